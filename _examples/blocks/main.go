@@ -1,9 +1,15 @@
 package main
 
-import "github.com/hedzr/is/term/color"
+import (
+	"fmt"
+	"time"
+
+	"github.com/hedzr/is/term/color"
+)
 
 func main() {
 	runBlock()
+	runGrid()
 }
 
 func runBlock() {
@@ -29,3 +35,31 @@ func runBlock() {
 	blk.Home()
 	print("xyz")
 }
+
+// runGrid draws many progress rows, only the changed cells
+// are sent to the terminal on each frame.
+func runGrid() {
+	const rows, width = 12, 30
+	fmt.Println()
+
+	g := color.NewGrid(width+12, rows)
+	defer g.Done()
+
+	for step := 0; step <= 100; step++ {
+		for row := range rows {
+			pct := min(100, step*(row+1)/4)
+			n := pct * width / 100
+			g.SetString(0, row, fmt.Sprintf("task %2d ", row+1), nil, nil, color.AttrNone)
+			for i := range width {
+				c := color.Cell{Ch: '░', Fg: color.FgDarkGray}
+				if i < n {
+					c = color.Cell{Ch: '█', Fg: color.NewColor256(byte(22+row%6*6), false)}
+				}
+				g.Set(8+i, row, c)
+			}
+			g.SetString(8+width, row, fmt.Sprintf(" %3d%%", pct), color.FgGreen, nil, color.AttrBold)
+		}
+		_ = g.Flush()
+		time.Sleep(30 * time.Millisecond)
+	}
+}
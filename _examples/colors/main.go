@@ -7,7 +7,10 @@ import (
 	"github.com/hedzr/is/term/color"
 )
 
-func main() { run1() }
+func main() {
+	run1()
+	run2()
+}
 func run1() {
 	// start a color text builder
 	var c = color.New()
@@ -76,3 +79,18 @@ func run1() {
 	// color.Left(1)
 	fmt.Printf("\nEND (pos = %+v)\n", pos)
 }
+
+func run2() {
+	// the true colors, parsed and mixed
+	red, _ := color.ParseRGB("#e74c3c")
+	yellow, _ := color.ParseRGB("hsl(50, 90%, 55%)")
+	blue, _ := color.ParseRGB("rebeccapurple")
+	fmt.Println(color.GradientText("gradient text across the stops, mixed in OKLab", red, yellow, blue))
+
+	// the swatches with a readable foreground
+	for _, bg := range color.Gradient(8, blue, red.Lighten(0.2)) {
+		fg := color.ReadableOn(bg)
+		fmt.Print(bg.AsBg().Color() + fg.Color() + fmt.Sprintf(" %s %4.1f ", bg.Hex(), fg.Contrast(bg)))
+	}
+	fmt.Println(color.ResetToNormalColor.Color())
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"github.com/hedzr/is/term/progress"
+)
+
+func main() {
+	// a standalone bar
+	bar := progress.New(50, progress.WithTitle("warming up"))
+	for range 50 {
+		time.Sleep(20 * time.Millisecond)
+		bar.Increment()
+	}
+	bar.Done()
+
+	// a group of concurrent bars, try piping the output to
+	// see the plain log lines:
+	//
+	//	go run ./_examples/progress | cat
+	g := progress.NewGroup(progress.WithLogInterval(time.Second))
+	var wg sync.WaitGroup
+	for _, name := range []string{"alpha.tar.gz", "beta.iso", "gamma.bin"} {
+		b := g.Add(int64(rand.IntN(48)+16)<<20, progress.WithTitle(name), progress.WithBytes())
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer b.Done()
+			for b.Current() < b.Total() {
+				time.Sleep(10 * time.Millisecond)
+				b.Add(min(int64(rand.IntN(512))<<10, b.Total()-b.Current()))
+			}
+		}()
+	}
+	spinner := g.Add(0, progress.WithTitle("indexing"))
+	go func() {
+		wg.Wait()
+		spinner.Done()
+	}()
+	for range 30 {
+		time.Sleep(50 * time.Millisecond)
+		spinner.Add(int64(rand.IntN(100)))
+	}
+	g.Println("all downloads are scheduled")
+	g.Wait()
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"context"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/hedzr/is"
+	"github.com/hedzr/is/term/color"
+)
+
+func main() {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// raw mode is disabled so that CTRL-C still raises SIGINT.
+	scr := color.NewScreen(color.WithScreenRawMode(false))
+
+	is.Signals().Catch().
+		WithPeripherals(scr).
+		WithOnSignalCaught(func(ctx context.Context, sig os.Signal, wg *sync.WaitGroup) {
+			cancel() // stop the loop below, the screen will be restored later.
+		}).
+		WaitFor(ctx, func(ctx context.Context, closer func()) {
+			defer closer()
+			ticker := time.NewTicker(200 * time.Millisecond)
+			defer ticker.Stop()
+			deadline := time.After(5 * time.Second)
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-deadline:
+					return
+				case tm := <-ticker.C:
+					cols, rows := scr.Size()
+					scr.Clear()
+					scr.MoveTo(max(1, cols/2-12), rows/2).
+						Printf("%s  %dx%d", tm.Format(time.TimeOnly), cols, rows)
+					scr.MoveTo(1, rows).Printf("Press CTRL-C to quit, or waiting for 5s...")
+				}
+			}
+		})
+}
@@ -0,0 +1,28 @@
+package main
+
+import (
+	"flag"
+	"os"
+
+	"github.com/hedzr/is/term/table"
+)
+
+var format = flag.String("format", "auto", "output format: auto, plain, markdown, csv")
+
+func main() {
+	flag.Parse()
+
+	f := map[string]table.Format{
+		"plain":    table.FormatPlain,
+		"markdown": table.FormatMarkdown,
+		"csv":      table.FormatCSV,
+	}[*format]
+
+	t := table.New(table.WithFormat(f), table.WithBorder(table.BorderRounded))
+	t.SetHeader("Package", "Size", "Status", "Note")
+	t.SetAlign(1, table.AlignRight).SetAlign(2, table.AlignCenter)
+	t.Append("is", "12 KiB", "<font color=green>ok</font>", "detectors and terminal helpers")
+	t.Append("term/color", "1.5 MiB", "<font color=green>ok</font>", "<b>CPT</b> markups, cursor and the cell grid")
+	t.Append("中文", "3 KiB", "<font color=red>failed</font>", "the wide characters are aligned well, and the long notes are wrapped to the terminal width")
+	_ = t.Render(os.Stdout)
+}
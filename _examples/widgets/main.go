@@ -0,0 +1,57 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	logz "log/slog"
+
+	"github.com/hedzr/is/term/prompt"
+)
+
+func main() {
+	ctx := context.Background()
+	if err := run(ctx); err != nil && !errors.Is(err, prompt.ErrInterrupted) {
+		logz.Error("app error", "err", err)
+	}
+}
+
+func run(ctx context.Context) (err error) {
+	fruits := []string{"apple", "banana", "cherry", "date", "elderberry", "fig", "grape", "kiwi", "lemon", "mango"}
+
+	var idx int
+	if idx, err = prompt.Select(ctx, "Pick a fruit", fruits, prompt.WithDefault(2)); err != nil {
+		return
+	}
+
+	var more []int
+	if more, err = prompt.MultiSelect(ctx, "Some more", fruits, prompt.WithPageSize(5)); err != nil {
+		return
+	}
+
+	var name string
+	if name, err = prompt.Input(ctx, "Your name",
+		prompt.WithPlaceholder("at least 2 characters"),
+		prompt.WithValidator(func(s string) error {
+			if len(strings.TrimSpace(s)) < 2 {
+				return errors.New("too short")
+			}
+			return nil
+		})); err != nil {
+		return
+	}
+
+	if _, err = prompt.Password(ctx, "Password"); err != nil {
+		return
+	}
+
+	var ok bool
+	if ok, err = prompt.Confirm(ctx, "Save the order?", true); err != nil {
+		return
+	}
+
+	fmt.Printf("%s ordered %q and %v, saved: %v\n", name, fruits[idx], more, ok)
+	return
+}
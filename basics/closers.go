@@ -1,6 +1,7 @@
 package basics
 
 import (
+	"slices"
 	"sync/atomic"
 )
 
@@ -8,6 +9,9 @@ import (
 // a basics.Peripheral object is a closable instance.
 func RegisterPeripheral(servers ...Peripheral) { closers.RegisterPeripheral(servers...) }
 
+// UnregisterPeripheral removes the peripherals from our global closers set.
+func UnregisterPeripheral(servers ...Peripheral) { closers.UnregisterPeripheral(servers...) }
+
 // RegisterClosable adds a peripheral/closable into our global closers set.
 // a basics.Peripheral object is a closable instance.
 func RegisterClosable(servers ...Closable) { closers.RegisterClosable(servers...) }
@@ -49,6 +53,19 @@ func (s *c) RegisterPeripheral(servers ...Peripheral) {
 	s.closers = append(s.closers, servers...)
 }
 
+// UnregisterPeripheral removes the peripherals. The set is
+// rebuilt rather than changed in place, so a peripheral can
+// unregister itself while Close is running.
+func (s *c) UnregisterPeripheral(servers ...Peripheral) {
+	kept := make([]Peripheral, 0, len(s.closers))
+	for _, c := range s.closers {
+		if !slices.Contains(servers, c) {
+			kept = append(kept, c)
+		}
+	}
+	s.closers = kept
+}
+
 func (s *c) RegisterClosable(servers ...Closable) {
 	for _, ci := range servers {
 		s.closers = append(s.closers, ci)
@@ -12,6 +12,8 @@ import (
 	"time"
 
 	"github.com/hedzr/is/basics"
+	"github.com/hedzr/is/term"
+	"github.com/hedzr/is/term/input"
 )
 
 type closerS struct{}
@@ -378,12 +380,42 @@ func PressEnterToContinue(in io.Reader, msg ...string) (input string) {
 }
 
 // PressAnyKeyToContinue lets program pause and wait for user's ANY key press in console/terminal
+//
+// If in is a terminal, the terminal of in will be switched into
+// raw mode, so a single key press is enough, and the returned
+// input is the key name (such as "a", "Enter", "Ctrl+c", see
+// [input.KeyEvent]). Or else a word is read from in.
 func PressAnyKeyToContinue(in io.Reader, msg ...string) (input string) {
 	if len(msg) > 0 && len(msg[0]) > 0 {
 		fmt.Print(msg[0])
 	} else {
 		fmt.Print("Press any key to continue...")
 	}
+	if f, ok := in.(*os.File); ok && term.IsTerminal(int(f.Fd())) {
+		if restore, err := term.MakeRawWrappedFd(int(f.Fd())); err == nil {
+			defer restore()
+			return readAnyKey(f)
+		}
+	}
 	_, _ = fmt.Fscanf(in, "%s", &input)
 	return
 }
+
+// readAnyKey reads in synchronously, a key press (even an escape
+// sequence) arrives in one read generally.
+func readAnyKey(in io.Reader) (key string) {
+	d := input.NewDecoder()
+	buf := make([]byte, 64)
+	for {
+		n, err := in.Read(buf)
+		events := append(d.Feed(buf[:n]), d.Flush()...)
+		for _, ev := range events {
+			if ke, ok := ev.(input.KeyEvent); ok {
+				return ke.String()
+			}
+		}
+		if err != nil {
+			return
+		}
+	}
+}
@@ -4,12 +4,13 @@ package chk
 import (
 	"io"
 	"os"
-	"regexp"
 	"runtime"
 	"strconv"
 	"strings"
 
 	"golang.org/x/term"
+
+	"github.com/hedzr/is/term/terminfo"
 )
 
 var (
@@ -19,9 +20,25 @@ var (
 	MinVal                    int64 // 0,16,88,256, or 1<<24
 )
 
+// TtyWriter is a writer which knows if it's a tty device, such
+// as color.Output.
+type TtyWriter interface {
+	io.Writer
+	IsTty() bool
+}
+
+// ColorfulWriter is a writer which knows if it's colorful, such
+// as color.Output.
+type ColorfulWriter interface {
+	io.Writer
+	IsColorful() bool
+}
+
 // IsTty detects a writer if it is abstracting from a tty (console, terminal) device.
 func IsTty(w io.Writer) bool {
 	switch z := w.(type) {
+	case TtyWriter:
+		return z.IsTty()
 	case *os.File:
 		return term.IsTerminal(int(z.Fd()))
 	default:
@@ -38,6 +55,10 @@ func IsColored(w io.Writer) bool { return IsColorful(w) }
 //
 // A colorful tty device can receive ANSI escaped sequences and draw its.
 func IsColorful(w io.Writer) (colorful bool) {
+	if cw, ok := w.(ColorfulWriter); ok {
+		return cw.IsColorful()
+	}
+
 	// && (runtime.GOOS != "windows")
 
 	// Check for Azure DevOps pipelines.
@@ -87,26 +108,14 @@ func IsColorful(w io.Writer) (colorful bool) {
 		_ = name
 
 		if name, val, present = anyInEnv("COLORTERM"); present {
-			if val == "truecolor" {
+			if val == "truecolor" || val == "24bit" {
 				MinVal = 1 << 24
 			} else {
 				MinVal = 16
 			}
 		}
 		if name, val, present = anyInEnv("TERM"); present {
-			switch val {
-			case "dumb":
-				MinVal = 16
-			default:
-				if strings.HasPrefix(val, "xterm-") {
-					MinVal = 256
-				} else if val == "xterm-kitty" {
-					MinVal = 1 << 24
-				} else if regexp.MustCompile(`^screen|^xterm|^vt100|^vt220|^rxvt|color|ansi|cygwin|linux`).Match([]byte(val)) {
-					MinVal = 16
-				}
-			}
-			MinVal = 1 << 24
+			MinVal = termColors(val, MinVal)
 		}
 	}
 
@@ -114,6 +123,36 @@ func IsColorful(w io.Writer) (colorful bool) {
 	return !DisableColors && (MinVal > 0 || force)
 }
 
+// termColors returns the color level of a terminal type.
+//
+// The terminfo database is queried firstly, the `colors`
+// number and the `Tc`/`RGB` extended capabilities decide the
+// level. A true-color hint from `COLORTERM` (the colorterm
+// level) upgrades a colorful entry since many terminfo entries
+// don't declare `Tc` even if the emulator supports it.
+//
+// The name patterns are used if no entry can be found, an
+// unknown terminal is taken as a 16 colors one.
+func termColors(name string, colorterm int64) int64 {
+	if ti, err := terminfo.Load(name); err == nil {
+		n := int64(ti.Colors())
+		if n > 0 && colorterm > n {
+			return colorterm
+		}
+		return n
+	}
+
+	switch {
+	case name == "dumb":
+		return 0
+	case name == "xterm-kitty", strings.HasSuffix(name, "-direct"), strings.HasSuffix(name, "-truecolor"):
+		return 1 << 24
+	case strings.HasSuffix(name, "-256color"):
+		return max(256, colorterm)
+	}
+	return max(16, colorterm)
+}
+
 func checkDisableColor() (disabled bool) {
 	if name, val, present := anyInEnv("NO_COLOR", "NOCOLOR"); present {
 		DisableColors = StringToBool(val)
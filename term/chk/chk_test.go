@@ -0,0 +1,20 @@
+package chk
+
+import "testing"
+
+func TestTermColors(t *testing.T) {
+	for _, c := range []struct {
+		name      string
+		colorterm int64
+		want      int64
+	}{
+		{"no-such-terminal", 0, 16},
+		{"no-such-terminal", 1 << 24, 1 << 24},
+		{"no-such-terminal-256color", 0, 256},
+		{"no-such-terminal-direct", 0, 1 << 24},
+	} {
+		if got := termColors(c.name, c.colorterm); got != c.want {
+			t.Errorf("termColors(%q, %d) = %d, want %d", c.name, c.colorterm, got, c.want)
+		}
+	}
+}
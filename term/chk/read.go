@@ -1,8 +1,10 @@
 package chk
 
 import (
+	"errors"
 	"io"
 	"runtime"
+	"time"
 )
 
 // ReadTill reads a line or a string ending with delim of input
@@ -51,3 +53,20 @@ func readNoEchoTill(reader io.Reader, delim byte) (ret []byte, delimMatched bool
 		}
 	}
 }
+
+// ErrTimeout is returned by [Query] if the reply is incomplete
+// after the timeout.
+var ErrTimeout = errors.New("terminal: read timeout")
+
+// Query writes request (such as "\x1b[6n") to w, and reads the
+// reply of the terminal from fd without local echo, till
+// complete(reply) returns true, or [ErrTimeout] after timeout.
+//
+// Unlike [ReadTill], the reply may contain any bytes, and a
+// terminal which doesn't reply won't block the caller forever.
+func Query(fd int, w io.Writer, request string, timeout time.Duration, complete func(reply []byte) bool) (reply []byte, err error) {
+	return queryReply(fd, func() error {
+		_, err := io.WriteString(w, request)
+		return err
+	}, timeout, complete)
+}
@@ -2,7 +2,12 @@
 
 package chk
 
-import "golang.org/x/sys/unix"
+import (
+	"io"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
 
 func readBytesTill(fd int, delim byte) ([]byte, bool, error) {
 	termios, err := unix.IoctlGetTermios(fd, ioctlReadTermios)
@@ -30,3 +35,55 @@ type disrectReader int
 func (r disrectReader) Read(buf []byte) (int, error) {
 	return unix.Read(int(r), buf)
 }
+
+func queryReply(fd int, request func() error, timeout time.Duration, complete func([]byte) bool) (ret []byte, err error) {
+	if termios, e := unix.IoctlGetTermios(fd, ioctlReadTermios); e == nil {
+		newState := *termios
+		newState.Lflag &^= (unix.ECHO | unix.ICANON)
+		newState.Cc[unix.VMIN], newState.Cc[unix.VTIME] = 1, 0
+		if err = unix.IoctlSetTermios(fd, ioctlWriteTermios, &newState); err != nil {
+			return
+		}
+		defer unix.IoctlSetTermios(fd, ioctlWriteTermios, termios)
+	}
+
+	if err = request(); err != nil {
+		return
+	}
+
+	var buf [256]byte
+	deadline := time.Now().Add(timeout)
+	for !complete(ret) {
+		left := time.Until(deadline)
+		if left <= 0 {
+			return ret, ErrTimeout
+		}
+		// select(2) rather than poll(2), which doesn't work with
+		// the terminal devices on darwin.
+		var rfds unix.FdSet
+		rfds.Zero()
+		rfds.Set(fd)
+		tv := unix.NsecToTimeval(left.Nanoseconds())
+		var n int
+		if n, err = unix.Select(fd+1, &rfds, nil, nil, &tv); err != nil {
+			if err == unix.EINTR {
+				continue
+			}
+			return
+		}
+		if n == 0 {
+			continue
+		}
+		if n, err = unix.Read(fd, buf[:]); err != nil {
+			if err == unix.EINTR || err == unix.EAGAIN {
+				continue
+			}
+			return
+		}
+		if n == 0 {
+			return ret, io.EOF
+		}
+		ret = append(ret, buf[:n]...)
+	}
+	return ret, nil
+}
@@ -5,9 +5,15 @@ package chk
 import (
 	"fmt"
 	"runtime"
+	"time"
 )
 
 func readBytesTill(fd int, delim byte) ([]byte, bool, error) {
 	_, _ = fd, delim
 	return nil, false, fmt.Errorf("terminal: ReadTill not implemented on %s/%s", runtime.GOOS, runtime.GOARCH)
 }
+
+func queryReply(fd int, request func() error, timeout time.Duration, complete func([]byte) bool) ([]byte, error) {
+	_, _, _, _ = fd, request, timeout, complete
+	return nil, fmt.Errorf("terminal: Query not implemented on %s/%s", runtime.GOOS, runtime.GOARCH)
+}
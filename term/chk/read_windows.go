@@ -1,7 +1,9 @@
 package chk
 
 import (
+	"io"
 	"os"
+	"time"
 
 	"golang.org/x/sys/windows"
 )
@@ -31,3 +33,46 @@ func readBytesTill(fd int, delim byte) ([]byte, bool, error) {
 	defer f.Close()
 	return readNoEchoTill(f, delim)
 }
+
+func queryReply(fd int, request func() error, timeout time.Duration, complete func([]byte) bool) (ret []byte, err error) {
+	h := windows.Handle(fd)
+	var st uint32
+	if err = windows.GetConsoleMode(h, &st); err == nil {
+		old := st
+		st &^= (windows.ENABLE_ECHO_INPUT | windows.ENABLE_LINE_INPUT)
+		st |= windows.ENABLE_VIRTUAL_TERMINAL_INPUT
+		if err = windows.SetConsoleMode(h, st); err != nil {
+			return
+		}
+		defer windows.SetConsoleMode(h, old)
+	}
+
+	if err = request(); err != nil {
+		return
+	}
+
+	var buf [256]byte
+	deadline := time.Now().Add(timeout)
+	for !complete(ret) {
+		left := time.Until(deadline)
+		if left <= 0 {
+			return ret, ErrTimeout
+		}
+		ev, e := windows.WaitForSingleObject(h, uint32(left/time.Millisecond)+1)
+		if e != nil {
+			return ret, e
+		}
+		if ev != windows.WAIT_OBJECT_0 {
+			continue
+		}
+		var n uint32
+		if err = windows.ReadFile(h, buf[:], &n, nil); err != nil {
+			return
+		}
+		if n == 0 {
+			return ret, io.EOF
+		}
+		ret = append(ret, buf[:n]...)
+	}
+	return ret, nil
+}
@@ -0,0 +1,228 @@
+package color
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hedzr/is/term/chk"
+)
+
+// QueryPalette asks the terminal for its default foreground and
+// background colors (OSC 10 and 11) and the 16 ANSI colors (OSC
+// 4), in raw mode, waiting for the replies at most timeout.
+//
+// A Device Attributes request is sent after the queries, so a
+// terminal which doesn't support them answers it only, and the
+// query ends without waiting for the timeout.
+//
+// The colors not reported are the ones of [DefaultPalette]. An
+// error is returned if stdin and stdout are not a terminal, or
+// the background color is not reported.
+func QueryPalette(timeout time.Duration) (*Palette, error) {
+	if !chk.IsTty(os.Stdin) || !chk.IsTty(os.Stdout) {
+		return nil, errors.New("color: query palette: not a terminal")
+	}
+	var sb strings.Builder
+	_, _ = sb.WriteString("\x1b]10;?\x1b\\\x1b]11;?\x1b\\")
+	for i := range 16 {
+		_, _ = fmt.Fprintf(&sb, "\x1b]4;%d;?\x1b\\", i)
+	}
+	_, _ = sb.WriteString("\x1b[c")
+	reply, err := chk.Query(int(os.Stdin.Fd()), os.Stdout, sb.String(), timeout, hasDAReply)
+	p := *DefaultPalette
+	if _, bg := parseColorReplies(string(reply), &p); !bg {
+		if err == nil {
+			err = errors.New("no background color reported")
+		}
+		return nil, fmt.Errorf("color: query palette: %w", err)
+	}
+	return &p, nil
+}
+
+// hasDAReply reports whether reply ends with the reply of Device
+// Attributes, "ESC [ ? ... c".
+func hasDAReply(reply []byte) bool {
+	if len(reply) == 0 || reply[len(reply)-1] != 'c' {
+		return false
+	}
+	i := bytes.LastIndex(reply, []byte("\x1b[?"))
+	if i < 0 {
+		return false
+	}
+	for _, c := range reply[i+3 : len(reply)-1] {
+		if (c < '0' || c > '9') && c != ';' {
+			return false
+		}
+	}
+	return true
+}
+
+// parseColorReplies parses the replies of OSC 10, 11 and 4 into
+// p, such as "ESC ] 11 ; rgb:1e1e/1e1e/1e1e ESC \".
+func parseColorReplies(s string, p *Palette) (fg, bg bool) {
+	for {
+		i := strings.Index(s, "\x1b]")
+		if i < 0 {
+			return
+		}
+		s = s[i+2:]
+		end, next := len(s), len(s)
+		if j := strings.IndexAny(s, "\a\x1b"); j >= 0 {
+			end, next = j, j+1
+			if s[j] == '\x1b' && j+1 < len(s) && s[j+1] == '\\' {
+				next++
+			}
+		}
+		body := s[:end]
+		s = s[next:]
+
+		fields := strings.Split(body, ";")
+		switch {
+		case len(fields) == 2 && (fields[0] == "10" || fields[0] == "11"):
+			rgb, ok := parseXColor(fields[1])
+			if !ok {
+				continue
+			}
+			if fields[0] == "10" {
+				p.Foreground, fg = rgb, true
+			} else {
+				p.Background, bg = rgb, true
+			}
+		case len(fields) == 3 && fields[0] == "4":
+			n, err := strconv.Atoi(fields[1])
+			if err != nil || n < 0 || n >= len(p.ANSI) {
+				continue
+			}
+			if rgb, ok := parseXColor(fields[2]); ok {
+				p.ANSI[n] = rgb
+			}
+		}
+	}
+}
+
+// parseXColor parses a color in the X11 formats which the
+// terminals reply: "rgb:r/g/b" and "rgba:r/g/b/a" where each
+// component has 1 to 4 hex digits, or "#rrggbb".
+func parseXColor(spec string) (rgb [3]byte, ok bool) {
+	var parts []string
+	switch {
+	case strings.HasPrefix(spec, "rgb:"):
+		parts = strings.Split(spec[4:], "/")
+	case strings.HasPrefix(spec, "rgba:"):
+		parts = strings.Split(spec[5:], "/")
+		if len(parts) != 4 {
+			return
+		}
+		parts = parts[:3]
+	case strings.HasPrefix(spec, "#"):
+		return parseHex(spec[1:])
+	default:
+		return
+	}
+	if len(parts) != 3 {
+		return
+	}
+	for i, part := range parts {
+		if len(part) < 1 || len(part) > 4 {
+			return rgb, false
+		}
+		v, err := strconv.ParseUint(part, 16, 16)
+		if err != nil {
+			return rgb, false
+		}
+		maxV := uint64(1)<<(4*len(part)) - 1
+		rgb[i] = byte((v*255 + maxV/2) / maxV)
+	}
+	return rgb, true
+}
+
+// parseCOLORFGBG returns the background color of $COLORFGBG,
+// "fg;bg" or "fg;default;bg" set by rxvt and some others, the
+// index of the background is mapped by [XtermPalette].
+func parseCOLORFGBG(s string) (rgb [3]byte, ok bool) {
+	fields := strings.Split(s, ";")
+	if len(fields) < 2 {
+		return
+	}
+	n, err := strconv.Atoi(fields[len(fields)-1])
+	if err != nil || n < 0 || n >= len(XtermPalette.ANSI) {
+		return
+	}
+	return XtermPalette.ANSI[n], true
+}
+
+var (
+	defaultBg  = DefaultPalette.Background
+	bgOnce     sync.Once
+	detectedBg [3]byte
+	bgMu       sync.RWMutex
+)
+
+// bgQueryTimeout is the timeout of the query by [Background].
+const bgQueryTimeout = 200 * time.Millisecond
+
+// SetDefaultBackground sets the background color which
+// [Background] falls back to, the background of [DefaultPalette]
+// (dark) by default. It should be called before the first call
+// of [Background].
+func SetDefaultBackground(rgb [3]byte) {
+	bgMu.Lock()
+	defer bgMu.Unlock()
+	defaultBg = rgb
+}
+
+// Background returns the background color of the terminal. It's
+// queried once (see [QueryPalette]), and falls back to
+// $COLORFGBG, then to the default one (see
+// [SetDefaultBackground]).
+func Background() [3]byte {
+	bgOnce.Do(func() {
+		if p, err := QueryPalette(bgQueryTimeout); err == nil {
+			detectedBg = p.Background
+			return
+		}
+		if rgb, ok := parseCOLORFGBG(os.Getenv("COLORFGBG")); ok {
+			detectedBg = rgb
+			return
+		}
+		bgMu.RLock()
+		defer bgMu.RUnlock()
+		detectedBg = defaultBg
+	})
+	return detectedBg
+}
+
+// IsDarkBackground reports whether the terminal has a dark
+// background, see [Background].
+func IsDarkBackground() bool { return IsDark(Background()) }
+
+// IsDark reports whether a background color is dark, that is,
+// the white text is more readable on it than the black one.
+func IsDark(rgb [3]byte) bool {
+	// the contrast ratios to white and black are equal at the
+	// luminance sqrt(1.05*0.05)-0.05
+	return relativeLuminance(rgb) < math.Sqrt(1.05*0.05)-0.05
+}
+
+// relativeLuminance returns the relative luminance of WCAG 2.
+func relativeLuminance(rgb [3]byte) float64 {
+	return 0.2126*linearRGB(rgb[0]) + 0.7152*linearRGB(rgb[1]) + 0.0722*linearRGB(rgb[2])
+}
+
+// AutoTheme returns [ThemeLight] for a light background of the
+// terminal, or [ThemeDark], see [IsDarkBackground].
+//
+//	color.SetTheme(color.AutoTheme())
+func AutoTheme() *Theme {
+	if IsDarkBackground() {
+		return ThemeDark
+	}
+	return ThemeLight
+}
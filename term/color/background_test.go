@@ -0,0 +1,108 @@
+package color
+
+import "testing"
+
+func TestParseXColor(t *testing.T) {
+	for _, c := range []struct {
+		spec string
+		want [3]byte
+		ok   bool
+	}{
+		{"rgb:1e1e/1e1e/1e1e", [3]byte{0x1e, 0x1e, 0x1e}, true},
+		{"rgb:ffff/8080/0000", [3]byte{0xff, 0x80, 0x00}, true},
+		{"rgb:f/8/0", [3]byte{0xff, 0x88, 0x00}, true},
+		{"rgb:ff/80/00", [3]byte{0xff, 0x80, 0x00}, true},
+		{"rgba:ffff/ffff/ffff/ffff", [3]byte{0xff, 0xff, 0xff}, true},
+		{"#336699", [3]byte{0x33, 0x66, 0x99}, true},
+		{"rgb:ff/80", [3]byte{}, false},
+		{"rgb:fffff/0/0", [3]byte{}, false},
+		{"rgb:zz/00/00", [3]byte{}, false},
+		{"?", [3]byte{}, false},
+	} {
+		got, ok := parseXColor(c.spec)
+		if ok != c.ok || (ok && got != c.want) {
+			t.Errorf("parseXColor(%q) = %v, %v, want %v, %v", c.spec, got, ok, c.want, c.ok)
+		}
+	}
+}
+
+func TestParseColorReplies(t *testing.T) {
+	reply := "\x1b]10;rgb:cccc/cccc/cccc\x1b\\" +
+		"\x1b]11;rgb:ffff/ffff/ffff\a" +
+		"\x1b]4;1;rgb:cdcd/0000/0000\x1b\\" +
+		"\x1b]4;99;rgb:0000/0000/0000\x1b\\" +
+		"\x1b[?62;22c"
+	p := *DefaultPalette
+	fg, bg := parseColorReplies(reply, &p)
+	if !fg || !bg {
+		t.Fatalf("fg, bg = %v, %v", fg, bg)
+	}
+	if p.Foreground != [3]byte{0xcc, 0xcc, 0xcc} || p.Background != [3]byte{0xff, 0xff, 0xff} {
+		t.Errorf("fg, bg = %v, %v", p.Foreground, p.Background)
+	}
+	if p.ANSI[1] != [3]byte{0xcd, 0, 0} || p.ANSI[2] != DefaultPalette.ANSI[2] {
+		t.Errorf("ansi = %v", p.ANSI)
+	}
+
+	p = *DefaultPalette
+	if _, bg = parseColorReplies("\x1b[?1;2c", &p); bg {
+		t.Error("no reply: bg reported")
+	}
+}
+
+func TestHasDAReply(t *testing.T) {
+	for _, c := range []struct {
+		reply string
+		want  bool
+	}{
+		{"", false},
+		{"\x1b[?62;22c", true},
+		{"\x1b]11;rgb:cccc/cccc/cccc\x1b\\\x1b[?1;2c", true},
+		{"\x1b]11;rgb:cccc/cccc/cccc\x1b\\", false},
+		{"\x1b]11;rgb:cccc/cccc/cc", false},
+	} {
+		if got := hasDAReply([]byte(c.reply)); got != c.want {
+			t.Errorf("hasDAReply(%q) = %v, want %v", c.reply, got, c.want)
+		}
+	}
+}
+
+func TestParseCOLORFGBG(t *testing.T) {
+	for _, c := range []struct {
+		env  string
+		dark bool
+		ok   bool
+	}{
+		{"15;0", true, true},
+		{"0;15", false, true},
+		{"0;default;7", false, true},
+		{"15;8", false, true},
+		{"7;4", true, true},
+		{"", false, false},
+		{"15;default", false, false},
+		{"15;16", false, false},
+	} {
+		rgb, ok := parseCOLORFGBG(c.env)
+		if ok != c.ok || (ok && IsDark(rgb) != c.dark) {
+			t.Errorf("parseCOLORFGBG(%q) = %v, %v, dark %v, want dark %v, %v", c.env, rgb, ok, IsDark(rgb), c.dark, c.ok)
+		}
+	}
+}
+
+func TestIsDark(t *testing.T) {
+	for _, c := range []struct {
+		rgb  [3]byte
+		want bool
+	}{
+		{DefaultPalette.Background, true},
+		{LightPalette.Background, false},
+		{[3]byte{0x00, 0x2b, 0x36}, true},  // solarized dark
+		{[3]byte{0xfd, 0xf6, 0xe3}, false}, // solarized light
+		{[3]byte{0x40, 0x40, 0x40}, true},
+		{[3]byte{0xc0, 0xc0, 0xc0}, false},
+	} {
+		if got := IsDark(c.rgb); got != c.want {
+			t.Errorf("IsDark(%v) = %v, want %v", c.rgb, got, c.want)
+		}
+	}
+}
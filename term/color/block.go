@@ -21,7 +21,7 @@ type RowsBlock struct {
 func NewRowsBlock() RowsBlock {
 	return RowsBlock{
 		height:     0,
-		writer:     os.Stdout,
+		writer:     Out,
 		cursor:     New(),
 		cursorPosY: 0,
 	}
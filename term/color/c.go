@@ -4,7 +4,6 @@ import (
 	"bytes"
 	"fmt"
 	"io"
-	"os"
 	"sync"
 	"sync/atomic"
 
@@ -80,11 +79,13 @@ func (s Result) String() string {
 
 func (s *Cursor) reinit(first bool) {
 	s.useColor = !states.Env().IsNoColorMode()
-	s.w = os.Stdout
-	s.sw = os.Stdout
+	s.w = Out
+	if sw, ok := Out.(io.StringWriter); ok {
+		s.sw = sw
+	}
 	if first {
 		states.Env().SetOnNoColorChanged(s.updateCandidated)
-		s.colorful = chk.IsColorful(os.Stdout)
+		s.colorful = chk.IsColorful(Out)
 	}
 }
 
@@ -102,6 +103,8 @@ func (s *Cursor) Reset() {
 	// return
 }
 
+// Build returns the text built, the colors are downsampled for
+// the color level of the output device, see [ColorLevel].
 func (s *Cursor) Build() (r string) {
 	for i := len(s.closers) - 1; i >= 0; i-- {
 		fn := s.closers[i]
@@ -115,7 +118,7 @@ func (s *Cursor) Build() (r string) {
 		s.echoResetColor()
 	}
 
-	r = s.sb.String()
+	r = DownsampleString(s.sb.String(), ColorLevel(s.w))
 	s.sb.Reset()
 	poolBuilder.Put(s)
 	return
@@ -139,7 +142,7 @@ func (s *Cursor) String() string {
 	if atomic.CompareAndSwapInt32(&s.needReset, 1, 0) {
 		s.echoResetColor()
 	}
-	return s.sb.String()
+	return DownsampleString(s.sb.String(), ColorLevel(s.w))
 }
 
 func (s *Cursor) ResetColor() *Cursor {
@@ -317,6 +320,11 @@ const formfeed = '\x0c'       // CTRL-L FF, Move a printer to top of next page.
 const carriagereturn = '\x0d' // CTRL-M CR, Moves the cursor to column zero.
 const escape = '\x1b'         // CTRL-[ ESC, Starts all the escape sequences
 const csi = "\x1b["
+
+const aecHideCursor = "\x1b[?25l"    // DECTCEM off
+const aecShowCursor = "\x1b[?25h"    // DECTCEM on
+const aecAltScreenOn = "\x1b[?1049h" // DECSET 1049, save cursor and switch to the alternate screen buffer
+const aecAltScreenOff = "\x1b[?1049l"
 const ESCAPE = '\x1b'
 
 const (
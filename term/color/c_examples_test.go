@@ -8,8 +8,18 @@ import (
 
 	"github.com/hedzr/is/states"
 	"github.com/hedzr/is/term/color"
+	"github.com/hedzr/is/term/terminfo"
+	"github.com/hedzr/is/term/vt"
 )
 
+// withoutTerminfo clears $TERM in the test, so the cursor
+// sequences are the ECMA-48 ones whatever the environment is.
+func withoutTerminfo(t *testing.T) {
+	t.Setenv("TERM", "")
+	terminfo.Reset()
+	t.Cleanup(terminfo.Reset)
+}
+
 func ExampleNew() {
 	// start a color text builder
 	var c = color.New()
@@ -188,13 +198,13 @@ func ExampleCursor_Translate() {
 		<font color="green">green text</font>
 		`).String())
 	// Output:
-	// [51;1mcode[0m[39m | [51;1mCTRL[0m[39m
-	//		[1mbold / strong / em[0m[39m
-	//		[3mitalic / cite[0m[39m
-	//		[4munderline[0m[39m
-	//		[7minverse mark[0m[39m
-	//		[9mstrike / del [0m[39m
-	//		[32mgreen text[0m[39m
+	// [1;51mcode[0m | [1;51mCTRL[0m
+	//		[1mbold / strong / em[0m
+	//		[3mitalic / cite[0m
+	//		[4munderline[0m
+	//		[7minverse mark[0m
+	//		[9mstrike / del [0m
+	//		[32mgreen text[0m
 }
 
 func ExampleCursor_StripLeftTabsColorful() {
@@ -211,13 +221,13 @@ func ExampleCursor_StripLeftTabsColorful() {
 		<font color="green">green text</font>
 		`).String())
 	// Output:
-	// [51;1mcode[0m[0m | [51;1mCTRL[0m[0m
-	// [1mbold / strong / em[0m[0m
-	// [3mitalic / cite[0m[0m
-	// [4munderline[0m[0m
-	// [7minverse mark[0m[0m
-	// [9mstrike / del [0m[0m
-	// [32mgreen text[0m[0m
+	// [1;51mcode[0m | [1;51mCTRL[0m
+	// [1mbold / strong / em[0m
+	// [3mitalic / cite[0m
+	// [4munderline[0m
+	// [7minverse mark[0m
+	// [9mstrike / del [0m
+	// [32mgreen text[0m
 }
 
 func ExampleCSICodes() {
@@ -279,11 +289,10 @@ func ExampleNewRowsBlock() {
 }
 
 func TestExampleNewRowsBlock(t *testing.T) {
-	if !testing.Verbose() {
-		return
-	}
-
+	withoutTerminfo(t)
+	term := vt.New(40, 5)
 	rb := color.NewRowsBlock()
+	rb.WithWriter(term)
 
 	// the following outputs will be displayed in first
 	// line of the RowsBlock.
@@ -292,6 +301,18 @@ func TestExampleNewRowsBlock(t *testing.T) {
 		str := fmt.Sprintf("%sHello, World!\n", spc)
 		rb.Update(str)
 	}
+
+	term.ExpectRow(t, 0, "+++++++++Hello, World!")
+	term.ExpectRow(t, 1, "")
+	term.ExpectCursor(t, 0, 1)
+
+	// a taller block is redrawn in place
+	rb.Update("a\nb\nc\n")
+	rb.Update("d\ne\n")
+	if got, want := term.String(), "d\ne"; got != want {
+		t.Errorf("got %q, want %q\n%s", got, want, term.Snapshot())
+	}
+	term.ExpectCursor(t, 0, 2)
 }
 
 func ExampleNewSGR() {
@@ -355,23 +376,43 @@ func ExampleNewSGR() {
 }
 
 func TestExampleNewSGR(t *testing.T) {
-	if !testing.Verbose() {
-		return
+	term := vt.New(60, 30)
+	for i, sgrs := range []struct {
+		pre, post color.CSIsgr
+		desc      string
+		attrs     color.Attr
+	}{
+		{color.SGRbold, color.SGRresetBoldAndDim, "bold", color.AttrBold},
+		{color.SGRdim, color.SGRresetBoldAndDim, "dim", color.AttrDim},
+		{color.SGRitalic, color.SGRresetItalic, "italic", color.AttrItalic},
+		{color.SGRunderline, color.SGRresetUnderline, "underline", color.AttrUnderline},
+		{color.SGRslowblink, color.SGRresetSlowBlink, "blink", color.AttrBlink},
+		{color.SGRinverse, color.SGRresetInverse, "inverse", color.AttrReverse},
+		{color.SGRhide, color.SGRresetHide, "hide", color.AttrHidden},
+		{color.SGRstrike, color.SGRresetStrike, "strike", color.AttrStrike},
+	} {
+		_, _ = fmt.Fprintf(term, "%5d. %s%s%s %s\n", i, sgrs.pre, "Hello, World!", sgrs.post, sgrs.desc)
+		term.ExpectRow(t, i, fmt.Sprintf("%5d. Hello, World! %s", i, sgrs.desc))
+		term.ExpectStyle(t, i, "Hello, World!", color.Cell{Attrs: sgrs.attrs})
+		term.ExpectStyle(t, i, " "+sgrs.desc, color.Cell{})
 	}
-	ExampleNewSGR()
 
 	// SGRsetFg
-	fmt.Printf("\x1b[%d;5;9m[ 9 TEST string HERE]%s\n",
+	_, _ = fmt.Fprintf(term, "\x1b[%d;5;9m[ 9 TEST string HERE]%s\n",
 		color.SGRsetFg,
 		color.SGRdefaultFg,
 	)
 
-	fmt.Printf("\x1b[%d;5;21m[21 TEST string HERE]%s\n",
+	_, _ = fmt.Fprintf(term, "\x1b[%d;5;21m[21 TEST string HERE]%s\n",
 		color.SGRsetFg,
 		color.SGRdefaultFg,
 	)
 
-	fmt.Println(color.SGRreset)
+	term.ExpectStyle(t, 8, "[ 9 TEST string HERE]", color.Cell{Fg: color.NewColor256(9, false)})
+	term.ExpectStyle(t, 9, "[21 TEST string HERE]", color.Cell{Fg: color.NewColor256(21, false)})
+	if testing.Verbose() {
+		t.Logf("\n%s", term.Snapshot())
+	}
 
 	// [38;5;9m[ 9 TEST string HERE][39m
 	// [38;5;21m[21 TEST string HERE][39m
@@ -0,0 +1,146 @@
+package color
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/hedzr/is/term/terminfo"
+)
+
+// currentTerminfo returns the capabilities of the running
+// terminal (`$TERM`), it can be replaced in testing.
+var currentTerminfo = terminfo.Current
+
+// csiSeq translates a CSI code to the capability string of the
+// current terminal.
+//
+// The ECMA-48 sequence ecma is returned as is if the terminal is
+// unknown (no `$TERM`, or no terminfo entry). For a known terminal,
+// the capability string is used, and an empty string will be
+// returned if the terminal hasn't such a capability, for example,
+// the cursor movements on a `dumb` terminal.
+func csiSeq(ch uint8, n, m int, ecma string) string {
+	ti := currentTerminfo()
+	if ti == nil {
+		return ecma
+	}
+
+	// ansi is the fallback for the codes without a terminfo
+	// capability, such as "erase whole line", "DSR", ...
+	ansi := func() string {
+		if isANSI(ti) {
+			return ecma
+		}
+		return ""
+	}
+
+	one := max(n, 1) // ECMA-48: 0 means 1 for the count and position params
+	switch ch {
+	case 'A':
+		return capRepeat(ti, "cuu", "cuu1", one)
+	case 'B':
+		return capRepeat(ti, "cud", "cud1", one)
+	case 'C':
+		return capRepeat(ti, "cuf", "cuf1", one)
+	case 'D':
+		return capRepeat(ti, "cub", "cub1", one)
+	case 'E':
+		return ti.Exec("cr") + capRepeat(ti, "cud", "cud1", one)
+	case 'F':
+		return ti.Exec("cr") + capRepeat(ti, "cuu", "cuu1", one)
+	case 'G':
+		if s, ok := ti.Parm("hpa", one-1); ok {
+			return s
+		}
+		if one == 1 {
+			return ti.Exec("cr")
+		}
+		return ti.Exec("cr") + capRepeat(ti, "cuf", "cuf1", one-1)
+	case 'H', 'f':
+		return ti.Exec("cup", one-1, max(m, 1)-1)
+	case 'J':
+		if n == 0 {
+			return ti.Exec("ed")
+		}
+		return ansi()
+	case 'K':
+		switch n {
+		case 0:
+			return ti.Exec("el")
+		case 1:
+			return ti.Exec("el1")
+		case 2:
+			if isANSI(ti) || !ti.Has("el1") {
+				return ansi()
+			}
+			return ti.Exec("el1") + ti.Exec("el")
+		}
+		return ansi()
+	case 'S':
+		return capRepeat(ti, "indn", "ind", one)
+	case 'T':
+		return capRepeat(ti, "rin", "ri", one)
+	case 's':
+		return ti.Exec("sc")
+	case 'u':
+		return ti.Exec("rc")
+	case 'm':
+		// SGR is generic, but a terminal without any
+		// attributes (dumb, ...) gets nothing.
+		if ti.Has("sgr0") || ti.Has("setaf") {
+			return ecma
+		}
+		return ""
+	}
+	return ansi()
+}
+
+// capSeq returns the capability string of the current terminal,
+// or ecma if the terminal is unknown.
+func capSeq(capName, ecma string) string {
+	ti := currentTerminfo()
+	if ti == nil {
+		return ecma
+	}
+	return ti.Exec(capName)
+}
+
+// capRepeat expands the parameterized capability capName, or
+// repeats the single-step capability single n times if capName is
+// absent.
+func capRepeat(ti *terminfo.Terminfo, capName, single string, n int) string {
+	if n == 1 {
+		if s, ok := ti.Parm(single); ok {
+			return s
+		}
+	}
+	if s, ok := ti.Parm(capName, n); ok {
+		return s
+	}
+	if s, ok := ti.Parm(single); ok {
+		return strings.Repeat(s, n)
+	}
+	return ""
+}
+
+// isANSI reports whether a terminal speaks ECMA-48 (ANSI X3.64),
+// by checking its cursor addressing capability.
+func isANSI(ti *terminfo.Terminfo) bool {
+	cup, _ := ti.Str("cup")
+	return strings.HasPrefix(cup, csi)
+}
+
+// ecmaSeq formats a CSI code in ECMA-48 form, such as "\x1b[3;4H".
+func ecmaSeq(ch uint8, n, m int) string {
+	var sb strings.Builder
+	_, _ = sb.WriteString(csi)
+	if n > 0 {
+		_, _ = sb.WriteString(strconv.Itoa(n))
+	}
+	if m > 0 {
+		_ = sb.WriteByte(';')
+		_, _ = sb.WriteString(strconv.Itoa(m))
+	}
+	_ = sb.WriteByte(ch)
+	return sb.String()
+}
@@ -0,0 +1,50 @@
+package color
+
+import (
+	"testing"
+
+	"github.com/hedzr/is/term/terminfo"
+)
+
+func withTerminfo(t *testing.T, name string) {
+	t.Helper()
+	saved := currentTerminfo
+	t.Cleanup(func() { currentTerminfo = saved })
+
+	var ti *terminfo.Terminfo
+	if name != "" {
+		ti = terminfo.Builtin(name)
+		if ti == nil {
+			t.Fatalf("builtin terminfo %q not found", name)
+		}
+	}
+	currentTerminfo = func() *terminfo.Terminfo { return ti }
+}
+
+func TestCursorCapabilities(t *testing.T) {
+	tests := []struct {
+		term  string
+		build func(c *Cursor) *Cursor
+		want  string
+	}{
+		{"", func(c *Cursor) *Cursor { return c.Up(3).Printf("") }, "\x1b[3A"},
+		{"", func(c *Cursor) *Cursor { return c.MoveTo(4, 2).Printf("") }, "\x1b[2;4H"},
+		{"xterm-256color", func(c *Cursor) *Cursor { return c.Up(3).Printf("") }, "\x1b[3A"},
+		{"xterm-256color", func(c *Cursor) *Cursor { return c.Up(1).Printf("") }, "\x1b[A"},
+		{"xterm-256color", func(c *Cursor) *Cursor { return c.MoveTo(4, 2).Printf("") }, "\x1b[2;4H"},
+		{"xterm-256color", func(c *Cursor) *Cursor { return c.EraseInLine(2).Printf("") }, "\x1b[2K"},
+		{"vt100", func(c *Cursor) *Cursor { return c.SavePos().Printf("") }, "\x1b7"},
+		{"vt100", func(c *Cursor) *Cursor { return c.HorzCol(1).Printf("") }, "\r"},
+		{"dumb", func(c *Cursor) *Cursor { return c.Up(3).Printf("x") }, "x"},
+		{"dumb", func(c *Cursor) *Cursor { return c.MoveTo(4, 2).Printf("x") }, "x"},
+		{"dumb", func(c *Cursor) *Cursor { return c.EraseInLine(2).Printf("x") }, "x"},
+		{"dumb", func(c *Cursor) *Cursor { return c.SGR(1).Printf("x") }, "x"},
+	}
+	for i, tc := range tests {
+		withTerminfo(t, tc.term)
+		c := New()
+		if got := tc.build(c).Build(); got != tc.want {
+			t.Errorf("%d. [%s] got %q, want %q", i, tc.term, got, tc.want)
+		}
+	}
+}
@@ -8,6 +8,17 @@
 //
 // [RowsBlock] is another cursor controller, which can treat the current line and following lines as a block and updating these lines repeatedly. This feature will help the progressbar writers or the continuous lines updater.
 //
+// [Output] wraps a writer with its own profile (tty, color level, width, no-color mode), the
+// colors and the escape sequences written through it are adapted to that. [Stdout] and [Stderr]
+// are the outputs of os.Stdout and os.Stderr.
+//
+// On Windows, [SetupConsole] enables the virtual terminal processing of the consoles, or makes
+// [Stdout] and [Stderr] translate the escape sequences into the console API calls on the legacy
+// console, see [ConsoleTranslator].
+//
+// [HorizontalGradient], [VerticalGradient] and [Rainbow] paint the banners, [AnimateRainbow],
+// [AnimatePulse] and [AnimateTypewriter] animate them in a [RowsBlock].
+//
 // [Translator] is a text and tiny HTML tags translator to convert these markup text into colorful console text sequences.
 // [GetCPT] can return a smart translator which translate colorful text or strip the ansi escaped sequence from result text if `states.Env().IsNoColorMode()` is true.
 //
@@ -18,9 +29,11 @@
 //   - by [NewColor16], or use [Color16] constants directly like [FgBlack], [BgGreen], ...
 //   - by [NewColor256] to make a 8-bit 256-colors object
 //   - by [NewColor16m] to make a true-color object
+//   - by [ParseRGB], [NewColorHSL], [NewColorHSV] or [NewColorOKLab] to make a true-color object, which can be mixed ([Color16m.Mix], [Gradient]) and checked for the contrast ([Color16m.Contrast], [ReadableOn])
 //   - by [NewControlCode] or [ControlCode] constants
 //   - by [NewFeCode] or [FeCode] constants
 //   - by [NewSGR] or use [CSIsgr] constants directly like [SGRdim], [SGRstrike], ...
+//   - by [CSIsgr.Sub] to make a code with the sub-parameters, or use [UnderlineStyle] constants like [UnderlineCurly], and [NewUnderlineColor]
 //   - by [NewStyle] to make a compounded object
 //   - ...
 //
@@ -256,6 +269,12 @@ func (c Color16) TryAppend(color colorCSI) (nc csiGroups, added bool) {
 // 	return newclr.Add(colors...)
 // }
 
+// Color256 is a color of the 256-color palette.
+//
+// Color and Wrap return the "38;5;n" sequences in full depth,
+// whatever the terminal supports. The colors are downsampled
+// by the writers only: [Cursor], [Grid], [Output] and
+// [DepthWriter], or by [DownsampleString] explicitly.
 type Color256 struct {
 	// r, g, b, a byte
 	clr [4]byte
@@ -265,17 +284,17 @@ type Color256 struct {
 func (c Color256) leading() string      { return csi }
 func (c Color256) ending() byte         { return byte('m') }
 func (c Color256) epilogue(out CWriter) { _ = out.WriteByte(c.ending()) }
-func (c Color256) prologue(out CWriter) {
-	_, _ = out.WriteString(c.leading())
+func (c Color256) prologue(out CWriter) { _, _ = out.WriteString(c.leading()) }
+
+// core writes the parameters, "38;5;n" or "48;5;n", so that a
+// Color256 can be grouped with the others in one sequence.
+func (c Color256) core(out CWriter) {
 	if c.bg {
 		_, _ = out.WriteString("48;5;")
 	} else {
 		_, _ = out.WriteString("38;5;")
 	}
-}
-
-func (c Color256) core(out CWriter) {
-	_, _ = out.WriteInt(int(c.clr[0])) // r
+	_, _ = out.WriteInt(int(c.clr[0])) // n
 }
 
 // func (c Color256) Color() string {
@@ -327,6 +346,10 @@ func (c Color256) AddAndNew(colors ...Color) (newclr *Style) {
 	return newclr.Add(colors...)
 }
 
+// Color16m is a true color.
+//
+// Color and Wrap return the "38;2;r;g;b" sequences in full
+// depth, see [Color256] for the downsampling.
 type Color16m struct {
 	// r, g, b, a byte
 	clr [4]byte
@@ -336,16 +359,15 @@ type Color16m struct {
 func (c Color16m) leading() string      { return csi }
 func (c Color16m) ending() byte         { return byte('m') }
 func (c Color16m) epilogue(out CWriter) { _ = out.WriteByte(c.ending()) }
-func (c Color16m) prologue(out CWriter) {
-	_, _ = out.WriteString(c.leading())
+func (c Color16m) prologue(out CWriter) { _, _ = out.WriteString(c.leading()) }
+
+// core writes the parameters, "38;2;r;g;b" or "48;2;r;g;b".
+func (c Color16m) core(out CWriter) {
 	if c.bg {
 		_, _ = out.WriteString("48;2;")
 	} else {
 		_, _ = out.WriteString("38;2;")
 	}
-}
-
-func (c Color16m) core(out CWriter) {
 	_, _ = out.WriteInt(int(c.clr[0])) // r
 	_, _ = out.WriteRune(';')
 	_, _ = out.WriteInt(int(c.clr[1])) // g
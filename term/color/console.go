@@ -0,0 +1,325 @@
+package color
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ErrNoVirtualTerminal is returned by [EnableVirtualTerminal] if
+// the console can't process the escape sequences, such as the
+// legacy console before Windows 10.
+var ErrNoVirtualTerminal = errors.New("color: the console doesn't support the virtual terminal sequences")
+
+// ConsoleAPI is the part of the Windows console API used by
+// [ConsoleTranslator]. The console handles implement it on
+// Windows, and a mock can be used to test the translation on
+// any platform.
+//
+// The coordinates are 0-based in the screen buffer.
+type ConsoleAPI interface {
+	// Info returns the screen buffer info (GetConsoleScreenBufferInfo).
+	Info() (ConsoleInfo, error)
+	// SetAttribute sets the attribute of the text written next
+	// (SetConsoleTextAttribute).
+	SetAttribute(attr uint16) error
+	// SetCursor moves the cursor (SetConsoleCursorPosition).
+	SetCursor(x, y int) error
+	// Fill fills n cells from x, y with ch in attr, the cells wrap
+	// to the next rows (FillConsoleOutputCharacter and
+	// FillConsoleOutputAttribute).
+	Fill(x, y, n int, ch rune, attr uint16) error
+	// ShowCursor shows or hides the cursor (SetConsoleCursorInfo).
+	ShowCursor(visible bool) error
+	// Write writes text at the cursor (WriteConsole).
+	Write(text string) error
+}
+
+// ConsoleInfo is the screen buffer info of a console.
+type ConsoleInfo struct {
+	Width, Height int    // the size of the screen buffer
+	X, Y          int    // the cursor position
+	Attr          uint16 // the current attribute
+	Top, Bottom   int    // the rows of the visible window
+}
+
+// the bits of a console attribute.
+const (
+	consoleFgIntensity uint16 = 0x08
+	consoleFgMask      uint16 = 0x0f
+	consoleBgMask      uint16 = 0xf0
+	consoleUnderscore  uint16 = 0x8000 // COMMON_LVB_UNDERSCORE
+)
+
+// consoleColor returns the console color bits (BGR, and the
+// intensity) of an ANSI color index, 0-15.
+func consoleColor(i int) uint16 {
+	return uint16(i&1)<<2 | uint16(i&2) | uint16(i&4)>>2 | uint16(i&8)
+}
+
+// ConsoleTranslator is a writer for the legacy consoles which
+// can't process the escape sequences. It translates the SGR
+// sequences into the text attributes, and the cursor movements
+// and erasing into the console API calls. The other sequences
+// are dropped.
+//
+// The colors are downsampled to the 16 colors, see
+// [DownsampleString]. An escape sequence can be split into
+// several writes.
+type ConsoleTranslator struct {
+	api ConsoleAPI
+	def uint16 // the attribute at the beginning
+
+	mu               sync.Mutex
+	fg, bg           int // the ANSI color index, -1 is the default
+	bold, rev, under bool
+	savedX, savedY   int
+	saved            bool
+	pending          []byte
+	lastErr          error
+}
+
+// NewConsoleTranslator returns a ConsoleTranslator writing to the
+// console api.
+func NewConsoleTranslator(api ConsoleAPI) *ConsoleTranslator {
+	t := &ConsoleTranslator{api: api, def: 0x07, fg: -1, bg: -1}
+	if info, err := api.Info(); err == nil {
+		t.def = info.Attr
+	}
+	return t
+}
+
+// Fd returns the handle of the console if the api has one, so
+// the translator is a [Writer].
+func (t *ConsoleTranslator) Fd() uintptr {
+	if f, ok := t.api.(interface{ Fd() uintptr }); ok {
+		return f.Fd()
+	}
+	return ^uintptr(0)
+}
+
+// IsTty reports true, the translator writes to a console.
+func (t *ConsoleTranslator) IsTty() bool { return true }
+
+// Write translates p into the console api calls. It stops at
+// the first error of the calls, and returns the length of p
+// translated before it.
+func (t *ConsoleTranslator) Write(p []byte) (n int, err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	held := len(t.pending)
+	s := string(append(t.pending, p...))
+	t.pending = t.pending[:0]
+	if i := pendingAt(s); i < len(s) && len(s)-i <= maxLineLen {
+		t.pending = append(t.pending, s[i:]...)
+		s = s[:i]
+	}
+
+	t.lastErr = nil
+	done := scanEscapes(s, func(part string, esc bool) bool {
+		if esc {
+			t.escape(part)
+		} else {
+			t.check(t.api.Write(part))
+		}
+		return t.lastErr == nil
+	})
+	if t.lastErr != nil {
+		t.pending = t.pending[:0] // the rest of p is left to the caller
+		return max(done-held, 0), t.lastErr
+	}
+	return len(p), nil
+}
+
+// check keeps the first error.
+func (t *ConsoleTranslator) check(err error) {
+	if err != nil && t.lastErr == nil {
+		t.lastErr = err
+	}
+}
+
+func (t *ConsoleTranslator) escape(seq string) {
+	switch {
+	case seq == "\x1b7":
+		t.savePos()
+	case seq == "\x1b8":
+		t.restorePos()
+	case len(seq) >= 3 && seq[1] == '[':
+		t.csi(seq[2:len(seq)-1], seq[len(seq)-1])
+	}
+}
+
+// attr returns the console attribute of the current style.
+func (t *ConsoleTranslator) attr() uint16 {
+	a := t.def
+	if t.fg >= 0 {
+		a = a&^consoleFgMask | consoleColor(t.fg)
+	}
+	if t.bg >= 0 {
+		a = a&^consoleBgMask | consoleColor(t.bg)<<4
+	}
+	if t.bold {
+		a |= consoleFgIntensity
+	}
+	if t.rev {
+		a = a&^(consoleFgMask|consoleBgMask) | (a&consoleFgMask)<<4 | (a&consoleBgMask)>>4
+	}
+	if t.under {
+		a |= consoleUnderscore
+	}
+	return a
+}
+
+func (t *ConsoleTranslator) sgr(params string) {
+	// the extended colors become the 16 colors
+	ds := downsampleSGR(params, Level16)
+	if ds == "" && params != "" {
+		return // only an underline color
+	}
+	for _, f := range strings.Split(ds, ";") {
+		f, sub, _ := strings.Cut(f, ":")
+		p, err := strconv.Atoi(f)
+		if err != nil && f != "" {
+			continue
+		}
+		switch {
+		case p == 0:
+			t.fg, t.bg, t.bold, t.rev, t.under = -1, -1, false, false, false
+		case p == 1:
+			t.bold = true
+		case p == 22:
+			t.bold = false
+		case p == 4:
+			t.under = sub != "0" // 4:0 is no underline
+		case p == 24:
+			t.under = false
+		case p == 7:
+			t.rev = true
+		case p == 27:
+			t.rev = false
+		case p >= 30 && p <= 37:
+			t.fg = p - 30
+		case p >= 90 && p <= 97:
+			t.fg = p - 90 + 8
+		case p == 39:
+			t.fg = -1
+		case p >= 40 && p <= 47:
+			t.bg = p - 40
+		case p >= 100 && p <= 107:
+			t.bg = p - 100 + 8
+		case p == 49:
+			t.bg = -1
+		}
+	}
+	t.check(t.api.SetAttribute(t.attr()))
+}
+
+func (t *ConsoleTranslator) csi(params string, final byte) {
+	if final == 'm' {
+		t.sgr(params)
+		return
+	}
+	if strings.HasPrefix(params, "?") {
+		if params == "?25" && (final == 'h' || final == 'l') {
+			t.check(t.api.ShowCursor(final == 'h'))
+		}
+		return
+	}
+
+	info, err := t.api.Info()
+	if err != nil {
+		t.check(err)
+		return
+	}
+	var args []int
+	for _, f := range strings.Split(params, ";") {
+		n, _ := strconv.Atoi(f)
+		args = append(args, n)
+	}
+	arg := func(i, def int) int {
+		if i < len(args) && args[i] > 0 {
+			return args[i]
+		}
+		return def
+	}
+	n, x, y := arg(0, 1), info.X, info.Y
+	switch final {
+	case 'A':
+		y -= n
+	case 'B':
+		y += n
+	case 'C':
+		x += n
+	case 'D':
+		x -= n
+	case 'E':
+		x, y = 0, y+n
+	case 'F':
+		x, y = 0, y-n
+	case 'G':
+		x = n - 1
+	case 'd':
+		y = info.Top + n - 1
+	case 'H', 'f':
+		x, y = arg(1, 1)-1, info.Top+n-1
+	case 'J':
+		t.eraseDisplay(info, arg(0, 0))
+		return
+	case 'K':
+		t.eraseLine(info, arg(0, 0))
+		return
+	case 'X':
+		t.check(t.api.Fill(x, y, min(n, info.Width-x), ' ', t.attr()))
+		return
+	case 's':
+		t.savePos()
+		return
+	case 'u':
+		t.restorePos()
+		return
+	default:
+		return
+	}
+	x = max(0, min(x, info.Width-1))
+	y = max(info.Top, min(y, info.Bottom))
+	t.check(t.api.SetCursor(x, y))
+}
+
+func (t *ConsoleTranslator) eraseDisplay(info ConsoleInfo, how int) {
+	w := info.Width
+	switch how {
+	case 0:
+		t.check(t.api.Fill(info.X, info.Y, (info.Bottom-info.Y)*w+w-info.X, ' ', t.attr()))
+	case 1:
+		t.check(t.api.Fill(0, info.Top, (info.Y-info.Top)*w+info.X+1, ' ', t.attr()))
+	case 2, 3:
+		t.check(t.api.Fill(0, info.Top, (info.Bottom-info.Top+1)*w, ' ', t.attr()))
+	}
+}
+
+func (t *ConsoleTranslator) eraseLine(info ConsoleInfo, how int) {
+	switch how {
+	case 0:
+		t.check(t.api.Fill(info.X, info.Y, info.Width-info.X, ' ', t.attr()))
+	case 1:
+		t.check(t.api.Fill(0, info.Y, info.X+1, ' ', t.attr()))
+	case 2:
+		t.check(t.api.Fill(0, info.Y, info.Width, ' ', t.attr()))
+	}
+}
+
+func (t *ConsoleTranslator) savePos() {
+	info, err := t.api.Info()
+	if err != nil {
+		t.check(err)
+		return
+	}
+	t.savedX, t.savedY, t.saved = info.X, info.Y, true
+}
+
+func (t *ConsoleTranslator) restorePos() {
+	if t.saved {
+		t.check(t.api.SetCursor(t.savedX, t.savedY))
+	}
+}
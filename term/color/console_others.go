@@ -0,0 +1,18 @@
+//go:build !windows
+// +build !windows
+
+package color
+
+// EnableVirtualTerminal does nothing except on Windows, the
+// terminals process the escape sequences already.
+func EnableVirtualTerminal() (restore func(), err error) {
+	return func() {}, nil
+}
+
+// SetupConsole does nothing except on Windows, see
+// [EnableVirtualTerminal].
+//
+//	defer color.SetupConsole()()
+func SetupConsole() (restore func()) {
+	return func() {}
+}
@@ -0,0 +1,188 @@
+package color
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// mockConsole is a console screen buffer in memory.
+type mockConsole struct {
+	w, h    int
+	top     int // the first row of the window
+	x, y    int
+	attr    uint16
+	cells   [][]rune
+	attrs   [][]uint16
+	visible bool
+}
+
+func newMockConsole(w, h, top int) *mockConsole {
+	c := &mockConsole{w: w, h: h, top: top, y: top, attr: 0x07, visible: true}
+	for range h {
+		c.cells = append(c.cells, []rune(strings.Repeat(" ", w)))
+		c.attrs = append(c.attrs, make([]uint16, w))
+	}
+	return c
+}
+
+func (c *mockConsole) Info() (ConsoleInfo, error) {
+	return ConsoleInfo{Width: c.w, Height: c.h, X: c.x, Y: c.y, Attr: c.attr, Top: c.top, Bottom: c.h - 1}, nil
+}
+
+func (c *mockConsole) SetAttribute(attr uint16) error { c.attr = attr; return nil }
+
+func (c *mockConsole) SetCursor(x, y int) error { c.x, c.y = x, y; return nil }
+
+func (c *mockConsole) Fill(x, y, n int, ch rune, attr uint16) error {
+	for i := y*c.w + x; n > 0 && i < c.w*c.h; i, n = i+1, n-1 {
+		c.cells[i/c.w][i%c.w], c.attrs[i/c.w][i%c.w] = ch, attr
+	}
+	return nil
+}
+
+func (c *mockConsole) ShowCursor(visible bool) error { c.visible = visible; return nil }
+
+func (c *mockConsole) Write(text string) error {
+	for _, r := range text {
+		if r == '\n' {
+			c.x, c.y = 0, min(c.y+1, c.h-1)
+			continue
+		}
+		c.cells[c.y][c.x], c.attrs[c.y][c.x] = r, c.attr
+		if c.x++; c.x == c.w {
+			c.x, c.y = 0, min(c.y+1, c.h-1)
+		}
+	}
+	return nil
+}
+
+func (c *mockConsole) row(y int) string { return strings.TrimRight(string(c.cells[y]), " ") }
+
+// brokenConsole fails to write the text containing "!".
+type brokenConsole struct{ *mockConsole }
+
+func (c brokenConsole) Write(text string) error {
+	if strings.Contains(text, "!") {
+		return errors.New("broken")
+	}
+	return c.mockConsole.Write(text)
+}
+
+func TestConsoleTranslatorSGR(t *testing.T) {
+	for _, c := range []struct {
+		in   string
+		want uint16
+	}{
+		{"\x1b[31mx", 0x04},
+		{"\x1b[1;44mx", 0x1f},
+		{"\x1b[1;31;44mx", 0x1c},
+		{"\x1b[94mx", 0x09},
+		{"\x1b[38;5;196mx", 0x0c},
+		{"\x1b[38;2;0;0;200mx", 0x01},
+		{"\x1b[31;7mx", 0x40},
+		{"\x1b[4mx", 0x8007},
+		{"\x1b[4:3m\x1b[4:0mx", 0x07},
+		{"\x1b[31;42m\x1b[39mx", 0x27},
+		{"\x1b[1;31;7m\x1b[0mx", 0x07},
+		{"\x1b[32m\x1b[58;5;1mx", 0x02},
+	} {
+		con := newMockConsole(10, 2, 0)
+		tr := NewConsoleTranslator(con)
+		if _, err := tr.Write([]byte(c.in)); err != nil {
+			t.Fatal(err)
+		}
+		if got := con.attrs[0][0]; got != c.want || con.row(0) != "x" {
+			t.Errorf("%q: the attribute is %#x, want %#x (%q)", c.in, got, c.want, con.row(0))
+		}
+	}
+}
+
+func TestConsoleTranslatorCursor(t *testing.T) {
+	for _, c := range []struct {
+		name, in string
+		rows     []string
+		x, y     int
+	}{
+		{"moves", "\x1b[2Bx\x1b[1Ay\x1b[3Dz\x1b[2Cw", []string{"", "zy w", "x"}, 4, 1},
+		{"cup", "\x1b[2;3Hx\x1b[Hy", []string{"y", "  x"}, 1, 0},
+		{"clamp", "\x1b[99;99Hx\x1b[99A\x1b[99Dy", []string{"y", "", "", "     x"}, 1, 0},
+		{"next prev line", "ab\x1b[2Ec\x1b[Fd", []string{"ab", "d", "c"}, 1, 1},
+		{"cha vpa", "\x1b[4Gx\x1b[3dy", []string{"   x", "", "    y"}, 5, 2},
+		{"erase line", "abcde\x1b[3G\x1b[K", []string{"ab"}, 2, 0},
+		{"erase line left", "abcde\x1b[3G\x1b[1K", []string{"   de"}, 2, 0},
+		{"erase display", "ab\ncd\nef\x1b[2;2H\x1b[J", []string{"ab", "c", ""}, 1, 1},
+		{"erase display up", "ab\ncd\nef\x1b[2;2H\x1b[1J", []string{"", "", "ef"}, 1, 1},
+		{"erase chars", "abcde\x1b[2G\x1b[3X", []string{"a   e"}, 1, 0},
+		{"save restore", "ab\x1b7\ncd\x1b8x\x1b[s\x1b[3Hy\x1b[uz", []string{"abxz", "cd", "y"}, 4, 0},
+		{"dropped", "a\x1b]0;title\x07b\x1b]8;;http://x\x1b\\c\x1b]8;;\x1b\\\x1b[?2004hd\x1b[2Se", []string{"abcde"}, 5, 0},
+	} {
+		// the window begins at row 2 of the screen buffer
+		con := newMockConsole(6, 6, 2)
+		tr := NewConsoleTranslator(con)
+		if _, err := tr.Write([]byte(c.in)); err != nil {
+			t.Fatal(err)
+		}
+		for i, want := range c.rows {
+			if got := con.row(con.top + i); got != want {
+				t.Errorf("%s: row %d reads %q, want %q", c.name, i, got, want)
+			}
+		}
+		if con.x != c.x || con.y-con.top != c.y {
+			t.Errorf("%s: the cursor is at %d,%d, want %d,%d", c.name, con.x, con.y-con.top, c.x, c.y)
+		}
+	}
+}
+
+func TestConsoleTranslatorSplitWrites(t *testing.T) {
+	con := newMockConsole(10, 2, 0)
+	tr := NewConsoleTranslator(con)
+	in := "\x1b[?25l\x1b[1;32mok\x1b[0m \x1b]2;t\x1b\\x\x1b[?25h"
+	for i := range len(in) {
+		if _, err := tr.Write([]byte{in[i]}); err != nil {
+			t.Fatal(err)
+		}
+		if i == 10 && con.visible {
+			t.Error("the cursor isn't hidden")
+		}
+	}
+	if got := con.row(0); got != "ok x" {
+		t.Errorf("row 0 reads %q", got)
+	}
+	if con.attrs[0][0] != 0x0a || con.attrs[0][1] != 0x0a || con.attrs[0][3] != 0x07 {
+		t.Errorf("the attributes are %#x", con.attrs[0][:4])
+	}
+	if !con.visible {
+		t.Error("the cursor is hidden")
+	}
+}
+
+func TestConsoleTranslatorError(t *testing.T) {
+	con := newMockConsole(10, 2, 0)
+	tr := NewConsoleTranslator(brokenConsole{con})
+	_, _ = tr.Write([]byte("a\x1b[3"))
+	n, err := tr.Write([]byte("1mok\x1b[0m!\x1b[1"))
+	if err == nil || n != len("1mok\x1b[0m") {
+		t.Errorf("got %d, %v", n, err)
+	}
+	if got := con.row(0); got != "aok" || con.attrs[0][1] != 0x04 {
+		t.Errorf("row 0 reads %q in %#x", got, con.attrs[0][1])
+	}
+	// the rest is written again without the held sequence
+	if n, err = tr.Write([]byte("x")); n != 1 || err != nil || con.row(0) != "aokx" {
+		t.Errorf("got %d, %v, row 0 reads %q", n, err, con.row(0))
+	}
+}
+
+func TestConsoleTranslatorOutput(t *testing.T) {
+	// an Output of the translator is a colorful tty
+	con := newMockConsole(20, 2, 0)
+	o := NewOutput(NewConsoleTranslator(con), WithOutputLevel(Level16), WithOutputNoColor(false))
+	if !o.IsTty() || !o.IsColorful() {
+		t.Errorf("tty %v, colorful %v", o.IsTty(), o.IsColorful())
+	}
+	o.Colored(FgRed, "failed")
+	if got := con.row(0); got != "failed" || con.attrs[0][0] != 0x04 || con.attr != 0x07 {
+		t.Errorf("row 0 reads %q in %#x, the attribute is %#x", got, con.attrs[0][0], con.attr)
+	}
+}
@@ -0,0 +1,180 @@
+//go:build windows
+// +build windows
+
+package color
+
+import (
+	"os"
+	"sync"
+	"syscall"
+	"unicode/utf16"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+var (
+	setConsoleTextAttributeProc    *syscall.LazyProc
+	fillConsoleOutputCharacterProc *syscall.LazyProc
+	fillConsoleOutputAttributeProc *syscall.LazyProc
+)
+
+func init() {
+	kernel32 := syscall.NewLazyDLL("kernel32.dll")
+	setConsoleTextAttributeProc = kernel32.NewProc("SetConsoleTextAttribute")
+	fillConsoleOutputCharacterProc = kernel32.NewProc("FillConsoleOutputCharacterW")
+	fillConsoleOutputAttributeProc = kernel32.NewProc("FillConsoleOutputAttribute")
+}
+
+// winConsole is the ConsoleAPI of a console screen buffer handle.
+type winConsole struct{ h uintptr }
+
+func (c winConsole) Fd() uintptr { return c.h }
+
+func (c winConsole) Info() (ConsoleInfo, error) {
+	info, err := getConsoleScreenBufferInfo(c.h)
+	if err != nil {
+		return ConsoleInfo{}, err
+	}
+	return ConsoleInfo{
+		Width: int(info.Size.X), Height: int(info.Size.Y),
+		X: int(info.CursorPosition.X), Y: int(info.CursorPosition.Y),
+		Attr: uint16(info.Attributes),
+		Top:  int(info.Window.Top), Bottom: int(info.Window.Bottom),
+	}, nil
+}
+
+func (c winConsole) SetAttribute(attr uint16) error {
+	return checkError(setConsoleTextAttributeProc.Call(c.h, uintptr(attr)))
+}
+
+func (c winConsole) SetCursor(x, y int) error {
+	return setConsoleCursorPosition(c.h, COORD{X: SHORT(x), Y: SHORT(y)})
+}
+
+func (c winConsole) Fill(x, y, n int, ch rune, attr uint16) error {
+	if n <= 0 {
+		return nil
+	}
+	var written uint32
+	pos := coordToPointer(COORD{X: SHORT(x), Y: SHORT(y)})
+	if err := checkError(fillConsoleOutputCharacterProc.Call(c.h, uintptr(ch), uintptr(n), pos, uintptr(unsafe.Pointer(&written)))); err != nil {
+		return err
+	}
+	return checkError(fillConsoleOutputAttributeProc.Call(c.h, uintptr(attr), uintptr(n), pos, uintptr(unsafe.Pointer(&written))))
+}
+
+func (c winConsole) ShowCursor(visible bool) error {
+	var info CONSOLE_CURSOR_INFO
+	if err := checkError(getConsoleCursorInfoProc.Call(c.h, uintptr(unsafe.Pointer(&info)))); err != nil {
+		return err
+	}
+	info.Visible = 0
+	if visible {
+		info.Visible = 1
+	}
+	return checkError(setConsoleCursorInfoProc.Call(c.h, uintptr(unsafe.Pointer(&info))))
+}
+
+func (c winConsole) Write(text string) error {
+	buf := utf16.Encode([]rune(text))
+	for len(buf) > 0 {
+		var done uint32
+		if err := windows.WriteConsole(windows.Handle(c.h), &buf[0], uint32(len(buf)), &done, nil); err != nil {
+			return err
+		}
+		if done == 0 {
+			return syscall.EIO
+		}
+		buf = buf[done:]
+	}
+	return nil
+}
+
+// EnableVirtualTerminal enables the virtual terminal processing of
+// the consoles of stdout and stderr, and the virtual terminal input
+// of the console of stdin. The handles which aren't consoles, such
+// as the redirected ones, are skipped.
+//
+// restore puts the console modes back, it should be called on
+// exit. err is [ErrNoVirtualTerminal] if an output console can't
+// process the escape sequences, the modes are restored already
+// in this case.
+func EnableVirtualTerminal() (restore func(), err error) {
+	type saved struct {
+		h    windows.Handle
+		mode uint32
+	}
+	var modes []saved
+	restore = func() {
+		for _, s := range modes {
+			_ = windows.SetConsoleMode(s.h, s.mode)
+		}
+	}
+
+	for _, f := range []*os.File{os.Stdout, os.Stderr} {
+		h := windows.Handle(f.Fd())
+		var mode uint32
+		if windows.GetConsoleMode(h, &mode) != nil {
+			continue
+		}
+		want := mode | windows.ENABLE_PROCESSED_OUTPUT | windows.ENABLE_VIRTUAL_TERMINAL_PROCESSING
+		if want != mode {
+			if windows.SetConsoleMode(h, want) != nil {
+				restore()
+				return func() {}, ErrNoVirtualTerminal
+			}
+			modes = append(modes, saved{h, mode})
+		}
+	}
+
+	// stdin is optional, the legacy console can't do it either
+	h := windows.Handle(os.Stdin.Fd())
+	var mode uint32
+	if windows.GetConsoleMode(h, &mode) == nil && mode&windows.ENABLE_VIRTUAL_TERMINAL_INPUT == 0 {
+		if windows.SetConsoleMode(h, mode|windows.ENABLE_VIRTUAL_TERMINAL_INPUT) == nil {
+			modes = append(modes, saved{h, mode})
+		}
+	}
+	return restore, nil
+}
+
+var setupMu sync.Mutex
+
+// SetupConsole prepares the consoles for the escape sequences.
+// It calls [EnableVirtualTerminal], and if the consoles can't
+// process the sequences, [Stdout] and [Stderr] are replaced with
+// the outputs of a [ConsoleTranslator] for the consoles, and so
+// is [Out], the default output of [Cursor] and [RowsBlock]. The
+// colors and the cursor movements written through them work on
+// the legacy console too.
+//
+// restore puts the modes and the outputs back:
+//
+//	defer color.SetupConsole()()
+func SetupConsole() (restore func()) {
+	setupMu.Lock()
+	defer setupMu.Unlock()
+	restoreModes, err := EnableVirtualTerminal()
+	if err == nil {
+		return restoreModes
+	}
+
+	out, stdout, stderr := Out, Stdout, Stderr
+	legacy := func(f *os.File, def *Output) *Output {
+		var mode uint32
+		if windows.GetConsoleMode(windows.Handle(f.Fd()), &mode) != nil {
+			return def // not a console
+		}
+		return NewOutput(NewConsoleTranslator(winConsole{h: f.Fd()}), WithOutputLevel(Level16))
+	}
+	Stdout, Stderr = legacy(os.Stdout, stdout), legacy(os.Stderr, stderr)
+	if Stdout != stdout && out == Writer(os.Stdout) {
+		Out = Stdout
+	}
+	return func() {
+		setupMu.Lock()
+		defer setupMu.Unlock()
+		Out, Stdout, Stderr = out, stdout, stderr
+	}
+}
@@ -2,7 +2,6 @@ package color
 
 import (
 	"fmt"
-	"strconv"
 )
 
 // CSI code
@@ -32,16 +31,7 @@ func (s csiS) Println(args ...any) *Cursor {
 
 // Printf prints contents into buffer for [Cursor.Build].
 func (s csiS) Printf(format string, args ...any) *Cursor {
-	_, _ = s.Cursor.sb.WriteString(csi)
-	if s.n > 0 {
-		_, _ = s.Cursor.sb.WriteString(strconv.Itoa(s.n))
-	}
-	if s.m > 0 {
-		_ = s.Cursor.sb.WriteByte(';')
-		_, _ = s.Cursor.sb.WriteString(strconv.Itoa(s.m))
-	}
-	_ = s.Cursor.sb.WriteByte(s.ch)
-
+	_, _ = s.Cursor.sb.WriteString(csiSeq(s.ch, s.n, s.m, ecmaSeq(s.ch, s.n, s.m)))
 	return s.Cursor.Printf(format, args...)
 }
 
@@ -4,7 +4,8 @@ import (
 	"os"
 )
 
-// Out is the default output writer for the Writer
+// Out is the default output writer for the Writer, the cursor
+// functions, [New] and [NewRowsBlock].
 var Out Writer = os.Stdout
 
 func Hide() {
@@ -24,7 +24,7 @@ func (s *Cursor) Back(n int) csiS    { return s.CSI('D', n) } // use color.Left(
 func (s *Cursor) NextLine(n int) csiS      { return s.CSI('E', n) }        // Moves cursor to beginning of the line n (default 1) lines down. (not ANSI.SYS)
 func (s *Cursor) PrevLine(n int) csiS      { return s.CSI('F', n) }        // Moves cursor to beginning of the line n (default 1) lines up. (not ANSI.SYS)
 func (s *Cursor) HorzCol(colAbs int) csiS  { return s.CSI('G', colAbs) }   // Moves the cursor to column n (default 1).
-func (s *Cursor) MoveTo(col, row int) csiS { return s.CSI('H', col, row) } //
+func (s *Cursor) MoveTo(col, row int) csiS { return s.CSI('H', row, col) } // Moves the cursor to col, row (1-based).
 func (s *Cursor) Erase(n EraseTo) csiS     { return s.CSI('J', int(n)) }   // Erase in Display
 func (s *Cursor) EraseInLine(n int) csiS   { return s.CSI('K', n) }        // Erase in Line
 
@@ -109,9 +109,7 @@ func cursorHorizontalAbsolute(w Writer, n int) {
 }
 
 func writecsi(out Writer, what rune) {
-	var bb = []byte(aecHideCursor)
-	_, _ = out.Write(bb[0:2])
-	_, _ = out.Write([]byte{byte(what)})
+	_, _ = out.Write([]byte(csiSeq(byte(what), 0, 0, csi+string(what))))
 }
 
 func writecsiseq(out Writer, what rune, n int) {
@@ -121,17 +119,17 @@ func writecsiseq(out Writer, what rune, n int) {
 	var ss = strconv.Itoa(n)
 	_, _ = sb.WriteString(ss)
 	_ = sb.WriteByte(byte(what))
-	_, _ = out.Write(sb.Bytes())
+	_, _ = out.Write([]byte(csiSeq(byte(what), n, 0, sb.String())))
 }
 
 // showCursor shows the cursor.
 func showCursor(w Writer) {
-	safeWrite(w, []byte(aecShowCursor))
+	safeWrite(w, []byte(capSeq("cnorm", aecShowCursor)))
 }
 
 // hideCursor hides the cursor.
 func hideCursor(w Writer) {
-	safeWrite(w, []byte(aecHideCursor))
+	safeWrite(w, []byte(capSeq("civis", aecHideCursor)))
 }
 
 func safeWrite(w Writer, b []byte) (n int, e error) {
@@ -139,6 +137,3 @@ func safeWrite(w Writer, b []byte) (n int, e error) {
 }
 
 // var escape = []byte{'\x1b'}
-
-const aecHideCursor = "\x1b[?25l"
-const aecShowCursor = "\x1b[?25h"
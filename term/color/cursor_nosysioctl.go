@@ -23,7 +23,7 @@ func (s *Cursor) Back(n int) csiS    { return s.CSI('D', n) } // use color.Left(
 func (s *Cursor) NextLine(n int) csiS      { return s.CSI('E', n) }        // Moves cursor to beginning of the line n (default 1) lines down. (not ANSI.SYS)
 func (s *Cursor) PrevLine(n int) csiS      { return s.CSI('F', n) }        // Moves cursor to beginning of the line n (default 1) lines up. (not ANSI.SYS)
 func (s *Cursor) HorzCol(colAbs int) csiS  { return s.CSI('G', colAbs) }   // Moves the cursor to column n (default 1).
-func (s *Cursor) MoveTo(col, row int) csiS { return s.CSI('H', col, row) } //
+func (s *Cursor) MoveTo(col, row int) csiS { return s.CSI('H', row, col) } // Moves the cursor to col, row (1-based).
 func (s *Cursor) Erase(n EraseTo) csiS     { return s.CSI('J', int(n)) }   // Erase in Display
 func (s *Cursor) EraseInLine(n int) csiS   { return s.CSI('K', n) }        // Erase in Line
 
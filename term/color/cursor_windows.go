@@ -54,7 +54,7 @@ func (s *Cursor) Back(n int) csiS    { return s.pCSI('D', n) } // use color.Left
 func (s *Cursor) NextLine(n int) csiS      { return s.pCSI('E', n) }        // Moves cursor to beginning of the line n (default 1) lines down. (not ANSI.SYS)
 func (s *Cursor) PrevLine(n int) csiS      { return s.pCSI('F', n) }        // Moves cursor to beginning of the line n (default 1) lines up. (not ANSI.SYS)
 func (s *Cursor) HorzCol(colAbs int) csiS  { return s.pCSI('G', colAbs) }   // Moves the cursor to column n (default 1).
-func (s *Cursor) MoveTo(col, row int) csiS { return s.pCSI('H', col, row) } //
+func (s *Cursor) MoveTo(col, row int) csiS { return s.pCSI('H', row, col) } // Moves the cursor to col, row (1-based).
 func (s *Cursor) Erase(n EraseTo) csiS     { return s.pCSI('J', int(n)) }   // Erase in Display
 func (s *Cursor) EraseInLine(n int) csiS   { return s.pCSI('K', n) }        // Erase in Line
 
@@ -0,0 +1,333 @@
+package color
+
+import (
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/hedzr/is/term/chk"
+)
+
+// The color levels, the same values of [chk.MinVal].
+const (
+	Level16  int64 = 16
+	Level88  int64 = 88
+	Level256 int64 = 256
+	Level16m int64 = 1 << 24
+)
+
+var forcedLevel atomic.Int64
+
+// SetColorLevel forces the color level (such as [Level256]) of
+// all outputs, 0 restores the detection (see [ColorLevel]). It
+// applies to the writers, the strings returned by
+// [Color16m.Color] and [Color256.Color] are kept in full depth.
+func SetColorLevel(level int64) { forcedLevel.Store(level) }
+
+var (
+	detectOnce    sync.Once
+	detectedLevel int64
+)
+
+// ColorLevel returns the color level of w, the forced one (see
+// [SetColorLevel]), or the detected one (see [chk.IsColorful])
+// if w is a terminal. 0 means unknown, the colors are written
+// as is. The level of an [Output] is its own one, or 0 if it
+// isn't colorful.
+func ColorLevel(w io.Writer) int64 {
+	if o, ok := w.(*Output); ok {
+		if !o.IsColorful() {
+			return 0
+		}
+		return o.Level()
+	}
+	if level := forcedLevel.Load(); level > 0 {
+		return level
+	}
+	if !chk.IsTty(w) {
+		return 0
+	}
+	detectOnce.Do(func() {
+		if chk.IsColorful(w) {
+			detectedLevel = chk.MinVal
+		}
+	})
+	return detectedLevel
+}
+
+// XtermPalette is the default palette of xterm, which is used to
+// map the colors to the 16 ANSI colors.
+var XtermPalette = &Palette{
+	Foreground: [3]byte{0x00, 0x00, 0x00},
+	Background: [3]byte{0xff, 0xff, 0xff},
+	ANSI: [16][3]byte{
+		{0x00, 0x00, 0x00}, {0xcd, 0x00, 0x00}, {0x00, 0xcd, 0x00}, {0xcd, 0xcd, 0x00},
+		{0x00, 0x00, 0xee}, {0xcd, 0x00, 0xcd}, {0x00, 0xcd, 0xcd}, {0xe5, 0xe5, 0xe5},
+		{0x7f, 0x7f, 0x7f}, {0xff, 0x00, 0x00}, {0x00, 0xff, 0x00}, {0xff, 0xff, 0x00},
+		{0x5c, 0x5c, 0xff}, {0xff, 0x00, 0xff}, {0x00, 0xff, 0xff}, {0xff, 0xff, 0xff},
+	},
+}
+
+// Downsample maps c to the nearest color which can be shown at
+// the color level: a true color becomes a [Color256] at
+// [Level256], and a true or 256 color becomes a [Color16] at
+// [Level16] (or [Level88]). The distances are measured in the
+// OKLab color space, so the result looks close to the original.
+//
+// The other colors, and all colors at [Level16m] or an unknown
+// level (<= 0), are returned as is.
+func Downsample(c Color, level int64) Color {
+	if level <= 0 || level >= Level16m {
+		return c
+	}
+	var rgb [3]byte
+	var bg bool
+	switch v := c.(type) {
+	case Color16m:
+		rgb, bg = [3]byte{v.clr[0], v.clr[1], v.clr[2]}, v.bg
+	case *Color16m:
+		rgb, bg = [3]byte{v.clr[0], v.clr[1], v.clr[2]}, v.bg
+	case Color256:
+		if level >= Level256 {
+			return c
+		}
+		return color256To16(v.clr[0], v.bg)
+	case *Color256:
+		if level >= Level256 {
+			return c
+		}
+		return color256To16(v.clr[0], v.bg)
+	default:
+		return c
+	}
+	if level >= Level256 {
+		return Color256{clr: [4]byte{nearest256(rgb)}, bg: bg}
+	}
+	return ansiColor(nearest16(rgb), bg)
+}
+
+func color256To16(n byte, bg bool) Color16 {
+	if n < 16 {
+		return ansiColor(int(n), bg)
+	}
+	return ansiColor(nearest16(XtermPalette.rgb256(n)), bg)
+}
+
+// ansiColor returns the fg or bg code of the ANSI color i (0..15).
+func ansiColor(i int, bg bool) Color16 {
+	base := 30
+	if i >= 8 {
+		base, i = 90, i-8
+	}
+	if bg {
+		base += 10
+	}
+	return Color16(base + i)
+}
+
+// oklab is a color in the OKLab color space.
+type oklab struct{ l, a, b float64 }
+
+// linearRGB converts an sRGB component to the linear one.
+func linearRGB(v byte) float64 {
+	c := float64(v) / 255
+	if c <= 0.04045 {
+		return c / 12.92
+	}
+	return math.Pow((c+0.055)/1.055, 2.4)
+}
+
+func toOKLab(rgb [3]byte) oklab {
+	r, g, b := linearRGB(rgb[0]), linearRGB(rgb[1]), linearRGB(rgb[2])
+	l := math.Cbrt(0.4122214708*r + 0.5363325363*g + 0.0514459929*b)
+	m := math.Cbrt(0.2119034982*r + 0.6806995451*g + 0.1073969566*b)
+	s := math.Cbrt(0.0883024619*r + 0.2817188376*g + 0.6299787005*b)
+	return oklab{
+		l: 0.2104542553*l + 0.7936177850*m - 0.0040720468*s,
+		a: 1.9779984951*l - 2.4285922050*m + 0.4505937099*s,
+		b: 0.0259040371*l + 0.7827717662*m - 0.8086757660*s,
+	}
+}
+
+// distance returns the squared distance, the lightness difference
+// is weighted by wl.
+func (c oklab) distance(o oklab, wl float64) float64 {
+	dl, da, db := c.l-o.l, c.a-o.a, c.b-o.b
+	return wl*dl*dl + da*da + db*db
+}
+
+var (
+	labOnce  sync.Once
+	labTable [256]oklab // the xterm 256 colors in OKLab
+)
+
+func initLabTable() {
+	for i := range labTable {
+		labTable[i] = toOKLab(XtermPalette.rgb256(byte(i)))
+	}
+}
+
+// nearest16 returns the index of the nearest ANSI color. The
+// lightness is weighted down since the hue matters more among
+// the few colors, a dark green should be green rather than gray.
+func nearest16(rgb [3]byte) int { return nearestIn(rgb, 0, 16, 0.5) }
+
+// nearest256 returns the nearest color in the 6x6x6 cube and the
+// gray ramp, the first 16 colors are skipped since they vary by
+// terminals.
+func nearest256(rgb [3]byte) byte { return byte(nearestIn(rgb, 16, 256, 1)) }
+
+func nearestIn(rgb [3]byte, from, to int, wl float64) (best int) {
+	labOnce.Do(initLabTable)
+	lab := toOKLab(rgb)
+	bestDist := math.Inf(1)
+	for i := from; i < to; i++ {
+		if d := lab.distance(labTable[i], wl); d < bestDist {
+			best, bestDist = i, d
+		}
+	}
+	return
+}
+
+// DownsampleString rewrites the colors of the SGR sequences in s
+// for the color level, see [Downsample].
+func DownsampleString(s string, level int64) string {
+	if level <= 0 || level >= Level16m || !strings.Contains(s, csi) {
+		return s
+	}
+	var sb strings.Builder
+	scanEscapes(s, func(part string, esc bool) bool {
+		if esc && isSGR(part) {
+			params := downsampleSGR(part[2:len(part)-1], level)
+			if params == "" && len(part) > 3 {
+				return true // only an underline color, which is dropped
+			}
+			part = csi + params + "m"
+		}
+		_, _ = sb.WriteString(part)
+		return true
+	})
+	return sb.String()
+}
+
+// downsampleSGR rewrites the extended colors in the parameters
+// of an SGR sequence.
+func downsampleSGR(params string, level int64) string {
+	if !strings.Contains(params, "8") {
+		return params // no 38, 48 or 58
+	}
+	fields := strings.Split(params, ";")
+	out := make([]string, 0, len(fields))
+	for k := 0; k < len(fields); k++ {
+		sub := strings.Split(fields[k], ":")
+		code := sub[0]
+		if code != "38" && code != "48" && code != "58" {
+			out = append(out, fields[k])
+			continue
+		}
+		var clr Color
+		var orig []string
+		if len(sub) > 1 {
+			clr, orig = extendedColor(sub[1:], code == "48", true), fields[k:k+1]
+		} else {
+			var used int
+			clr, used = extendedColorFields(fields[k+1:], code == "48")
+			orig = fields[k : k+1+used]
+			k += used
+		}
+		switch c := Downsample(clr, level).(type) {
+		case Color16:
+			if code != "58" { // no 16 colors for the underline
+				out = append(out, strconv.Itoa(int(c)))
+			}
+		case Color256:
+			out = append(out, code, "5", strconv.Itoa(int(c.clr[0])))
+		default:
+			out = append(out, orig...)
+		}
+	}
+	return strings.Join(out, ";")
+}
+
+// DepthWriter rewrites the colors written through it for a color
+// level, see [DownsampleString]. The escape sequences split
+// across the writes are handled.
+type DepthWriter struct {
+	w       io.Writer
+	level   int64
+	pending []byte
+}
+
+// NewDepthWriter returns a writer which downsamples the colors
+// for the level, 0 means [ColorLevel] of w.
+//
+//	w := color.NewDepthWriter(os.Stdout, 0)
+//	fmt.Fprint(w, color.NewColor16m(255, 135, 0, false).Wrap("orange"))
+func NewDepthWriter(w io.Writer, level int64) *DepthWriter {
+	if level == 0 {
+		level = ColorLevel(w)
+	}
+	return &DepthWriter{w: w, level: level}
+}
+
+// Write writes p, an incomplete escape sequence at the end is
+// held until the next Write or Flush.
+func (d *DepthWriter) Write(p []byte) (n int, err error) {
+	if d.level <= 0 || d.level >= Level16m {
+		if len(d.pending) > 0 {
+			if err = d.Flush(); err != nil {
+				return
+			}
+		}
+		return d.w.Write(p)
+	}
+	s := string(append(d.pending, p...))
+	cut := len(s)
+	if i := pendingAt(s); i < len(s) && len(s)-i <= maxLineLen {
+		cut = i
+	}
+	d.pending = append(d.pending[:0], s[cut:]...)
+	if cut > 0 {
+		if _, err = io.WriteString(d.w, DownsampleString(s[:cut], d.level)); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+// Flush writes the held bytes.
+func (d *DepthWriter) Flush() (err error) {
+	if len(d.pending) > 0 {
+		_, err = d.w.Write(d.pending)
+		d.pending = d.pending[:0]
+	}
+	return
+}
+
+// Fd returns the file descriptor of the underlying writer, or
+// ^uintptr(0) if it has none.
+func (d *DepthWriter) Fd() uintptr {
+	if f, ok := d.w.(interface{ Fd() uintptr }); ok {
+		return f.Fd()
+	}
+	return ^uintptr(0)
+}
+
+// escapeComplete reports whether the escape sequence seq has its
+// terminator.
+func escapeComplete(seq string) bool {
+	if len(seq) < 2 {
+		return false
+	}
+	switch seq[1] {
+	case '[':
+		c := seq[len(seq)-1]
+		return len(seq) > 2 && c >= 0x40 && c <= 0x7e
+	case ']', 'P', 'X', '^', '_':
+		return strings.HasSuffix(seq, "\a") || strings.HasSuffix(seq, "\x1b\\")
+	}
+	return true
+}
@@ -0,0 +1,90 @@
+package color
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestDownsample(t *testing.T) {
+	tests := []struct {
+		c     Color
+		level int64
+		want  Color
+	}{
+		{Color16m{clr: [4]byte{255, 0, 0}}, Level256, Color256{clr: [4]byte{196}}},
+		{Color16m{clr: [4]byte{128, 128, 128}, bg: true}, Level256, Color256{clr: [4]byte{244}, bg: true}},
+		{NewColor16m(255, 135, 0, false), Level256, Color256{clr: [4]byte{208}}},
+		{Color16m{clr: [4]byte{255, 0, 0}}, Level16, FgLightRed},
+		{Color16m{clr: [4]byte{10, 10, 120}, bg: true}, Level16, BgBlue},
+		{Color16m{clr: [4]byte{250, 250, 250}}, Level88, FgWhite},
+		{Color256{clr: [4]byte{9}}, Level16, FgLightRed},
+		{Color256{clr: [4]byte{28}, bg: true}, Level16, BgGreen},
+		{Color256{clr: [4]byte{28}}, Level256, Color256{clr: [4]byte{28}}},
+		{Color16m{clr: [4]byte{1, 2, 3}}, Level16m, Color16m{clr: [4]byte{1, 2, 3}}},
+		{Color16m{clr: [4]byte{1, 2, 3}}, 0, Color16m{clr: [4]byte{1, 2, 3}}},
+		{FgRed, Level16, FgRed},
+	}
+	for i, tc := range tests {
+		if got := Downsample(tc.c, tc.level); !sameColor(got, tc.want) {
+			t.Errorf("%d. Downsample(%q, %d) = %q, want %q", i, tc.c.Color(), tc.level, got.Color(), tc.want.Color())
+		}
+	}
+}
+
+func TestDownsampleString(t *testing.T) {
+	tests := []struct {
+		in    string
+		level int64
+		want  string
+	}{
+		{"\x1b[38;2;255;0;0mred\x1b[0m", Level256, "\x1b[38;5;196mred\x1b[0m"},
+		{"\x1b[1;38;2;255;0;0;48;5;21mx\x1b[0m", Level16, "\x1b[1;91;44mx\x1b[0m"},
+		{"\x1b[38:2::255:0:0mx", Level16, "\x1b[91mx"},
+		{"\x1b[4:3;58;2;255;0;0mx", Level16, "\x1b[4:3mx"},
+		{"\x1b[58;5;196mx", Level16, "x"},
+		{"\x1b[38;2;255;0;0mx", Level16m, "\x1b[38;2;255;0;0mx"},
+		{"\x1b[2J\x1b[31mplain", Level16, "\x1b[2J\x1b[31mplain"},
+	}
+	for i, tc := range tests {
+		if got := DownsampleString(tc.in, tc.level); got != tc.want {
+			t.Errorf("%d. DownsampleString(%q, %d) = %q, want %q", i, tc.in, tc.level, got, tc.want)
+		}
+	}
+}
+
+func TestDepthWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewDepthWriter(&buf, Level256)
+	for _, chunk := range []string{"a\x1b[38;2;", "255;0;0m", "b\x1b", "[0m"} {
+		if n, err := w.Write([]byte(chunk)); err != nil || n != len(chunk) {
+			t.Fatalf("Write(%q) = %d, %v", chunk, n, err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := buf.String(), "a\x1b[38;5;196mb\x1b[0m"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	// a lone ESC at the end is held too
+	buf.Reset()
+	for _, chunk := range []string{"x\x1b", "[38;2;255;135;0my"} {
+		_, _ = w.Write([]byte(chunk))
+	}
+	if got, want := buf.String(), "x\x1b[38;5;208my"; got != want {
+		t.Errorf("split ESC: got %q, want %q", got, want)
+	}
+}
+
+func TestCursorForcedLevel(t *testing.T) {
+	SetColorLevel(Level16)
+	defer SetColorLevel(0)
+	if got, want := New().RGB(255, 0, 0).Printf("x").Build(), "\x1b[91mx\x1b[0m"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	// the colors themselves are kept in full depth
+	if got, want := NewColor16m(255, 0, 0, false).Wrap("x"), "\x1b[38;2;255;0;0mx\x1b[0m"; got != want {
+		t.Errorf("Color16m.Wrap: got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,252 @@
+package color
+
+import (
+	"context"
+	"io"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/hedzr/is/states"
+	"github.com/hedzr/is/term"
+	"github.com/hedzr/is/term/chk"
+)
+
+// HorizontalGradient paints the text in the gradient across the
+// stops from the left to the right, see [Gradient]. The columns
+// of all lines get the same colors, so a multi-line banner is
+// painted evenly. The spaces and the escape sequences in s are
+// kept. s is returned as is in no-color mode.
+//
+//	fmt.Println(color.HorizontalGradient(banner, red, yellow, green))
+func HorizontalGradient(s string, stops ...Color16m) string {
+	if states.Env().IsNoColorMode() || len(stops) == 0 || s == "" {
+		return s
+	}
+	colors := Gradient(textWidth(s), stops...)
+	return paintText(s, func(row, col int) Color16m { return colors[min(col, len(colors)-1)] })
+}
+
+// VerticalGradient paints the lines of the text in the gradient
+// across the stops from the top to the bottom, see
+// [HorizontalGradient].
+func VerticalGradient(s string, stops ...Color16m) string {
+	if states.Env().IsNoColorMode() || len(stops) == 0 || s == "" {
+		return s
+	}
+	colors := Gradient(strings.Count(strings.TrimSuffix(s, "\n"), "\n")+1, stops...)
+	return paintText(s, func(row, col int) Color16m { return colors[row] })
+}
+
+// Rainbow paints the text in the hues of the rainbow from the
+// left to the right, see [HorizontalGradient].
+func Rainbow(s string) string {
+	if states.Env().IsNoColorMode() || s == "" {
+		return s
+	}
+	return rainbow(s, 0)
+}
+
+// rainbow paints s in the hues beginning from phase (in degrees).
+func rainbow(s string, phase float64) string {
+	width := max(textWidth(s), 1)
+	return paintText(s, func(row, col int) Color16m {
+		return NewColorHSL(math.Mod(phase+360*float64(col)/float64(width), 360), 1, .5)
+	})
+}
+
+// textWidth returns the columns of the widest line of s.
+func textWidth(s string) (width int) {
+	for _, line := range strings.Split(s, "\n") {
+		width = max(width, StringWidth(line))
+	}
+	return
+}
+
+// paintText paints each character of s in the color of its row
+// and column, the spaces and the escape sequences are kept. Each
+// painted line ends with a reset.
+func paintText(s string, clr func(row, col int) Color16m) string {
+	var sb strings.Builder
+	row, col, painted := 0, 0, false
+	var last string // the color written last
+	for i := 0; i < len(s); {
+		n, width, esc := nextToken(s[i:])
+		switch {
+		case esc:
+			last = ""
+		case s[i] == '\n':
+			if painted {
+				_, _ = sb.WriteString(ResetToNormalColor.Color())
+			}
+			row, col, painted, last = row+1, 0, false, ""
+		case s[i] != ' ':
+			if c := clr(row, col).AsFg().Color(); c != last {
+				_, _ = sb.WriteString(c)
+				last = c
+			}
+			painted = true
+		}
+		_, _ = sb.WriteString(s[i : i+n])
+		col += width
+		i += n
+	}
+	if painted {
+		_, _ = sb.WriteString(ResetToNormalColor.Color())
+	}
+	return sb.String()
+}
+
+// EffectOpt is the option of the animated effects, such as
+// [AnimateRainbow].
+type EffectOpt func(e *effect)
+
+type effect struct {
+	interval time.Duration
+	duration time.Duration
+}
+
+// WithEffectInterval sets the interval of the frames, default is
+// 50ms.
+func WithEffectInterval(d time.Duration) EffectOpt {
+	return func(e *effect) {
+		if d > 0 {
+			e.interval = d
+		}
+	}
+}
+
+// WithEffectDuration stops the effect after d, it runs until the
+// context is done by default. A typewriter stops when the whole
+// text is typed anyway.
+func WithEffectDuration(d time.Duration) EffectOpt {
+	return func(e *effect) { e.duration = d }
+}
+
+// AnimateRainbow shows the text in the rainbow hues which keep
+// moving, till ctx is done or the duration set by
+// [WithEffectDuration] has passed. The text is left in the hues
+// of [Rainbow].
+//
+// The effects are redrawn in place by a [RowsBlock] on w, and
+// they are written as the plain text once if w isn't a colorful
+// terminal or in no-color mode.
+//
+//	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+//	defer cancel()
+//	color.AnimateRainbow(ctx, os.Stdout, banner)
+func AnimateRainbow(ctx context.Context, w Writer, text string, opts ...EffectOpt) {
+	animate(ctx, w, text, opts, func(i int) (string, bool) {
+		return rainbow(text, math.Mod(float64(i)*12, 360)), false
+	}, func() string { return rainbow(text, 0) })
+}
+
+// AnimatePulse shows the text in clr which fades and brightens
+// in turn, see [AnimateRainbow]. The text is left in clr.
+func AnimatePulse(ctx context.Context, w Writer, text string, clr Color16m, opts ...EffectOpt) {
+	const period = 24 // frames
+	dark := clr.Mix(Color16m{}, .7)
+	paint := func(c Color16m) string {
+		return paintText(text, func(row, col int) Color16m { return c })
+	}
+	animate(ctx, w, text, opts, func(i int) (string, bool) {
+		t := (1 - math.Cos(2*math.Pi*float64(i%period)/period)) / 2
+		return paint(clr.Mix(dark, t)), false
+	}, func() string { return paint(clr) })
+}
+
+// AnimateTypewriter types the text character by character, see
+// [AnimateRainbow]. The escape sequences in text, such as the
+// colors, are kept. The whole text is shown at the end, even if
+// ctx is done earlier.
+func AnimateTypewriter(ctx context.Context, w Writer, text string, opts ...EffectOpt) {
+	animate(ctx, w, text, opts, func(i int) (string, bool) {
+		return typed(text, i+1)
+	}, func() string { return text })
+}
+
+// typed returns the first n characters of s, and whether s is
+// typed completely.
+func typed(s string, n int) (string, bool) {
+	k, styled := 0, false
+	for i := 0; i < len(s); {
+		m, _, esc := nextToken(s[i:])
+		switch {
+		case esc:
+			styled = true
+		case s[i] == '\n':
+		case k == n:
+			if styled {
+				return s[:i] + ResetToNormalColor.Color(), false
+			}
+			return s[:i], false
+		default:
+			k++
+		}
+		i += m
+	}
+	return s, true
+}
+
+// animate draws the frames till ctx is done, the duration has
+// passed or a frame reports done, and then the last one.
+func animate(ctx context.Context, w Writer, text string, opts []EffectOpt, frame func(i int) (text string, done bool), last func() string) {
+	e := effect{interval: 50 * time.Millisecond}
+	for _, opt := range opts {
+		opt(&e)
+	}
+	if !animatable(w) {
+		_, _ = io.WriteString(w, withNewline(term.StripEscapes(text)))
+		return
+	}
+	if e.duration > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, e.duration)
+		defer cancel()
+	}
+
+	level := ColorLevel(w)
+	blk := NewRowsBlock()
+	blk.WithWriter(w)
+	draw := func(s string) {
+		if level > 0 {
+			s = DownsampleString(s, level)
+		}
+		blk.Update(withNewline(s))
+	}
+	blk.HideCursor()
+	defer blk.ShowCursor()
+
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+loop:
+	for i := 0; ; i++ {
+		s, done := frame(i)
+		if done {
+			break
+		}
+		draw(s)
+		select {
+		case <-ctx.Done():
+			break loop
+		case <-ticker.C:
+		}
+	}
+	draw(last())
+}
+
+// animatable reports whether the effects can be animated on w: a
+// colorful terminal, not in no-color mode.
+func animatable(w io.Writer) bool {
+	if o, ok := w.(*Output); ok {
+		return o.IsTty() && o.IsColorful()
+	}
+	return chk.IsTty(w) && chk.IsColorful(w) && !states.Env().IsNoColorMode()
+}
+
+func withNewline(s string) string {
+	if strings.HasSuffix(s, "\n") {
+		return s
+	}
+	return s + "\n"
+}
@@ -0,0 +1,92 @@
+package color_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/hedzr/is/states"
+	"github.com/hedzr/is/term/color"
+	"github.com/hedzr/is/term/vt"
+)
+
+func TestStaticEffects(t *testing.T) {
+	red, _ := color.ParseRGB("red")
+	blue, _ := color.ParseRGB("blue")
+	term := vt.New(10, 4)
+	_, _ = term.WriteString(color.HorizontalGradient("ab\nabc", red, blue) + "\n")
+	_, _ = term.WriteString(color.VerticalGradient("x\ny", red, blue))
+
+	// the columns get the same colors on all lines
+	term.ExpectRow(t, 0, "ab")
+	term.ExpectStyle(t, 0, "a", color.Cell{Fg: red})
+	term.ExpectStyle(t, 1, "a", color.Cell{Fg: red})
+	term.ExpectStyle(t, 1, "c", color.Cell{Fg: blue})
+	term.ExpectStyle(t, 2, "x", color.Cell{Fg: red})
+	term.ExpectStyle(t, 3, "y", color.Cell{Fg: blue})
+	if c := term.Cell(2, 0); c.Fg != nil {
+		t.Errorf("the lines end with a reset: %v", c.Fg)
+	}
+
+	term.Reset()
+	_, _ = term.WriteString(color.Rainbow("a b"))
+	term.ExpectStyle(t, 0, "a", color.Cell{Fg: color.NewColorHSL(0, 1, .5)})
+	term.ExpectStyle(t, 0, "b", color.Cell{Fg: color.NewColorHSL(240, 1, .5)})
+
+	if !states.Env().IsNoColorMode() {
+		states.Env().SetNoColorMode(true)
+		defer states.Env().SetNoColorMode(false)
+	}
+	for _, s := range []string{color.HorizontalGradient("ab", red, blue), color.VerticalGradient("ab", red), color.Rainbow("ab")} {
+		if s != "ab" {
+			t.Errorf("no-color mode: %q", s)
+		}
+	}
+}
+
+func TestAnimatedEffects(t *testing.T) {
+	withoutTerminfo(t)
+	red, _ := color.ParseRGB("red")
+	opts := []color.EffectOpt{color.WithEffectInterval(time.Millisecond), color.WithEffectDuration(20 * time.Millisecond)}
+	for _, c := range []struct {
+		name string
+		run  func(w color.Writer)
+		want color.Cell // the style of "a" at the end
+	}{
+		{"rainbow", func(w color.Writer) { color.AnimateRainbow(context.Background(), w, "a b\ncd", opts...) },
+			color.Cell{Fg: color.NewColorHSL(0, 1, .5)}},
+		{"pulse", func(w color.Writer) { color.AnimatePulse(context.Background(), w, "a b\ncd", red, opts...) },
+			color.Cell{Fg: red}},
+		{"typewriter", func(w color.Writer) {
+			color.AnimateTypewriter(context.Background(), w, "\x1b[1ma b\x1b[0m\ncd", color.WithEffectInterval(time.Millisecond))
+		}, color.Cell{Attrs: color.AttrBold}},
+	} {
+		term := vt.New(10, 4)
+		_, _ = term.WriteString("$ run\n")
+		out := color.NewOutput(term, color.WithOutputTty(true), color.WithOutputLevel(color.Level16m), color.WithOutputNoColor(false))
+		c.run(out)
+		if got, want := term.String(), "$ run\na b\ncd"; got != want {
+			t.Errorf("%s: got %q, want %q\n%s", c.name, got, want, term.Snapshot())
+		}
+		term.ExpectStyle(t, 1, "a", c.want)
+		term.ExpectCursor(t, 0, 3)
+		if !term.CursorVisible() {
+			t.Errorf("%s: the cursor is hidden", c.name)
+		}
+
+		// a plain output gets the text once
+		var buf bytes.Buffer
+		c.run(color.NewOutput(&buf))
+		if got, want := buf.String(), "a b\ncd\n"; got != want {
+			t.Errorf("%s, plain: got %q, want %q", c.name, got, want)
+		}
+	}
+
+	// the typewriter shows the whole text even if it's cancelled
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	term := vt.New(10, 2)
+	color.AnimateTypewriter(ctx, color.NewOutput(term, color.WithOutputTty(true), color.WithOutputLevel(color.Level256)), "hello")
+	term.ExpectRow(t, 0, "hello")
+}
@@ -0,0 +1,481 @@
+package color
+
+import (
+	"fmt"
+	"html"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Palette maps the 16 ANSI colors and the default colors to RGB
+// values, for the outputs other than a terminal, such as
+// [Spans.HTML] and [Spans.SVG].
+type Palette struct {
+	Foreground [3]byte
+	Background [3]byte
+	ANSI       [16][3]byte // black, red, ..., white, then the bright ones
+}
+
+// DefaultPalette is a dark palette, the colors of VS Code's
+// integrated terminal.
+var DefaultPalette = &Palette{
+	Foreground: [3]byte{0xcc, 0xcc, 0xcc},
+	Background: [3]byte{0x1e, 0x1e, 0x1e},
+	ANSI: [16][3]byte{
+		{0x00, 0x00, 0x00}, {0xcd, 0x31, 0x31}, {0x0d, 0xbc, 0x79}, {0xe5, 0xe5, 0x10},
+		{0x24, 0x72, 0xc8}, {0xbc, 0x3f, 0xbc}, {0x11, 0xa8, 0xcd}, {0xe5, 0xe5, 0xe5},
+		{0x66, 0x66, 0x66}, {0xf1, 0x4c, 0x4c}, {0x23, 0xd1, 0x8b}, {0xf5, 0xf5, 0x43},
+		{0x3b, 0x8e, 0xea}, {0xd6, 0x70, 0xd6}, {0x29, 0xb8, 0xdb}, {0xff, 0xff, 0xff},
+	},
+}
+
+// LightPalette is a light palette.
+var LightPalette = &Palette{
+	Foreground: [3]byte{0x33, 0x33, 0x33},
+	Background: [3]byte{0xff, 0xff, 0xff},
+	ANSI: [16][3]byte{
+		{0x00, 0x00, 0x00}, {0xcd, 0x31, 0x31}, {0x00, 0xbc, 0x00}, {0x94, 0x98, 0x00},
+		{0x04, 0x51, 0xa5}, {0xbc, 0x05, 0xbc}, {0x05, 0x98, 0xbc}, {0x55, 0x55, 0x55},
+		{0x66, 0x66, 0x66}, {0xcd, 0x31, 0x31}, {0x14, 0xce, 0x14}, {0xb5, 0xba, 0x00},
+		{0x04, 0x51, 0xa5}, {0xbc, 0x05, 0xbc}, {0x05, 0x98, 0xbc}, {0xa5, 0xa5, 0xa5},
+	},
+}
+
+// RGB returns the RGB value of a color, the 16 colors are looked
+// up in the palette, and the 256 colors beyond them are computed
+// from the standard 6x6x6 cube and the gray ramp. ok is false for
+// nil (the default color) and the non-color codes.
+func (p *Palette) RGB(c Color) (rgb [3]byte, ok bool) {
+	switch v := c.(type) {
+	case Color16:
+		if i := ansiIndex(v); i >= 0 {
+			return p.ANSI[i], true
+		}
+	case Color256:
+		return p.rgb256(v.clr[0]), true
+	case *Color256:
+		return p.rgb256(v.clr[0]), true
+	case Color16m:
+		return [3]byte{v.clr[0], v.clr[1], v.clr[2]}, true
+	case *Color16m:
+		return [3]byte{v.clr[0], v.clr[1], v.clr[2]}, true
+	}
+	return
+}
+
+func (p *Palette) rgb256(n byte) [3]byte {
+	switch {
+	case n < 16:
+		return p.ANSI[n]
+	case n < 232:
+		n -= 16
+		level := func(v byte) byte {
+			if v == 0 {
+				return 0
+			}
+			return 55 + v*40
+		}
+		return [3]byte{level(n / 36), level(n / 6 % 6), level(n % 6)}
+	}
+	g := 8 + (n-232)*10
+	return [3]byte{g, g, g}
+}
+
+// ansiIndex returns the index (0..15) of a 16-colors fg/bg code,
+// or -1.
+func ansiIndex(c Color16) int {
+	switch {
+	case c >= 30 && c <= 37:
+		return int(c - 30)
+	case c >= 40 && c <= 47:
+		return int(c - 40)
+	case c >= 90 && c <= 97:
+		return int(c - 90 + 8)
+	case c >= 100 && c <= 107:
+		return int(c - 100 + 8)
+	}
+	return -1
+}
+
+// CSS returns the style sheet of the classes used by [Spans.HTML]
+// with [WithExportCSSClasses].
+func (p *Palette) CSS() string {
+	var sb strings.Builder
+	_, _ = fmt.Fprintf(&sb, ".ansi{color:%s;background-color:%s}\n", hexRGB(p.Foreground), hexRGB(p.Background))
+	for i, rgb := range p.ANSI {
+		_, _ = fmt.Fprintf(&sb, ".ansi-fg-%d{color:%s}.ansi-bg-%d{background-color:%s}\n", i, hexRGB(rgb), i, hexRGB(rgb))
+	}
+	_, _ = sb.WriteString(".ansi-bold{font-weight:bold}.ansi-dim{opacity:.5}.ansi-italic{font-style:italic}\n")
+	_, _ = sb.WriteString(".ansi-underline{text-decoration:underline}.ansi-strike{text-decoration:line-through}\n")
+	_, _ = sb.WriteString(".ansi-underline.ansi-strike{text-decoration:underline line-through}\n")
+	_, _ = sb.WriteString(".ansi-hidden{visibility:hidden}.ansi-blink{animation:ansi-blink 1s steps(1) infinite}\n")
+	_, _ = sb.WriteString("@keyframes ansi-blink{50%{opacity:0}}\n")
+	return sb.String()
+}
+
+func hexRGB(rgb [3]byte) string {
+	return fmt.Sprintf("#%02x%02x%02x", rgb[0], rgb[1], rgb[2])
+}
+
+// ParseCPT translates the CPT markups (see [GetCPT]) into the
+// spans, an <a href> element is always a link.
+func ParseCPT(s string) Spans {
+	t := cpTranslator{forceLinks: true}
+	return ParseANSI(t.Translate(s, Reset))
+}
+
+// ExportOpt is a functional option for [Spans.HTML] and
+// [Spans.SVG].
+type ExportOpt func(e *exporter)
+
+// WithExportPalette sets the palette, default is [DefaultPalette].
+func WithExportPalette(p *Palette) ExportOpt {
+	return func(e *exporter) {
+		if p != nil {
+			e.palette = p
+		}
+	}
+}
+
+// WithExportCSSClasses uses the CSS classes (see [Palette.CSS])
+// for the 16 colors and attributes instead of the inline styles,
+// so that the colors can be changed by the style sheet.
+func WithExportCSSClasses(b bool) ExportOpt {
+	return func(e *exporter) {
+		e.classes = b
+	}
+}
+
+// WithExportFragment produces the <pre> element only, instead of
+// a standalone HTML document. It's ignored by [Spans.SVG].
+func WithExportFragment(b bool) ExportOpt {
+	return func(e *exporter) {
+		e.fragment = b
+	}
+}
+
+// WithExportTitle sets the title of the HTML document, or the
+// caption of the SVG window.
+func WithExportTitle(title string) ExportOpt {
+	return func(e *exporter) {
+		e.title = title
+	}
+}
+
+// WithExportFontSize sets the font size in pixels, default is 14.
+func WithExportFontSize(px int) ExportOpt {
+	return func(e *exporter) {
+		if px > 0 {
+			e.fontSize = px
+		}
+	}
+}
+
+type exporter struct {
+	palette  *Palette
+	classes  bool
+	fragment bool
+	title    string
+	fontSize int
+}
+
+func newExporter(opts []ExportOpt) *exporter {
+	e := &exporter{palette: DefaultPalette, fontSize: 14}
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e
+}
+
+// colors returns the effective colors of a style, the reverse
+// attribute applied. A color is a CSS value, or an index of the
+// 16 colors if the classes are used, or "" and -1 for the default
+// color.
+func (e *exporter) colors(st SpanStyle) (fg, bg string, fgIndex, bgIndex int) {
+	fgc, bgc := st.Fg, st.Bg
+	reverse := st.Attrs&AttrReverse != 0
+	if reverse {
+		fgc, bgc = bgc, fgc
+	}
+	fg, fgIndex = e.color(fgc)
+	bg, bgIndex = e.color(bgc)
+	if reverse {
+		if fg == "" && fgIndex < 0 {
+			fg = hexRGB(e.palette.Background)
+		}
+		if bg == "" && bgIndex < 0 {
+			bg = hexRGB(e.palette.Foreground)
+		}
+	}
+	return
+}
+
+func (e *exporter) color(c Color) (string, int) {
+	if c16, ok := c.(Color16); ok && e.classes {
+		if i := ansiIndex(c16); i >= 0 {
+			return "", i
+		}
+	}
+	if rgb, ok := e.palette.RGB(c); ok {
+		return hexRGB(rgb), -1
+	}
+	return "", -1
+}
+
+var underlineCSS = [...]string{"", "underline", "underline double", "underline wavy", "underline dotted", "underline dashed"}
+
+// HTML renders the spans as a standalone HTML document, or a
+// <pre> element with [WithExportFragment].
+//
+// The colors are looked up in the palette (see
+// [WithExportPalette]), the attributes are inline styles or CSS
+// classes (see [WithExportCSSClasses]), and the hyperlinks are
+// <a> elements.
+//
+//	doc := color.ParseCPT("<b>hello</b> <font color=green>world</font>").HTML()
+//	_ = os.WriteFile("help.html", []byte(doc), 0o644)
+func (spans Spans) HTML(opts ...ExportOpt) string {
+	e := newExporter(opts)
+	var sb strings.Builder
+	if !e.fragment {
+		_, _ = sb.WriteString("<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n")
+		if e.title != "" {
+			_, _ = sb.WriteString("<title>" + html.EscapeString(e.title) + "</title>\n")
+		}
+		_, _ = sb.WriteString("<style>\n")
+		if e.classes {
+			_, _ = sb.WriteString(e.palette.CSS())
+		}
+		_, _ = sb.WriteString("pre.ansi{padding:1em;font-family:ui-monospace,Menlo,Consolas,monospace;font-size:" +
+			strconv.Itoa(e.fontSize) + "px;line-height:1.3}\n")
+		_, _ = sb.WriteString("</style>\n</head>\n<body>\n")
+	}
+	if e.classes {
+		_, _ = sb.WriteString(`<pre class="ansi">`)
+	} else {
+		_, _ = fmt.Fprintf(&sb, `<pre class="ansi" style="color:%s;background-color:%s">`,
+			hexRGB(e.palette.Foreground), hexRGB(e.palette.Background))
+	}
+	for _, sp := range spans {
+		e.writeHTMLSpan(&sb, sp)
+	}
+	_, _ = sb.WriteString("</pre>")
+	if !e.fragment {
+		_, _ = sb.WriteString("\n</body>\n</html>")
+	}
+	_ = sb.WriteByte('\n')
+	return sb.String()
+}
+
+func (e *exporter) writeHTMLSpan(sb *strings.Builder, sp Span) {
+	text := html.EscapeString(sp.Text)
+	st := sp.Style
+	if st.Link != "" {
+		_, _ = sb.WriteString(`<a href="` + html.EscapeString(st.Link) + `">`)
+		defer sb.WriteString("</a>")
+	}
+
+	var classes, styles []string
+	fg, bg, fgIndex, bgIndex := e.colors(st)
+	if fgIndex >= 0 {
+		classes = append(classes, "ansi-fg-"+strconv.Itoa(fgIndex))
+	} else if fg != "" {
+		styles = append(styles, "color:"+fg)
+	}
+	if bgIndex >= 0 {
+		classes = append(classes, "ansi-bg-"+strconv.Itoa(bgIndex))
+	} else if bg != "" {
+		styles = append(styles, "background-color:"+bg)
+	}
+	attr := func(a Attr, class, style string) {
+		if st.Attrs&a == 0 {
+			return
+		}
+		if e.classes {
+			classes = append(classes, class)
+		} else {
+			styles = append(styles, style)
+		}
+	}
+	attr(AttrBold, "ansi-bold", "font-weight:bold")
+	attr(AttrDim, "ansi-dim", "opacity:.5")
+	attr(AttrItalic, "ansi-italic", "font-style:italic")
+	attr(AttrHidden, "ansi-hidden", "visibility:hidden")
+	attr(AttrBlink, "ansi-blink", "text-decoration:blink")
+	var decorations []string
+	if st.Underline > UnderlineSingle || (!e.classes && st.Attrs&AttrUnderline != 0) {
+		decorations = append(decorations, underlineCSS[max(st.Underline, UnderlineSingle)])
+	} else if st.Attrs&AttrUnderline != 0 {
+		classes = append(classes, "ansi-underline")
+	}
+	if st.Attrs&AttrOverline != 0 {
+		decorations = append(decorations, "overline")
+	}
+	if st.Attrs&AttrStrike != 0 {
+		if e.classes && len(decorations) == 0 {
+			classes = append(classes, "ansi-strike")
+		} else {
+			decorations = append(decorations, "line-through")
+		}
+	}
+	if len(decorations) > 0 {
+		styles = append(styles, "text-decoration:"+strings.Join(decorations, " "))
+	}
+	if rgb, ok := e.palette.RGB(st.UnderlineColor); ok {
+		styles = append(styles, "text-decoration-color:"+hexRGB(rgb))
+	}
+
+	if len(classes) == 0 && len(styles) == 0 {
+		_, _ = sb.WriteString(text)
+		return
+	}
+	_, _ = sb.WriteString("<span")
+	if len(classes) > 0 {
+		_, _ = sb.WriteString(` class="` + strings.Join(classes, " ") + `"`)
+	}
+	if len(styles) > 0 {
+		_, _ = sb.WriteString(` style="` + strings.Join(styles, ";") + `"`)
+	}
+	_, _ = sb.WriteString(">" + text + "</span>")
+}
+
+// SVG renders the spans as a "terminal screenshot", a window
+// with the text drawn in a monospace font. The columns are
+// measured by [StringWidth], so that the wide characters are
+// aligned, and the tabs are expanded to the 8-column stops.
+func (spans Spans) SVG(opts ...ExportOpt) string {
+	e := newExporter(opts)
+	e.classes = false // the colors are always the attributes
+	fs := float64(e.fontSize)
+	charW, lineH := fs*0.6, fs*1.4
+	pad := fs
+	top := pad
+	if e.title != "" {
+		top += lineH
+	}
+
+	lines := splitSpanLines(spans)
+	cols := 0
+	for _, line := range lines {
+		w := 0
+		for _, sp := range line {
+			w += StringWidth(sp.Text)
+		}
+		cols = max(cols, w)
+	}
+	width := float64(cols)*charW + 2*pad
+	height := float64(len(lines))*lineH + top + pad
+
+	num := func(f float64) string { return strconv.FormatFloat(math.Round(f*100)/100, 'f', -1, 64) }
+	var sb strings.Builder
+	_, _ = fmt.Fprintf(&sb, `<svg xmlns="http://www.w3.org/2000/svg" width="%s" height="%s" viewBox="0 0 %s %s">`+"\n",
+		num(width), num(height), num(width), num(height))
+	_, _ = fmt.Fprintf(&sb, `<rect width="100%%" height="100%%" rx="6" fill="%s"/>`+"\n", hexRGB(e.palette.Background))
+	if e.title != "" {
+		for i, clr := range []string{"#ff5f56", "#ffbd2e", "#27c93f"} {
+			_, _ = fmt.Fprintf(&sb, `<circle cx="%s" cy="%s" r="%s" fill="%s"/>`+"\n",
+				num(pad+float64(i)*fs), num(pad), num(fs*0.3), clr)
+		}
+		_, _ = fmt.Fprintf(&sb, `<text x="%s" y="%s" fill="%s" font-family="sans-serif" font-size="%s" text-anchor="middle">%s</text>`+"\n",
+			num(width/2), num(pad+fs*0.35), hexRGB(e.palette.Foreground), num(fs), html.EscapeString(e.title))
+	}
+	_, _ = fmt.Fprintf(&sb, `<g font-family="ui-monospace,Menlo,Consolas,monospace" font-size="%s" fill="%s">`+"\n",
+		num(fs), hexRGB(e.palette.Foreground))
+
+	for row, line := range lines {
+		y := top + float64(row)*lineH
+		col := 0
+		var text strings.Builder
+		for _, sp := range line {
+			w := StringWidth(sp.Text)
+			x := pad + float64(col)*charW
+			fg, bg, _, _ := e.colors(sp.Style)
+			if bg != "" {
+				_, _ = fmt.Fprintf(&sb, `<rect x="%s" y="%s" width="%s" height="%s" fill="%s"/>`+"\n",
+					num(x), num(y), num(float64(w)*charW), num(lineH), bg)
+			}
+			if strings.TrimSpace(sp.Text) != "" && sp.Style.Attrs&AttrHidden == 0 {
+				e.writeSVGSpan(&text, sp, x, fg)
+			}
+			col += w
+		}
+		if text.Len() > 0 {
+			_, _ = fmt.Fprintf(&sb, `<text y="%s" xml:space="preserve">%s</text>`+"\n",
+				num(y+lineH*0.75), text.String())
+		}
+	}
+	_, _ = sb.WriteString("</g>\n</svg>\n")
+	return sb.String()
+}
+
+func (e *exporter) writeSVGSpan(sb *strings.Builder, sp Span, x float64, fg string) {
+	st := sp.Style
+	if st.Link != "" {
+		_, _ = sb.WriteString(`<a href="` + html.EscapeString(st.Link) + `">`)
+		defer sb.WriteString("</a>")
+	}
+	_, _ = sb.WriteString(`<tspan x="` + strconv.FormatFloat(math.Round(x*100)/100, 'f', -1, 64) + `"`)
+	if fg != "" {
+		_, _ = sb.WriteString(` fill="` + fg + `"`)
+	}
+	if st.Attrs&AttrBold != 0 {
+		_, _ = sb.WriteString(` font-weight="bold"`)
+	}
+	if st.Attrs&AttrItalic != 0 {
+		_, _ = sb.WriteString(` font-style="italic"`)
+	}
+	if st.Attrs&AttrDim != 0 {
+		_, _ = sb.WriteString(` opacity="0.5"`)
+	}
+	var decorations []string
+	if st.Attrs&AttrUnderline != 0 {
+		decorations = append(decorations, "underline")
+	}
+	if st.Attrs&AttrStrike != 0 {
+		decorations = append(decorations, "line-through")
+	}
+	if len(decorations) > 0 {
+		_, _ = sb.WriteString(` text-decoration="` + strings.Join(decorations, " ") + `"`)
+	}
+	_, _ = sb.WriteString(">" + html.EscapeString(sp.Text) + "</tspan>")
+}
+
+// splitSpanLines splits the spans at the line breaks, and expands
+// the tabs.
+func splitSpanLines(spans Spans) (lines [][]Span) {
+	var line []Span
+	col := 0
+	for _, sp := range spans {
+		parts := strings.Split(strings.ReplaceAll(sp.Text, "\r\n", "\n"), "\n")
+		for i, part := range parts {
+			if i > 0 {
+				lines = append(lines, line)
+				line, col = nil, 0
+			}
+			if strings.Contains(part, "\t") {
+				var sb strings.Builder
+				for _, seg := range strings.SplitAfter(part, "\t") {
+					text := strings.TrimSuffix(seg, "\t")
+					_, _ = sb.WriteString(text)
+					col += StringWidth(text)
+					if len(text) < len(seg) {
+						n := 8 - col%8
+						_, _ = sb.WriteString(strings.Repeat(" ", n))
+						col += n
+					}
+				}
+				part = sb.String()
+			} else {
+				col += StringWidth(part)
+			}
+			if part != "" {
+				line = append(line, Span{Text: part, Style: sp.Style})
+			}
+		}
+	}
+	if len(line) > 0 || len(lines) == 0 {
+		lines = append(lines, line)
+	}
+	return
+}
@@ -0,0 +1,78 @@
+package color
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPaletteRGB(t *testing.T) {
+	p := DefaultPalette
+	tests := []struct {
+		c    Color
+		want [3]byte
+		ok   bool
+	}{
+		{FgRed, p.ANSI[1], true},
+		{BgLightBlue, p.ANSI[12], true},
+		{Color256{clr: [4]byte{3}}, p.ANSI[3], true},
+		{Color256{clr: [4]byte{196}}, [3]byte{255, 0, 0}, true},
+		{NewColor256(67, false), [3]byte{95, 135, 175}, true},
+		{Color256{clr: [4]byte{244}}, [3]byte{128, 128, 128}, true},
+		{Color16m{clr: [4]byte{1, 2, 3}}, [3]byte{1, 2, 3}, true},
+		{nil, [3]byte{}, false},
+		{BgBoldOrBright, [3]byte{}, false},
+	}
+	for i, tc := range tests {
+		if got, ok := p.RGB(tc.c); got != tc.want || ok != tc.ok {
+			t.Errorf("%d. RGB(%v) = %v, %v, want %v, %v", i, tc.c, got, ok, tc.want, tc.ok)
+		}
+	}
+}
+
+func TestSpansHTML(t *testing.T) {
+	tests := []struct {
+		in   string
+		opts []ExportOpt
+		want string
+	}{
+		{"a < b", nil, `">a &lt; b</pre>`},
+		{"\x1b[1;31mx\x1b[0m", nil, `<span style="color:#cd3131;font-weight:bold">x</span>`},
+		{"\x1b[1;31mx\x1b[0m", []ExportOpt{WithExportCSSClasses(true)}, `<span class="ansi-fg-1 ansi-bold">x</span>`},
+		{"\x1b[7mr\x1b[0m", nil, `<span style="color:#1e1e1e;background-color:#cccccc">r</span>`},
+		{"\x1b[4:3;58;5;196mc\x1b[0m", nil, `<span style="text-decoration:underline wavy;text-decoration-color:#ff0000">c</span>`},
+		{"\x1b]8;;https://e.com/?a&b\x1b\\l\x1b]8;;\x1b\\", nil, `<a href="https://e.com/?a&amp;b">l</a>`},
+		{"<b>bold</b>", []ExportOpt{WithExportPalette(LightPalette)}, `style="color:#333333;background-color:#ffffff"><span style="font-weight:bold">bold</span>`},
+	}
+	for i, tc := range tests {
+		spans := ParseANSI(tc.in)
+		if strings.HasPrefix(tc.in, "<") {
+			spans = ParseCPT(tc.in)
+		}
+		got := spans.HTML(append(tc.opts, WithExportFragment(true))...)
+		if !strings.Contains(got, tc.want) {
+			t.Errorf("%d. HTML(%q) =\n%s\nwant containing\n%s", i, tc.in, got, tc.want)
+		}
+	}
+
+	doc := ParseANSI("x").HTML(WithExportTitle("a & b"), WithExportCSSClasses(true))
+	for _, want := range []string{"<!DOCTYPE html>", "<title>a &amp; b</title>", ".ansi-fg-1{color:#cd3131}", "</html>"} {
+		if !strings.Contains(doc, want) {
+			t.Errorf("HTML document lacks %q:\n%s", want, doc)
+		}
+	}
+}
+
+func TestSpansSVG(t *testing.T) {
+	got := ParseANSI("中\x1b[32mok\x1b[0m\n\tx\x1b[44m \x1b[0m").SVG(WithExportTitle("demo"))
+	for _, want := range []string{
+		`<svg xmlns="http://www.w3.org/2000/svg" width="112" height="86.8"`,
+		`>demo</text>`,
+		`<tspan x="14">中</tspan><tspan x="30.8" fill="#0dbc79">ok</tspan>`,
+		`<tspan x="14">        x</tspan>`,
+		`<rect x="89.6" y="53.2" width="8.4" height="19.6" fill="#2472c8"/>`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("SVG lacks %q:\n%s", want, got)
+		}
+	}
+}
@@ -0,0 +1,436 @@
+package color
+
+import (
+	"bytes"
+	"os"
+	"strconv"
+	"sync"
+)
+
+// Attr is a set of text attributes of a [Cell].
+type Attr uint16
+
+const (
+	AttrBold Attr = 1 << iota
+	AttrDim
+	AttrItalic
+	AttrUnderline
+	AttrBlink
+	AttrReverse
+	AttrHidden
+	AttrStrike
+	AttrOverline
+	AttrFramed
+
+	AttrNone Attr = 0
+)
+
+// attrSGR maps an Attr bit to its SGR code, in bit order.
+var attrSGR = [...]int{1, 2, 3, 4, 5, 7, 8, 9, 53, 51}
+
+// Cell is a character cell of a [Grid].
+//
+// A nil (or NoColor) Fg/Bg means the default color of the
+// terminal. The zero Cell is a blank space in default colors.
+type Cell struct {
+	Ch    rune
+	Fg    Color
+	Bg    Color
+	Attrs Attr
+}
+
+// sameStyle reports whether two cells have the same colors
+// and attributes.
+func (c Cell) sameStyle(o Cell) bool {
+	return c.Attrs == o.Attrs && sameColor(c.Fg, o.Fg) && sameColor(c.Bg, o.Bg)
+}
+
+// downsample maps the colors for the color level, see [Downsample].
+func (c Cell) downsample(level int64) Cell {
+	c.Fg, c.Bg = Downsample(c.Fg, level), Downsample(c.Bg, level)
+	return c
+}
+
+func (c Cell) equal(o Cell) bool {
+	return c.Ch == o.Ch && c.sameStyle(o)
+}
+
+func (c Cell) char() rune {
+	if c.Ch == 0 {
+		return ' '
+	}
+	return c.Ch
+}
+
+// wideTail is the placeholder in the cell following a wide
+// character (such as CJK ideographs), it's never drawn.
+const wideTail rune = -1
+
+// Grid is a double-buffered cell grid.
+//
+// The drawing methods ([Grid.Set], [Grid.SetString], [Grid.Fill],
+// ...) update the back buffer only. [Grid.Flush] compares the
+// back buffer with the frame drawn last time, and emits the
+// changed runs only, with cursor movements (see [Cursor.MoveTo])
+// and the minimal SGR transitions. So a large grid can be
+// redrawn frequently without flickers, even over SSH.
+//
+// By default, a Grid is drawn inline, from the current line
+// downwards, like [RowsBlock]:
+//
+//	g := color.NewGrid(40, 3)
+//	for i := range 100 {
+//	  g.SetString(0, i%3, fmt.Sprintf("step %d", i), color.FgGreen, nil, color.AttrBold)
+//	  g.Flush()
+//	}
+//	g.Done()
+//
+// Use [Grid.SetOrigin] to place it at a fixed screen position,
+// for example, in a [Screen].
+type Grid struct {
+	mu         sync.Mutex
+	w          Writer
+	cols, rows int
+	back       []Cell // the drawing buffer
+	front      []Cell // the frame on the terminal
+	valid      bool   // front is drawn
+	absolute   bool
+	top, left  int // the 1-based screen position if absolute
+	curRow     int // the cursor row, relative to the grid
+	curCol     int // the cursor column, relative to the grid; -1 means unknown
+	buf        bytes.Buffer
+}
+
+// NewGrid returns a cols x rows grid, which will be drawn
+// to os.Stdout.
+func NewGrid(cols, rows int) *Grid {
+	g := &Grid{w: os.Stdout}
+	g.alloc(cols, rows)
+	return g
+}
+
+// WithWriter sets the output device.
+func (g *Grid) WithWriter(w Writer) *Grid {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.w = w
+	g.valid = false
+	return g
+}
+
+// SetOrigin places the grid at col, row (1-based) of the
+// screen, instead of the inline drawing. The whole grid will
+// be redrawn at next Flush.
+func (g *Grid) SetOrigin(col, row int) *Grid {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.absolute, g.top, g.left = true, max(row, 1), max(col, 1)
+	g.valid = false
+	return g
+}
+
+// Size returns the columns and rows of the grid.
+func (g *Grid) Size() (cols, rows int) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.cols, g.rows
+}
+
+// Resize changes the size of the grid, the content in the
+// remained area is kept. The whole grid will be redrawn at
+// next Flush.
+func (g *Grid) Resize(cols, rows int) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	old, oc, or := g.back, g.cols, g.rows
+	g.alloc(cols, rows)
+	for y := range min(or, g.rows) {
+		copy(g.back[y*g.cols:y*g.cols+min(oc, g.cols)], old[y*oc:])
+	}
+}
+
+func (g *Grid) alloc(cols, rows int) {
+	g.cols, g.rows = max(cols, 0), max(rows, 0)
+	g.back = make([]Cell, g.cols*g.rows)
+	g.front = make([]Cell, g.cols*g.rows)
+	g.valid = false
+}
+
+// Invalidate forces the whole grid to be redrawn at next Flush,
+// for instance, after the screen was cleared by others.
+func (g *Grid) Invalidate() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.valid = false
+}
+
+// Get returns the cell at col, row (0-based).
+func (g *Grid) Get(col, row int) (c Cell) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.in(col, row) {
+		c = g.back[row*g.cols+col]
+		if c.Ch == wideTail {
+			c.Ch = 0
+		}
+	}
+	return
+}
+
+// Set puts a cell at col, row (0-based). Out of range cells
+// are ignored.
+func (g *Grid) Set(col, row int, c Cell) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.set(col, row, c)
+}
+
+func (g *Grid) set(col, row int, c Cell) (width int) {
+	if !g.in(col, row) {
+		return 0
+	}
+	width = 1
+	if c.Ch != 0 {
+		width = runeWidth(c.Ch)
+		if width == 0 {
+			return // combining marks are not supported
+		}
+	}
+	if width == 2 && col+1 >= g.cols {
+		c.Ch, width = ' ', 1 // no room for a wide char
+	}
+
+	i := row*g.cols + col
+	// overwriting a half of a wide char clears the other half
+	if g.back[i].Ch == wideTail && col > 0 {
+		g.back[i-1].Ch = ' '
+	}
+	if col+width < g.cols && g.back[i+width].Ch == wideTail {
+		g.back[i+width].Ch = ' '
+	}
+	g.back[i] = c
+	if width == 2 {
+		g.back[i+1] = Cell{Ch: wideTail, Fg: c.Fg, Bg: c.Bg, Attrs: c.Attrs}
+	}
+	return
+}
+
+// SetString puts a string at col, row (0-based) with the given
+// colors and attributes, and returns the count of columns used.
+// The text is clipped at the right edge.
+func (g *Grid) SetString(col, row int, s string, fg, bg Color, attrs Attr) (width int) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	for _, r := range s {
+		if col >= g.cols {
+			break
+		}
+		w := g.set(col, row, Cell{Ch: r, Fg: fg, Bg: bg, Attrs: attrs})
+		col += w
+		width += w
+	}
+	return
+}
+
+// Fill sets all cells of the back buffer to c.
+func (g *Grid) Fill(c Cell) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if runeWidth(c.Ch) > 1 {
+		c.Ch = ' '
+	}
+	for i := range g.back {
+		g.back[i] = c
+	}
+}
+
+// Clear blanks the back buffer.
+func (g *Grid) Clear() { g.Fill(Cell{}) }
+
+func (g *Grid) in(col, row int) bool {
+	return col >= 0 && row >= 0 && col < g.cols && row < g.rows
+}
+
+// Flush draws the changes since the last Flush.
+func (g *Grid) Flush() (err error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.buf.Reset()
+	if !g.valid {
+		if g.absolute {
+			g.curCol = -1 // the cursor position is unknown
+		} else {
+			g.reserve()
+		}
+	}
+
+	var pen Cell
+	level := ColorLevel(g.w)
+	sgr := csiSeq('m', 0, 0, csi+"m") != "" // no SGR for a dumb terminal
+	for row := range g.rows {
+		base := row * g.cols
+		for col := 0; col < g.cols; {
+			if g.valid && g.back[base+col].equal(g.front[base+col]) {
+				col++
+				continue
+			}
+			// a changed run starts here
+			g.moveTo(row, col)
+			for col < g.cols {
+				i := base + col
+				c := g.back[i]
+				if g.valid && c.equal(g.front[i]) && !g.worthBridging(base, col) {
+					break
+				}
+				if c.Ch != wideTail {
+					if sgr && !c.sameStyle(pen) {
+						writeSGRTransition(&g.buf, pen.downsample(level), c.downsample(level))
+						pen = c
+					}
+					_, _ = g.buf.WriteRune(c.char())
+					g.curCol += max(runeWidth(c.char()), 1)
+				}
+				g.front[i] = c
+				col++
+			}
+		}
+	}
+	if !pen.sameStyle(Cell{}) {
+		_, _ = g.buf.WriteString(csi + "0m")
+	}
+	if !g.absolute {
+		g.moveTo(g.rows-1, 0) // park at the start of the last line
+	}
+	g.valid = true
+
+	if g.buf.Len() > 0 {
+		_, err = g.w.Write(g.buf.Bytes())
+	}
+	return
+}
+
+// Done moves the cursor below an inline grid, so that the
+// following output won't overwrite it.
+func (g *Grid) Done() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if !g.absolute && g.valid {
+		_, _ = g.w.Write([]byte("\r\n"))
+		g.valid = false
+	}
+}
+
+// worthBridging reports whether rewriting a short unchanged gap
+// is cheaper than a cursor movement over it.
+func (g *Grid) worthBridging(base, col int) bool {
+	const maxGap = 4
+	for j := col; j < g.cols && j < col+maxGap; j++ {
+		if !g.back[base+j].equal(g.front[base+j]) {
+			// keep the same style in the gap, or it's not cheap
+			return g.back[base+col].sameStyle(g.back[base+j])
+		}
+	}
+	return false
+}
+
+// reserve prepares the lines for an inline grid at its first
+// drawing, the cursor is left at the beginning of the top row.
+func (g *Grid) reserve() {
+	if g.rows > 1 {
+		for range g.rows - 1 {
+			_ = g.buf.WriteByte('\n')
+		}
+		_, _ = g.buf.WriteString(csiSeq('A', g.rows-1, 0, csi+strconv.Itoa(g.rows-1)+"A"))
+	}
+	_ = g.buf.WriteByte('\r')
+	g.curRow, g.curCol = 0, 0
+}
+
+func (g *Grid) moveTo(row, col int) {
+	if g.curRow == row && g.curCol == col && col < g.cols {
+		return
+	}
+	if g.absolute {
+		r, c := g.top+row, g.left+col
+		_, _ = g.buf.WriteString(csiSeq('H', r, c, ecmaSeq('H', r, c)))
+	} else {
+		if d := row - g.curRow; d < 0 {
+			_, _ = g.buf.WriteString(csiSeq('A', -d, 0, ecmaSeq('A', -d, 0)))
+		} else if d > 0 {
+			_, _ = g.buf.WriteString(csiSeq('B', d, 0, ecmaSeq('B', d, 0)))
+		}
+		_, _ = g.buf.WriteString(csiSeq('G', col+1, 0, ecmaSeq('G', col+1, 0)))
+	}
+	g.curRow, g.curCol = row, col
+}
+
+// writeSGRTransition writes the SGR sequence which changes the
+// pen from cell from to cell to.
+func writeSGRTransition(buf *bytes.Buffer, from, to Cell) {
+	var codes []string
+	if from.Attrs&^to.Attrs != 0 {
+		// some attributes have to be turned off, restart from scratch
+		codes = append(codes, "0")
+		from = Cell{}
+	}
+	for i, code := range attrSGR {
+		if bit := Attr(1 << i); to.Attrs&bit != 0 && from.Attrs&bit == 0 {
+			codes = append(codes, strconv.Itoa(code))
+		}
+	}
+	if len(codes) > 0 {
+		_, _ = buf.WriteString(csi)
+		for i, c := range codes {
+			if i > 0 {
+				_ = buf.WriteByte(';')
+			}
+			_, _ = buf.WriteString(c)
+		}
+		_ = buf.WriteByte('m')
+	}
+	if !sameColor(from.Fg, to.Fg) {
+		if isDefaultColor(to.Fg) {
+			_, _ = buf.WriteString(FgDefault.Color())
+		} else {
+			to.Fg.ColorTo(buf)
+		}
+	}
+	if !sameColor(from.Bg, to.Bg) {
+		if isDefaultColor(to.Bg) {
+			_, _ = buf.WriteString(BgDefault.Color())
+		} else {
+			to.Bg.ColorTo(buf)
+		}
+	}
+}
+
+func isDefaultColor(c Color) bool {
+	return c == nil || c == Color(NoColor)
+}
+
+// sameColor compares two colors safely, a Style holding a slice
+// cannot be compared by ==.
+func sameColor(a, b Color) bool {
+	if isDefaultColor(a) || isDefaultColor(b) {
+		return isDefaultColor(a) && isDefaultColor(b)
+	}
+	switch x := a.(type) {
+	case Color16:
+		y, ok := b.(Color16)
+		return ok && x == y
+	case Color256:
+		y, ok := b.(Color256)
+		return ok && x == y
+	case Color16m:
+		y, ok := b.(Color16m)
+		return ok && x == y
+	case *Color256:
+		y, ok := b.(*Color256)
+		return ok && (x == y || *x == *y)
+	case *Color16m:
+		y, ok := b.(*Color16m)
+		return ok && (x == y || *x == *y)
+	}
+	return a.Color() == b.Color()
+}
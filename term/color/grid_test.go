@@ -0,0 +1,88 @@
+package color
+
+import (
+	"os"
+	"testing"
+)
+
+func gridOutput(t *testing.T, g *Grid, f *os.File) string {
+	t.Helper()
+	if err := g.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	off, _ := f.Seek(0, 1)
+	data, _ := os.ReadFile(f.Name())
+	_ = f.Truncate(0)
+	_, _ = f.Seek(0, 0)
+	return string(data[:off])
+}
+
+func TestGridFlush(t *testing.T) {
+	withTerminfo(t, "")
+
+	f, err := os.CreateTemp(t.TempDir(), "grid")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	g := NewGrid(6, 2).WithWriter(f).SetOrigin(5, 3)
+	g.SetString(0, 0, "ab", FgRed, nil, AttrBold)
+	g.SetString(0, 1, "中x", nil, nil, AttrNone)
+
+	got := gridOutput(t, g, f)
+	want := "\x1b[3;5H\x1b[1m\x1b[31mab\x1b[0m    \x1b[4;5H中x   "
+	if got != want {
+		t.Errorf("first frame:\n got %q\nwant %q", got, want)
+	}
+
+	// nothing changed, nothing emitted
+	if got = gridOutput(t, g, f); got != "" {
+		t.Errorf("unchanged frame: got %q", got)
+	}
+
+	// only the changed cell is emitted
+	g.Set(1, 0, Cell{Ch: 'c', Fg: FgRed, Attrs: AttrBold})
+	got = gridOutput(t, g, f)
+	want = "\x1b[3;6H\x1b[1m\x1b[31mc\x1b[0m"
+	if got != want {
+		t.Errorf("diff frame:\n got %q\nwant %q", got, want)
+	}
+
+	// overwriting half of a wide char
+	g.Set(3, 1, Cell{Ch: 'y'})
+	g.Set(1, 1, Cell{Ch: 'z'})
+	got = gridOutput(t, g, f)
+	want = "\x1b[4;5H zxy" // the unchanged 'x' is cheaper than a cursor movement
+	if got != want {
+		t.Errorf("wide char frame:\n got %q\nwant %q", got, want)
+	}
+	if c := g.Get(0, 1); c.Ch != ' ' {
+		t.Errorf("want the wide char cleared, got %q", c.Ch)
+	}
+}
+
+func TestGridInline(t *testing.T) {
+	withTerminfo(t, "")
+
+	f, err := os.CreateTemp(t.TempDir(), "grid")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	g := NewGrid(3, 3).WithWriter(f)
+	g.SetString(0, 0, "abc", nil, nil, AttrNone)
+	got := gridOutput(t, g, f)
+	want := "\n\n\x1b[2A\rabc\x1b[1B\x1b[1G   \x1b[1B\x1b[1G   \x1b[1G"
+	if got != want {
+		t.Errorf("first frame:\n got %q\nwant %q", got, want)
+	}
+
+	g.Set(2, 0, Cell{Ch: 'x', Bg: NewColor256(22, true)})
+	got = gridOutput(t, g, f)
+	want = "\x1b[2A\x1b[3G\x1b[48;5;22mx\x1b[0m\x1b[2B\x1b[1G"
+	if got != want {
+		t.Errorf("diff frame:\n got %q\nwant %q", got, want)
+	}
+}
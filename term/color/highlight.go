@@ -0,0 +1,164 @@
+package color
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/hedzr/is/states"
+)
+
+// TokenKind is the kind of a [Token] of the source code.
+type TokenKind int
+
+const (
+	TokenText     TokenKind = iota // spaces, identifiers, ...
+	TokenKeyword                   // if, for, func, ...
+	TokenBuiltin                   // the predeclared types and functions, the shell builtins
+	TokenLiteral                   // true, false, nil, null, ...
+	TokenString                    //
+	TokenNumber                    //
+	TokenComment                   //
+	TokenOperator                  // the operators and punctuations
+	TokenKey                       // the keys of JSON, YAML and TOML
+	TokenVariable                  // the shell variables, $x and ${x}
+	TokenMeta                      // the diff hunk headers, the YAML tags and anchors
+	TokenSection                   // the TOML tables, the diff file headers, ...
+	TokenInserted                  // the added lines of diff
+	TokenDeleted                   // the removed lines of diff
+)
+
+// Token is a piece of the source code.
+type Token struct {
+	Kind TokenKind
+	Text string
+}
+
+// tokenRoles are the theme roles of the token kinds, and their
+// fallbacks if a theme doesn't define the role.
+var tokenRoles = [...]struct{ role, fallback Role }{
+	TokenText:     {},
+	TokenKeyword:  {"keyword", RoleAccent},
+	TokenBuiltin:  {"builtin", RoleInfo},
+	TokenLiteral:  {"literal", RoleWarning},
+	TokenString:   {"string", RoleSuccess},
+	TokenNumber:   {"number", RoleWarning},
+	TokenComment:  {"comment", RoleMuted},
+	TokenOperator: {"operator", ""},
+	TokenKey:      {"key", RoleInfo},
+	TokenVariable: {"variable", RoleWarning},
+	TokenMeta:     {"meta", RoleInfo},
+	TokenSection:  {"section", RoleHeading},
+	TokenInserted: {"inserted", RoleSuccess},
+	TokenDeleted:  {"deleted", RoleError},
+}
+
+// String returns the role name of the kind, such as "keyword".
+func (k TokenKind) String() string {
+	if k > TokenText && int(k) < len(tokenRoles) {
+		return string(tokenRoles[k].role)
+	}
+	return "text"
+}
+
+// tokenColor returns the color of a token kind in t, or nil.
+func (t *Theme) tokenColor(k TokenKind) Color {
+	if k <= TokenText || int(k) >= len(tokenRoles) {
+		return nil
+	}
+	r := tokenRoles[k]
+	if c := t.Color(r.role); c != nil {
+		return c
+	}
+	if r.fallback == "" {
+		return nil
+	}
+	return t.Color(r.fallback)
+}
+
+// Lexer splits the source code into the tokens, the texts of the
+// tokens make up the code.
+type Lexer func(code string) []Token
+
+var (
+	lexersMu sync.RWMutex
+	lexers   = map[string]Lexer{
+		"go": lexGo, "golang": lexGo,
+		"sh": lexShell, "bash": lexShell, "shell": lexShell, "zsh": lexShell, "console": lexShell,
+		"json": lexJSON,
+		"yaml": lexYAML, "yml": lexYAML,
+		"toml": lexTOML,
+		"diff": lexDiff, "patch": lexDiff,
+	}
+)
+
+// RegisterLexer adds (or replaces) the lexer of the languages.
+func RegisterLexer(lexer Lexer, langs ...string) {
+	lexersMu.Lock()
+	defer lexersMu.Unlock()
+	for _, lang := range langs {
+		lexers[strings.ToLower(lang)] = lexer
+	}
+}
+
+func lexerOf(lang string) Lexer {
+	lexersMu.RLock()
+	defer lexersMu.RUnlock()
+	return lexers[strings.ToLower(strings.TrimSpace(lang))]
+}
+
+// Tokenize splits code in lang into the tokens, or returns nil if
+// the language is unknown. The built-in languages are Go, shell,
+// JSON, YAML, TOML and diff, see [RegisterLexer] for the others.
+func Tokenize(code, lang string) []Token {
+	if lex := lexerOf(lang); lex != nil {
+		return lex(code)
+	}
+	return nil
+}
+
+// HighlightCode returns code in lang highlighted by the current theme
+// (see [CurrentTheme]). A theme can define the roles of the token
+// kinds, such as "keyword" and "string", or the common roles are
+// used.
+//
+// The code of an unknown language is dim, and code is returned
+// as is in no-color mode. It can be used in the CPT markups too:
+//
+//	color.GetCPT().Translate(`<code lang="go">func main() {}</code>`, color.Reset)
+func HighlightCode(code, lang string) string {
+	if states.Env().IsNoColorMode() {
+		return code
+	}
+	return highlight(code, lang)
+}
+
+func highlight(code, lang string) string {
+	var sb strings.Builder
+	toks := Tokenize(code, lang)
+	if toks == nil {
+		writeStyled(&sb, BgDim, code)
+		return sb.String()
+	}
+	theme := CurrentTheme()
+	for _, tok := range toks {
+		writeStyled(&sb, theme.tokenColor(tok.Kind), tok.Text)
+	}
+	return sb.String()
+}
+
+// writeStyled writes text in clr line by line, so that each line
+// can be drawn alone.
+func writeStyled(sb *strings.Builder, clr Color, text string) {
+	if clr == nil {
+		_, _ = sb.WriteString(text)
+		return
+	}
+	for i, line := range strings.Split(text, "\n") {
+		if i > 0 {
+			_ = sb.WriteByte('\n')
+		}
+		if line != "" {
+			_, _ = sb.WriteString(sgrOf(clr) + line + ResetToNormalColor.Color())
+		}
+	}
+}
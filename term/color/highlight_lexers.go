@@ -0,0 +1,445 @@
+package color
+
+import (
+	"regexp"
+	"strings"
+)
+
+// lexer collects the tokens of src, the adjacent ones of the same
+// kind are merged.
+type lexer struct {
+	src  string
+	pos  int
+	toks []Token
+}
+
+// emit makes src[pos:end] a token of kind.
+func (l *lexer) emit(kind TokenKind, end int) {
+	end = min(end, len(l.src))
+	if end <= l.pos {
+		return
+	}
+	if n := len(l.toks); n > 0 && l.toks[n-1].Kind == kind {
+		l.toks[n-1].Text += l.src[l.pos:end]
+	} else {
+		l.toks = append(l.toks, Token{Kind: kind, Text: l.src[l.pos:end]})
+	}
+	l.pos = end
+}
+
+// lineEnd returns the position of the line break after i.
+func lineEnd(s string, i int) int {
+	if j := strings.IndexByte(s[i:], '\n'); j >= 0 {
+		return i + j
+	}
+	return len(s)
+}
+
+// blockEnd returns the position after the closing delim searched
+// from i, or len(s) if it's unterminated.
+func blockEnd(s string, i int, delim string) int {
+	if j := strings.Index(s[min(i, len(s)):], delim); j >= 0 {
+		return i + j + len(delim)
+	}
+	return len(s)
+}
+
+// quotedEnd returns the position after the string quoted by q at
+// i. The backslash escapes a character if escapes, and a string
+// ends at the line break unless multiline.
+func quotedEnd(s string, i int, escapes, multiline bool) int {
+	q := s[i]
+	for j := i + 1; j < len(s); j++ {
+		switch c := s[j]; {
+		case c == '\\' && escapes:
+			j++
+		case c == q:
+			return j + 1
+		case c == '\n' && !multiline:
+			return j
+		}
+	}
+	return len(s)
+}
+
+// numberEnd returns the end of the number at i, such as 0x1F,
+// 1_000, 1.5e-3 and 2i.
+func numberEnd(s string, i int) int {
+	j := i
+	for j < len(s) {
+		switch c := s[j]; {
+		case isWordByte(c) || c == '.':
+		case (c == '+' || c == '-') && j > i && s[j-1]|0x20 == 'e' && !strings.HasPrefix(s[i:], "0x"):
+		default:
+			return j
+		}
+		j++
+	}
+	return j
+}
+
+// wordEnd returns the end of the identifier at i.
+func wordEnd(s string, i int) int {
+	j := i
+	for j < len(s) && isWordByte(s[j]) {
+		j++
+	}
+	return j
+}
+
+// isWordByte reports whether c can be in an identifier, the bytes
+// of the non-ASCII characters are treated as the letters.
+func isWordByte(c byte) bool {
+	return c == '_' || c >= '0' && c <= '9' || c|0x20 >= 'a' && c|0x20 <= 'z' || c >= 0x80
+}
+
+func isDigitByte(c byte) bool { return c >= '0' && c <= '9' }
+
+// nextNonSpace returns the byte after the spaces from i, or 0.
+func nextNonSpace(s string, i int) byte {
+	for ; i < len(s); i++ {
+		if c := s[i]; c != ' ' && c != '\t' {
+			return c
+		}
+	}
+	return 0
+}
+
+func kindsOf(kind TokenKind, words string) map[string]TokenKind {
+	m := make(map[string]TokenKind)
+	for _, w := range strings.Fields(words) {
+		m[w] = kind
+	}
+	return m
+}
+
+func mergeKinds(ms ...map[string]TokenKind) map[string]TokenKind {
+	ret := make(map[string]TokenKind)
+	for _, m := range ms {
+		for k, v := range m {
+			ret[k] = v
+		}
+	}
+	return ret
+}
+
+var goWords = mergeKinds(
+	kindsOf(TokenKeyword, `break case chan const continue default defer else fallthrough
+		for func go goto if import interface map package range return select struct switch type var`),
+	kindsOf(TokenBuiltin, `any bool byte comparable complex64 complex128 error float32 float64
+		int int8 int16 int32 int64 rune string uint uint8 uint16 uint32 uint64 uintptr
+		append cap clear close complex copy delete imag len make max min new panic print println real recover`),
+	kindsOf(TokenLiteral, `true false nil iota`),
+)
+
+const goOperators = "+-*/%&|^<>=!:;,.()[]{}~"
+
+func lexGo(code string) []Token {
+	l := &lexer{src: code}
+	for l.pos < len(code) {
+		i, c := l.pos, code[l.pos]
+		switch {
+		case strings.HasPrefix(code[i:], "//"):
+			l.emit(TokenComment, lineEnd(code, i))
+		case strings.HasPrefix(code[i:], "/*"):
+			l.emit(TokenComment, blockEnd(code, i+2, "*/"))
+		case c == '"' || c == '\'':
+			l.emit(TokenString, quotedEnd(code, i, true, false))
+		case c == '`':
+			l.emit(TokenString, blockEnd(code, i+1, "`"))
+		case isDigitByte(c) || c == '.' && i+1 < len(code) && isDigitByte(code[i+1]):
+			l.emit(TokenNumber, numberEnd(code, i))
+		case isWordByte(c):
+			j := wordEnd(code, i)
+			l.emit(goWords[code[i:j]], j)
+		case strings.IndexByte(goOperators, c) >= 0:
+			l.emit(TokenOperator, i+1)
+		default:
+			l.emit(TokenText, i+1)
+		}
+	}
+	return l.toks
+}
+
+var shellWords = mergeKinds(
+	kindsOf(TokenKeyword, `if then else elif fi for while until do done case esac function in select
+		return break continue local export readonly declare typeset`),
+	kindsOf(TokenBuiltin, `alias bg cd command echo eval exec exit fg getopts hash jobs kill printf
+		pwd read set shift source test trap type ulimit umask unalias unset wait`),
+	kindsOf(TokenLiteral, `true false`),
+)
+
+const shellOperators = "|&;<>()[]{}=!"
+
+func lexShell(code string) []Token {
+	l := &lexer{src: code}
+	for l.pos < len(code) {
+		i, c := l.pos, code[l.pos]
+		switch {
+		case c == '#' && (i == 0 || strings.IndexByte(" \t\n;", code[i-1]) >= 0):
+			l.emit(TokenComment, lineEnd(code, i))
+		case c == '\'':
+			l.emit(TokenString, quotedEnd(code, i, false, true))
+		case c == '"':
+			l.emit(TokenString, quotedEnd(code, i, true, true))
+		case c == '\\':
+			l.emit(TokenText, i+2)
+		case c == '$' && i+1 < len(code):
+			switch n := code[i+1]; {
+			case n == '{':
+				l.emit(TokenVariable, blockEnd(code, i+2, "}"))
+			case n == '_' || n|0x20 >= 'a' && n|0x20 <= 'z':
+				l.emit(TokenVariable, wordEnd(code, i+1))
+			case isDigitByte(n) || strings.IndexByte("@#?$!*-", n) >= 0:
+				l.emit(TokenVariable, i+2)
+			default:
+				l.emit(TokenOperator, i+1)
+			}
+		case isWordByte(c):
+			j := wordEnd(code, i)
+			for j < len(code) && (code[j] == '-' || code[j] == '.' || code[j] == '/' || isWordByte(code[j])) {
+				j++
+			}
+			word, prev := code[i:j], byte(' ')
+			if i > 0 {
+				prev = code[i-1]
+			}
+			switch {
+			case prev == '-' || prev == '/' || prev == '.':
+				l.emit(TokenText, j)
+			case isNumber(word):
+				l.emit(TokenNumber, j)
+			default:
+				l.emit(shellWords[word], j)
+			}
+		case strings.IndexByte(shellOperators, c) >= 0:
+			l.emit(TokenOperator, i+1)
+		default:
+			l.emit(TokenText, i+1)
+		}
+	}
+	return l.toks
+}
+
+var reNumber = regexp.MustCompile(`^[-+]?(?:0[xX][0-9a-fA-F_]+|0[oO][0-7_]+|0[bB][01_]+|\d[\d_]*(?:\.\d[\d_]*)?(?:[eE][-+]?\d+)?|\.\d+(?:[eE][-+]?\d+)?|\.?inf|\.?nan|\.?Inf|\.?NaN)$`)
+
+func isNumber(s string) bool { return reNumber.MatchString(s) }
+
+func lexJSON(code string) []Token {
+	l := &lexer{src: code}
+	for l.pos < len(code) {
+		i, c := l.pos, code[l.pos]
+		switch {
+		case c == '"':
+			j := quotedEnd(code, i, true, false)
+			if nextNonSpace(code, j) == ':' {
+				l.emit(TokenKey, j)
+			} else {
+				l.emit(TokenString, j)
+			}
+		case c == '-' || isDigitByte(c):
+			l.emit(TokenNumber, numberEnd(code, i+1))
+		case isWordByte(c):
+			j := wordEnd(code, i)
+			switch code[i:j] {
+			case "true", "false", "null":
+				l.emit(TokenLiteral, j)
+			default:
+				l.emit(TokenText, j)
+			}
+		case strings.IndexByte("{}[],:", c) >= 0:
+			l.emit(TokenOperator, i+1)
+		case c == '/' && i+1 < len(code) && (code[i+1] == '/' || code[i+1] == '*'): // JSONC
+			if code[i+1] == '/' {
+				l.emit(TokenComment, lineEnd(code, i))
+			} else {
+				l.emit(TokenComment, blockEnd(code, i+2, "*/"))
+			}
+		default:
+			l.emit(TokenText, i+1)
+		}
+	}
+	return l.toks
+}
+
+var (
+	reYAMLKey     = regexp.MustCompile(`^(?:"(?:[^"\\]|\\.)*"|'[^']*'|[^\s#'"{\[\]},&*!|>%@` + "`" + `-][^#:\n]*?|-[^\s#:][^#:\n]*?)[ \t]*:(?:[ \t]|$)`)
+	reYAMLLiteral = regexp.MustCompile(`^(?:~|null|Null|NULL|true|True|TRUE|false|False|FALSE|yes|Yes|YES|no|No|NO|on|On|ON|off|Off|OFF)$`)
+)
+
+func lexYAML(code string) []Token {
+	l := &lexer{src: code}
+	block := -1 // the indent of the line with a block scalar indicator
+	for l.pos < len(code) {
+		start, end := l.pos, lineEnd(code, l.pos)
+		line := code[start:end]
+		indent := len(line) - len(strings.TrimLeft(line, " "))
+		switch {
+		case block >= 0 && (strings.TrimSpace(line) == "" || indent > block):
+			l.emit(TokenText, start+indent)
+			l.emit(TokenString, end)
+		case strings.HasPrefix(line, "---") || strings.HasPrefix(line, "..."):
+			block = -1
+			l.emit(TokenSection, start+3)
+			l.yamlValue(end)
+		default:
+			block = -1
+			l.emit(TokenText, start+indent)
+			for strings.HasPrefix(code[l.pos:end], "- ") || code[l.pos:end] == "-" {
+				l.emit(TokenOperator, l.pos+1)
+				l.emit(TokenText, l.pos+len(code[l.pos:end])-len(strings.TrimLeft(code[l.pos:end], " ")))
+			}
+			if m := reYAMLKey.FindString(code[l.pos:end]); m != "" && code[l.pos] != '#' {
+				k := strings.LastIndexByte(m, ':')
+				l.emit(TokenKey, l.pos+len(strings.TrimRight(m[:k], " \t")))
+				l.emit(TokenText, l.pos+len(m[:k])-len(strings.TrimRight(m[:k], " \t")))
+				l.emit(TokenOperator, l.pos+1)
+			}
+			if l.yamlValue(end) {
+				block = indent
+			}
+		}
+		l.emit(TokenText, end+1)
+	}
+	return l.toks
+}
+
+// yamlValue lexes the value till end, and reports whether it's a
+// block scalar indicator (| or >).
+func (l *lexer) yamlValue(end int) (blockScalar bool) {
+	code := l.src
+	for l.pos < end {
+		i, c := l.pos, code[l.pos]
+		switch {
+		case c == ' ' || c == '\t':
+			l.emit(TokenText, i+1)
+		case c == '#' && (i == 0 || code[i-1] == ' ' || code[i-1] == '\t' || code[i-1] == '\n'):
+			l.emit(TokenComment, end)
+		case c == '"':
+			l.emit(TokenString, min(quotedEnd(code, i, true, false), end))
+		case c == '\'':
+			l.emit(TokenString, min(quotedEnd(code, i, false, false), end))
+		case (c == '&' || c == '*' || c == '!') && i+1 < end && code[i+1] != ' ':
+			j := i + 1
+			for j < end && strings.IndexByte(" \t,[]{}", code[j]) < 0 {
+				j++
+			}
+			l.emit(TokenMeta, j)
+		case (c == '|' || c == '>') && strings.TrimLeft(code[i+1:end], "+-0123456789 \t") == "" ||
+			(c == '|' || c == '>') && strings.HasPrefix(strings.TrimLeft(code[i+1:end], "+-0123456789 \t"), "#"):
+			l.emit(TokenOperator, i+1)
+			blockScalar = true
+		case strings.IndexByte("[]{},:", c) >= 0:
+			l.emit(TokenOperator, i+1)
+		default:
+			j := i + 1
+			for j < end && strings.IndexByte(",[]{}", code[j]) < 0 && !(code[j] == ' ' && j+1 < end && code[j+1] == '#') {
+				j++
+			}
+			word := strings.TrimRight(code[i:j], " \t")
+			switch {
+			case isNumber(word):
+				l.emit(TokenNumber, i+len(word))
+			case reYAMLLiteral.MatchString(word):
+				l.emit(TokenLiteral, i+len(word))
+			default:
+				l.emit(TokenString, i+len(word))
+			}
+		}
+	}
+	return
+}
+
+var reTOMLDate = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}(?:[T ]\d{2}:\d{2}:\d{2}(?:\.\d+)?(?:Z|[-+]\d{2}:\d{2})?)?|^\d{2}:\d{2}:\d{2}(?:\.\d+)?`)
+
+func lexTOML(code string) []Token {
+	l := &lexer{src: code}
+	depth, lineStart := 0, true
+	for l.pos < len(code) {
+		i, c := l.pos, code[l.pos]
+		switch {
+		case c == '\n':
+			l.emit(TokenText, i+1)
+			lineStart = true
+			continue
+		case c == ' ' || c == '\t' || c == '\r':
+			l.emit(TokenText, i+1)
+			continue
+		case c == '[' && lineStart && depth == 0:
+			j := lineEnd(code, i)
+			if k := strings.IndexByte(code[i:j], ']'); k >= 0 {
+				j = i + k + 1
+				if j < len(code) && code[j] == ']' {
+					j++
+				}
+			}
+			l.emit(TokenSection, j)
+		case c == '#':
+			l.emit(TokenComment, lineEnd(code, i))
+		case strings.HasPrefix(code[i:], `"""`) || strings.HasPrefix(code[i:], `'''`):
+			l.emit(TokenString, blockEnd(code, i+3, code[i:i+3]))
+		case c == '"' || c == '\'':
+			j := quotedEnd(code, i, c == '"', false)
+			if nextNonSpace(code, j) == '=' || nextNonSpace(code, j) == '.' {
+				l.emit(TokenKey, j)
+			} else {
+				l.emit(TokenString, j)
+			}
+		case c == '[' || c == '{':
+			depth++
+			l.emit(TokenOperator, i+1)
+		case c == ']' || c == '}':
+			depth = max(depth-1, 0)
+			l.emit(TokenOperator, i+1)
+		case strings.IndexByte("=,.", c) >= 0:
+			l.emit(TokenOperator, i+1)
+		default:
+			j := i
+			for j < len(code) && (isWordByte(code[j]) || code[j] == '-' || code[j] == '+' || code[j] == '.' || code[j] == ':') {
+				j++
+			}
+			j = max(j, i+1)
+			word := code[i:j]
+			switch {
+			case reTOMLDate.MatchString(word):
+				l.emit(TokenNumber, i+len(reTOMLDate.FindString(word)))
+			case isNumber(word):
+				l.emit(TokenNumber, j)
+			case word == "true" || word == "false":
+				l.emit(TokenLiteral, j)
+			default:
+				// a bare key, which may be dotted
+				j = i
+				for j < len(code) && (isWordByte(code[j]) || code[j] == '-') {
+					j++
+				}
+				l.emit(TokenKey, max(j, i+1))
+			}
+		}
+		lineStart = false
+	}
+	return l.toks
+}
+
+func lexDiff(code string) []Token {
+	l := &lexer{src: code}
+	for l.pos < len(code) {
+		start, end := l.pos, lineEnd(code, l.pos)
+		line := code[start:end]
+		switch {
+		case strings.HasPrefix(line, "+++ "), strings.HasPrefix(line, "--- "),
+			strings.HasPrefix(line, "diff "), strings.HasPrefix(line, "index "):
+			l.emit(TokenSection, end)
+		case strings.HasPrefix(line, "@@"):
+			l.emit(TokenMeta, end)
+		case strings.HasPrefix(line, "+"), strings.HasPrefix(line, ">"):
+			l.emit(TokenInserted, end)
+		case strings.HasPrefix(line, "-"), strings.HasPrefix(line, "<"):
+			l.emit(TokenDeleted, end)
+		default:
+			l.emit(TokenText, end)
+		}
+		l.emit(TokenText, end+1)
+	}
+	return l.toks
+}
@@ -0,0 +1,93 @@
+package color
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// dumpTokens returns the tokens other than TokenText.
+func dumpTokens(toks []Token) string {
+	var sb strings.Builder
+	for _, t := range toks {
+		if t.Kind != TokenText {
+			_, _ = fmt.Fprintf(&sb, "%s(%s) ", t.Kind, t.Text)
+		}
+	}
+	return strings.TrimSpace(sb.String())
+}
+
+func TestTokenize(t *testing.T) {
+	for _, c := range []struct {
+		lang, src, want string
+	}{
+		{"go", "// c\nfunc f() []int { return nil }", "comment(// c) keyword(func) operator(()) operator([]) builtin(int) operator({) keyword(return) literal(nil) operator(})"},
+		{"go", "s := `a` + \"b\\\"\" + 'c' + 0x1F + 2.5e-3", "operator(:=) string(`a`) operator(+) string(\"b\\\"\") operator(+) string('c') operator(+) number(0x1F) operator(+) number(2.5e-3)"},
+		{"bash", "# c\nfor f in *; do echo \"$f\" $HOME ${x} --n=2; done", "comment(# c) keyword(for) keyword(in) operator(;) keyword(do) builtin(echo) string(\"$f\") variable($HOME) variable(${x}) operator(=) number(2) operator(;) keyword(done)"},
+		{"json", `{"a": [1, true, null], "b": "s"}`, `operator({) key("a") operator(:) operator([) number(1) operator(,) literal(true) operator(,) literal(null) operator(],) key("b") operator(:) string("s") operator(})`},
+		{"yaml", "---\nk: v # c\nl:\n  - 1\n  - on\na: &x !t y\nt: |\n  text\nn: 2", "section(---) key(k) operator(:) string(v) comment(# c) key(l) operator(:) operator(-) number(1) operator(-) literal(on) key(a) operator(:) meta(&x) meta(!t) string(y) key(t) operator(:) operator(|) string(text) key(n) operator(:) number(2)"},
+		{"toml", "[a.b]\nk.x = 'v' # c\nd = 1979-05-27\nl = [1, true]", "section([a.b]) key(k) operator(.) key(x) operator(=) string('v') comment(# c) key(d) operator(=) number(1979-05-27) key(l) operator(=) operator([) number(1) operator(,) literal(true) operator(])"},
+		{"diff", "--- a\n+++ b\n@@ -1 +1 @@\n-x\n+y\n z", "section(--- a) section(+++ b) meta(@@ -1 +1 @@) deleted(-x) inserted(+y)"},
+		{"cobol", "x", ""},
+	} {
+		toks := Tokenize(c.src, c.lang)
+		if got := dumpTokens(toks); got != c.want {
+			t.Errorf("%s %q:\n got %s\nwant %s", c.lang, c.src, got, c.want)
+		}
+		var sb strings.Builder
+		for _, tok := range toks {
+			_, _ = sb.WriteString(tok.Text)
+		}
+		if toks != nil && sb.String() != c.src {
+			t.Errorf("%s %q: the tokens make up %q", c.lang, c.src, sb.String())
+		}
+	}
+}
+
+func TestHighlightCode(t *testing.T) {
+	for _, c := range []struct {
+		code, lang, want string
+	}{
+		{"if x {\n}", "go", "\x1b[96mif\x1b[0m x {\n}"},
+		{"/* a\nb */", "go", "\x1b[90m/* a\x1b[0m\n\x1b[90mb */\x1b[0m"},
+		{"a\nb", "unknown", "\x1b[2ma\x1b[0m\n\x1b[2mb\x1b[0m"},
+	} {
+		if got := HighlightCode(c.code, c.lang); got != c.want {
+			t.Errorf("%q in %s: got %q, want %q", c.code, c.lang, got, c.want)
+		}
+	}
+
+	// a theme can define the roles of the tokens
+	SetTheme(&Theme{Name: "t", Roles: map[Role]Color{"keyword": FgRed}})
+	defer SetTheme(nil)
+	if got, want := HighlightCode("if 1", "go"), "\x1b[31mif\x1b[0m 1"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestCPTCodeLang(t *testing.T) {
+	src := `x <code lang="go">if a &lt; 1 {}</code> y`
+	want := "x \x1b[96mif\x1b[0m a < \x1b[93m1\x1b[0m {} y"
+	if got := GetCPTC().Translate(src, Reset); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	var buf bytes.Buffer
+	tw := NewTranslatingWriter(&buf, GetCPTC())
+	for i := range len(src) {
+		_, _ = tw.Write([]byte{src[i]})
+	}
+	_ = tw.Close()
+	if got := buf.String(); got != want {
+		t.Errorf("writer: got %q, want %q", got, want)
+	}
+
+	buf.Reset()
+	tw = NewTranslatingWriter(&buf, GetCPTNC())
+	_, _ = tw.Write([]byte(src))
+	_ = tw.Close()
+	if got, want := buf.String(), "x if a < 1 {} y"; got != want {
+		t.Errorf("plain writer: got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,352 @@
+package color
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hedzr/is/term"
+	"github.com/hedzr/is/term/chk"
+)
+
+// ImageProtocol is the way to show an image in a terminal.
+type ImageProtocol int
+
+const (
+	ImageAuto      ImageProtocol = iota // detect it, see [DetectImageProtocol]
+	ImageHalfBlock                      // the "▀" characters in true colors, works everywhere
+	ImageKitty                          // the kitty graphics protocol
+	ImageITerm2                         // the iTerm2 inline images, OSC 1337
+	ImageSixel                          // the DEC sixel graphics
+)
+
+func (p ImageProtocol) String() string {
+	switch p {
+	case ImageHalfBlock:
+		return "halfblock"
+	case ImageKitty:
+		return "kitty"
+	case ImageITerm2:
+		return "iterm2"
+	case ImageSixel:
+		return "sixel"
+	}
+	return "auto"
+}
+
+var (
+	imageProtoOnce sync.Once
+	imageProto     ImageProtocol
+)
+
+// DetectImageProtocol returns the image protocol supported by the
+// terminal. It's decided by the environment variables (kitty and
+// ghostty use [ImageKitty], iTerm2 and WezTerm use [ImageITerm2]),
+// or by the Device Attributes query (4 means sixel), and falls
+// back to [ImageHalfBlock].
+func DetectImageProtocol() ImageProtocol {
+	imageProtoOnce.Do(func() {
+		imageProto = imageProtocolByEnv(os.Getenv)
+		if imageProto == ImageHalfBlock && querySixel() {
+			imageProto = ImageSixel
+		}
+	})
+	return imageProto
+}
+
+func imageProtocolByEnv(getenv func(string) string) ImageProtocol {
+	termName, prog := getenv("TERM"), getenv("TERM_PROGRAM")
+	switch {
+	case getenv("KITTY_WINDOW_ID") != "", termName == "xterm-kitty",
+		prog == "ghostty", termName == "xterm-ghostty":
+		return ImageKitty
+	case prog == "iTerm.app", prog == "WezTerm", getenv("LC_TERMINAL") == "iTerm2":
+		return ImageITerm2
+	}
+	return ImageHalfBlock
+}
+
+// querySixel asks the terminal for its Device Attributes, and
+// reports whether the sixel graphics (4) is one of them.
+func querySixel() bool {
+	if !chk.IsTty(os.Stdin) || !chk.IsTty(os.Stdout) {
+		return false
+	}
+	reply, err := chk.Query(int(os.Stdin.Fd()), os.Stdout, "\x1b[c", 200*time.Millisecond, hasDAReply)
+	if err != nil {
+		return false
+	}
+	return daHasSixel(string(reply))
+}
+
+// daHasSixel reports whether a Device Attributes reply, such as
+// "ESC [ ? 62 ; 4 ; 22 c", has the sixel graphics.
+func daHasSixel(reply string) bool {
+	i := strings.LastIndex(reply, "\x1b[?")
+	if i < 0 || !strings.HasSuffix(reply, "c") {
+		return false
+	}
+	for _, attr := range strings.Split(reply[i+3:len(reply)-1], ";") {
+		if attr == "4" {
+			return true
+		}
+	}
+	return false
+}
+
+// ImageOpt is a functional option for [WriteImage].
+type ImageOpt func(iw *imageWriter)
+
+// WithImageProtocol sets the protocol, default is [ImageAuto].
+func WithImageProtocol(p ImageProtocol) ImageOpt {
+	return func(iw *imageWriter) {
+		iw.proto = p
+	}
+}
+
+// WithImageSize sets the size of the image in the cells, 0 means
+// to keep the aspect ratio with the other one. By default, the
+// image is shrunk to fit the width of the terminal (or 80
+// columns).
+func WithImageSize(cols, rows int) ImageOpt {
+	return func(iw *imageWriter) {
+		iw.cols, iw.rows = max(cols, 0), max(rows, 0)
+	}
+}
+
+type imageWriter struct {
+	proto      ImageProtocol
+	cols, rows int
+}
+
+// The pixels of a cell assumed for the scaling, the terminals
+// have the cells about twice as high as wide. So is a half block
+// cell, 1x2 pixels.
+const (
+	cellPixelW = 10
+	cellPixelH = 20
+)
+
+// WriteImage writes img to w, in the protocol detected (see
+// [DetectImageProtocol]) if w is a capable terminal, or in the
+// half blocks.
+//
+//	f, _ := os.Open("chart.png")
+//	img, _, _ := image.Decode(f)
+//	err := color.WriteImage(os.Stdout, img, color.WithImageSize(40, 0))
+func WriteImage(w io.Writer, img image.Image, opts ...ImageOpt) error {
+	iw := &imageWriter{}
+	for _, opt := range opts {
+		opt(iw)
+	}
+	if iw.proto == ImageAuto {
+		iw.proto = ImageHalfBlock
+		if oscCapable(w) {
+			iw.proto = DetectImageProtocol()
+		}
+	}
+	cols, rows := iw.cells(img.Bounds(), w)
+
+	var buf bytes.Buffer
+	switch iw.proto {
+	case ImageKitty, ImageITerm2:
+		var data bytes.Buffer
+		if err := png.Encode(&data, img); err != nil {
+			return fmt.Errorf("color: encode image: %w", err)
+		}
+		if iw.proto == ImageKitty {
+			encodeKitty(&buf, data.Bytes(), cols, rows)
+		} else {
+			encodeITerm2(&buf, data.Bytes(), cols, rows)
+		}
+	case ImageSixel:
+		encodeSixel(&buf, scaleImage(img, cols*cellPixelW, rows*cellPixelH))
+	default:
+		encodeHalfBlock(&buf, scaleImage(img, cols, rows*2))
+		_, err := io.WriteString(w, DownsampleString(buf.String(), ColorLevel(w)))
+		return err
+	}
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// cells returns the size of the image in the cells.
+func (iw *imageWriter) cells(b image.Rectangle, w io.Writer) (cols, rows int) {
+	pw, ph := max(b.Dx(), 1), max(b.Dy(), 1)
+	cols, rows = iw.cols, iw.rows
+	switch {
+	case cols > 0 && rows > 0:
+	case cols > 0:
+		rows = (ph*cols*cellPixelW/pw + cellPixelH - 1) / cellPixelH
+	case rows > 0:
+		cols = (pw*rows*cellPixelH/ph + cellPixelW - 1) / cellPixelW
+	default:
+		width := 80
+		if f, ok := w.(interface{ Fd() uintptr }); ok {
+			if c, _, err := term.GetTtySizeByFd(f.Fd()); err == nil && c > 0 {
+				width = c
+			}
+		}
+		// no enlarging, a half block is 1x2 pixels
+		perCol := cellPixelW
+		if iw.proto == ImageHalfBlock {
+			perCol = 1
+		}
+		cols = min((pw+perCol-1)/perCol, width)
+		rows = (ph*cols*cellPixelW/pw + cellPixelH - 1) / cellPixelH
+	}
+	return max(cols, 1), max(rows, 1)
+}
+
+// scaleImage resizes img to w x h pixels by the nearest
+// neighbors.
+func scaleImage(img image.Image, w, h int) *image.NRGBA {
+	b := img.Bounds()
+	dst := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := range h {
+		sy := b.Min.Y + y*b.Dy()/h
+		for x := range w {
+			dst.Set(x, y, img.At(b.Min.X+x*b.Dx()/w, sy))
+		}
+	}
+	return dst
+}
+
+// kittyChunk is the max size of a payload chunk of the kitty
+// graphics protocol.
+const kittyChunk = 4096
+
+// encodeKitty writes a PNG image in the kitty graphics protocol,
+// the payload is split into the chunks.
+func encodeKitty(buf *bytes.Buffer, data []byte, cols, rows int) {
+	payload := base64.StdEncoding.EncodeToString(data)
+	for i := 0; i == 0 || i < len(payload); i += kittyChunk {
+		chunk := payload[i:min(i+kittyChunk, len(payload))]
+		more := 0
+		if i+kittyChunk < len(payload) {
+			more = 1
+		}
+		_, _ = buf.WriteString("\x1b_G")
+		if i == 0 {
+			_, _ = fmt.Fprintf(buf, "a=T,f=100,c=%d,r=%d,", cols, rows)
+		}
+		_, _ = fmt.Fprintf(buf, "m=%d;%s\x1b\\", more, chunk)
+	}
+	_ = buf.WriteByte('\n')
+}
+
+// encodeITerm2 writes a PNG image in the iTerm2 inline images
+// protocol.
+func encodeITerm2(buf *bytes.Buffer, data []byte, cols, rows int) {
+	_, _ = fmt.Fprintf(buf, "\x1b]1337;File=inline=1;size=%d;width=%d;height=%d;preserveAspectRatio=0:",
+		len(data), cols, rows)
+	_, _ = buf.WriteString(base64.StdEncoding.EncodeToString(data))
+	_, _ = buf.WriteString("\a\n")
+}
+
+// encodeSixel writes img in sixel, the colors are mapped to the
+// 6x6x6 color cube, and the transparent pixels are skipped.
+func encodeSixel(buf *bytes.Buffer, img *image.NRGBA) {
+	w, h := img.Rect.Dx(), img.Rect.Dy()
+	idx := make([]int16, w*h)
+	var used [216]bool
+	level := func(v uint8) int { return (int(v)*5 + 127) / 255 }
+	for y := range h {
+		for x := range w {
+			c := img.NRGBAAt(x, y)
+			if c.A < 128 {
+				idx[y*w+x] = -1
+				continue
+			}
+			i := level(c.R)*36 + level(c.G)*6 + level(c.B)
+			idx[y*w+x], used[i] = int16(i), true
+		}
+	}
+
+	_, _ = fmt.Fprintf(buf, "\x1bP0;1;0q\"1;1;%d;%d", w, h)
+	for i, ok := range used {
+		if ok {
+			_, _ = fmt.Fprintf(buf, "#%d;2;%d;%d;%d", i, i/36*20, i/6%6*20, i%6*20)
+		}
+	}
+	row := make([]byte, w)
+	for y0 := 0; y0 < h; y0 += 6 {
+		var band [216]bool
+		for k := y0; k < min(y0+6, h); k++ {
+			for _, i := range idx[k*w : (k+1)*w] {
+				if i >= 0 {
+					band[i] = true
+				}
+			}
+		}
+		for c, ok := range band {
+			if !ok {
+				continue
+			}
+			for x := range w {
+				bits := byte(0)
+				for k := 0; k < 6 && y0+k < h; k++ {
+					if idx[(y0+k)*w+x] == int16(c) {
+						bits |= 1 << k
+					}
+				}
+				row[x] = '?' + bits
+			}
+			_, _ = buf.WriteString("#" + strconv.Itoa(c))
+			writeSixelRow(buf, row)
+			_ = buf.WriteByte('$')
+		}
+		_ = buf.WriteByte('-')
+	}
+	_, _ = buf.WriteString("\x1b\\")
+}
+
+// writeSixelRow writes the sixels of a row with the run-length
+// encoding ("!n" and a sixel).
+func writeSixelRow(buf *bytes.Buffer, row []byte) {
+	for i := 0; i < len(row); {
+		j := i + 1
+		for j < len(row) && row[j] == row[i] {
+			j++
+		}
+		if n := j - i; n > 3 {
+			_, _ = fmt.Fprintf(buf, "!%d%c", n, row[i])
+		} else {
+			_, _ = buf.Write(row[i:j])
+		}
+		i = j
+	}
+}
+
+// encodeHalfBlock writes img in the upper half blocks "▀", whose
+// foreground is the upper pixel and background is the lower one.
+func encodeHalfBlock(buf *bytes.Buffer, img *image.NRGBA) {
+	w, h := img.Rect.Dx(), img.Rect.Dy()
+	for y := 0; y < h; y += 2 {
+		for x := range w {
+			top, bottom := img.NRGBAAt(x, y), img.NRGBAAt(x, y+1) // transparent if out of range
+			switch {
+			case top.A < 128 && bottom.A < 128:
+				_, _ = buf.WriteString(ResetToNormalColor.Color() + " ")
+			case top.A < 128:
+				_, _ = buf.WriteString(ResetToNormalColor.Color() +
+					NewColor16m(bottom.R, bottom.G, bottom.B, false).Color() + "▄")
+			case bottom.A < 128:
+				_, _ = buf.WriteString(ResetToNormalColor.Color() +
+					NewColor16m(top.R, top.G, top.B, false).Color() + "▀")
+			default:
+				_, _ = buf.WriteString(NewColor16m(top.R, top.G, top.B, false).Color() +
+					NewColor16m(bottom.R, bottom.G, bottom.B, true).Color() + "▀")
+			}
+		}
+		_, _ = buf.WriteString(ResetToNormalColor.Color() + "\n")
+	}
+}
@@ -0,0 +1,132 @@
+package color
+
+import (
+	"bytes"
+	"encoding/base64"
+	"image"
+	imgcolor "image/color"
+	"strings"
+	"testing"
+)
+
+func testImage() *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, 2, 2))
+	img.SetNRGBA(0, 0, imgcolor.NRGBA{255, 0, 0, 255})
+	img.SetNRGBA(1, 0, imgcolor.NRGBA{0, 0, 255, 255})
+	img.SetNRGBA(0, 1, imgcolor.NRGBA{0, 255, 0, 255})
+	return img // (1, 1) is transparent
+}
+
+func TestWriteImageHalfBlock(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteImage(&buf, testImage(), WithImageSize(2, 1)); err != nil {
+		t.Fatal(err)
+	}
+	want := "\x1b[38;2;255;0;0m\x1b[48;2;0;255;0m▀" +
+		"\x1b[0m\x1b[38;2;0;0;255m▀" +
+		"\x1b[0m\n"
+	if got := buf.String(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if got := ParseANSI(buf.String()).Plain(); got != "▀▀\n" {
+		t.Errorf("plain: got %q", got)
+	}
+}
+
+func TestWriteImageSixel(t *testing.T) {
+	var buf bytes.Buffer
+	encodeSixel(&buf, testImage())
+	// red 180, green 30, blue 5; the bits are the rows of a column
+	want := "\x1bP0;1;0q\"1;1;2;2" +
+		"#5;2;0;0;100#30;2;0;100;0#180;2;100;0;0" +
+		"#5?@$#30A?$#180@?$-\x1b\\"
+	if got := buf.String(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	buf.Reset()
+	writeSixelRow(&buf, []byte("@@@@@AA~"))
+	if got, want := buf.String(), "!5@AA~"; got != want {
+		t.Errorf("rle: got %q, want %q", got, want)
+	}
+}
+
+func TestWriteImageKitty(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteImage(&buf, testImage(), WithImageProtocol(ImageKitty), WithImageSize(4, 2)); err != nil {
+		t.Fatal(err)
+	}
+	got := buf.String()
+	if !strings.HasPrefix(got, "\x1b_Ga=T,f=100,c=4,r=2,m=0;") || !strings.HasSuffix(got, "\x1b\\\n") {
+		t.Errorf("got %q", got)
+	}
+
+	buf.Reset()
+	encodeKitty(&buf, make([]byte, kittyChunk), 1, 1) // 4096 bytes is 5464 in base64
+	chunks := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\x1b\\")
+	if len(chunks) != 3 || !strings.Contains(chunks[0], "m=1;") || !strings.HasPrefix(chunks[1], "\x1b_Gm=0;") {
+		t.Errorf("chunks: %q", chunks)
+	}
+}
+
+func TestWriteImageITerm2(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteImage(&buf, testImage(), WithImageProtocol(ImageITerm2), WithImageSize(4, 0)); err != nil {
+		t.Fatal(err)
+	}
+	got := buf.String()
+	const head = "\x1b]1337;File=inline=1;size="
+	if !strings.HasPrefix(got, head) || !strings.Contains(got, ";width=4;height=2;") || !strings.HasSuffix(got, "\a\n") {
+		t.Fatalf("got %q", got)
+	}
+	data := got[strings.IndexByte(got, ':')+1 : len(got)-2]
+	if png, err := base64.StdEncoding.DecodeString(data); err != nil || !bytes.HasPrefix(png, []byte("\x89PNG")) {
+		t.Errorf("payload: %v", err)
+	}
+}
+
+func TestImageProtocolByEnv(t *testing.T) {
+	for _, c := range []struct {
+		env  map[string]string
+		want ImageProtocol
+	}{
+		{map[string]string{"KITTY_WINDOW_ID": "1"}, ImageKitty},
+		{map[string]string{"TERM": "xterm-kitty"}, ImageKitty},
+		{map[string]string{"TERM_PROGRAM": "ghostty"}, ImageKitty},
+		{map[string]string{"TERM_PROGRAM": "iTerm.app"}, ImageITerm2},
+		{map[string]string{"TERM_PROGRAM": "WezTerm"}, ImageITerm2},
+		{map[string]string{"TERM": "xterm-256color"}, ImageHalfBlock},
+	} {
+		if got := imageProtocolByEnv(func(k string) string { return c.env[k] }); got != c.want {
+			t.Errorf("%v: got %v, want %v", c.env, got, c.want)
+		}
+	}
+	for reply, want := range map[string]bool{
+		"\x1b[?62;4;22c": true,
+		"\x1b[?62;22c":   false,
+		"\x1b[?4c":       true,
+		"":               false,
+	} {
+		if got := daHasSixel(reply); got != want {
+			t.Errorf("daHasSixel(%q) = %v", reply, got)
+		}
+	}
+}
+
+func TestImageCells(t *testing.T) {
+	b := image.Rect(0, 0, 200, 100)
+	for _, c := range []struct {
+		iw         imageWriter
+		cols, rows int
+	}{
+		{imageWriter{proto: ImageHalfBlock}, 80, 20},
+		{imageWriter{proto: ImageKitty}, 20, 5},
+		{imageWriter{proto: ImageKitty, cols: 40}, 40, 10},
+		{imageWriter{proto: ImageKitty, rows: 10}, 40, 10},
+		{imageWriter{proto: ImageKitty, cols: 3, rows: 7}, 3, 7},
+	} {
+		if cols, rows := c.iw.cells(b, &bytes.Buffer{}); cols != c.cols || rows != c.rows {
+			t.Errorf("%+v: got %d x %d, want %d x %d", c.iw, cols, rows, c.cols, c.rows)
+		}
+	}
+}
@@ -0,0 +1,156 @@
+package color
+
+import (
+	"encoding/base64"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/hedzr/is/states"
+)
+
+// osc returns an OSC sequence terminated by ST.
+func osc(params ...string) string {
+	return "\x1b]" + strings.Join(params, ";") + "\x1b\\"
+}
+
+// oscCapable reports whether the OSC sequences can be written to
+// w: the colors are enabled, w is a colorful terminal (or the
+// color level is forced, see [SetColorLevel]), and the terminal
+// isn't a dumb one.
+func oscCapable(w io.Writer) bool {
+	if states.Env().IsNoColorMode() || ColorLevel(w) <= 0 {
+		return false
+	}
+	if ti := currentTerminfo(); ti != nil && !isANSI(ti) {
+		return false
+	}
+	return true
+}
+
+// Hyperlink returns text linked to url by OSC 8. The control
+// characters in url are removed, so it can't end the sequence.
+func Hyperlink(url, text string) string {
+	return osc("8", "", sanitizeOSC(url)) + text + osc("8", "", "")
+}
+
+// linkFallback returns the plain text of a link, "text (url)", or
+// url if text is empty or the same. The control characters in url
+// are removed.
+func linkFallback(url, text string) string {
+	url = sanitizeOSC(url)
+	if text == "" || text == url {
+		return url
+	}
+	return text + " (" + url + ")"
+}
+
+// Link writes text linked to url (OSC 8 hyperlink), or "text
+// (url)" if the output isn't capable.
+//
+//	color.New().Link("https://github.com/hedzr/is", "is").Println().Build()
+func (s *Cursor) Link(url, text string) *Cursor {
+	if oscCapable(s.w) {
+		_, _ = s.sb.WriteString(Hyperlink(url, text))
+	} else {
+		_, _ = s.sb.WriteString(linkFallback(url, text))
+	}
+	return s
+}
+
+// Title sets the window title (OSC 2), nothing is written if
+// the output isn't capable.
+func (s *Cursor) Title(title string) *Cursor {
+	if oscCapable(s.w) {
+		_, _ = s.sb.WriteString(osc("2", sanitizeOSC(title)))
+	}
+	return s
+}
+
+// IconTitle sets both the icon name and the window title (OSC 0),
+// nothing is written if the output isn't capable.
+func (s *Cursor) IconTitle(title string) *Cursor {
+	if oscCapable(s.w) {
+		_, _ = s.sb.WriteString(osc("0", sanitizeOSC(title)))
+	}
+	return s
+}
+
+// Clipboard copies text into the system clipboard (OSC 52), which
+// works over SSH too. Nothing is written if the output isn't
+// capable. Some terminals disable it, or ask the user for the
+// permission.
+func (s *Cursor) Clipboard(text string) *Cursor {
+	if oscCapable(s.w) {
+		_, _ = s.sb.WriteString(osc("52", "c", base64.StdEncoding.EncodeToString([]byte(text))))
+	}
+	return s
+}
+
+// Notify posts a desktop notification, by OSC 777 for the
+// terminals of rxvt, foot and VTE, or by OSC 9 for the others
+// (iTerm2, kitty, WezTerm, Windows Terminal, ...), where title
+// and body are joined.
+//
+// A line "title: body" is written instead if the output isn't
+// capable. The control characters in title and body are removed.
+func (s *Cursor) Notify(title, body string) *Cursor {
+	if oscCapable(s.w) {
+		_, _ = s.sb.WriteString(notifySeq(os.Getenv("TERM"), os.Getenv("VTE_VERSION") != "", title, body))
+		return s
+	}
+	title, body = sanitizeOSC(title), sanitizeOSC(body)
+	switch {
+	case title == "":
+		_, _ = s.sb.WriteString(body + "\n")
+	case body == "":
+		_, _ = s.sb.WriteString(title + "\n")
+	default:
+		_, _ = s.sb.WriteString(title + ": " + body + "\n")
+	}
+	return s
+}
+
+// notifySeq returns the notification sequence for the terminal.
+func notifySeq(termName string, vte bool, title, body string) string {
+	title, body = sanitizeOSC(title), sanitizeOSC(body)
+	if vte || strings.HasPrefix(termName, "rxvt") || strings.HasPrefix(termName, "foot") {
+		return osc("777", "notify", strings.ReplaceAll(title, ";", ","), body)
+	}
+	msg := body
+	if title != "" && body != "" {
+		msg = title + ": " + body
+	} else if body == "" {
+		msg = title
+	}
+	return osc("9", msg)
+}
+
+// sanitizeOSC removes the control characters (C0, DEL and C1)
+// which would end an OSC sequence early.
+func sanitizeOSC(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r < 0x20 || r >= 0x7f && r <= 0x9f {
+			return -1
+		}
+		return r
+	}, s)
+}
+
+// reAnchor matches the <a href="..."> elements, for the no-color
+// mode of the CPT translator.
+var reAnchor = regexp.MustCompile(`(?is)<a\s[^>]*?href\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s>]+))[^>]*>(.*?)</a\s*>`)
+
+// expandAnchors rewrites the <a href> elements in s into their
+// plain text, see [linkFallback].
+func expandAnchors(s string) string {
+	if !strings.Contains(s, "<a") && !strings.Contains(s, "<A") {
+		return s
+	}
+	return reAnchor.ReplaceAllStringFunc(s, func(m string) string {
+		sm := reAnchor.FindStringSubmatch(m)
+		url := sm[1] + sm[2] + sm[3]
+		return linkFallback(url, sm[4])
+	})
+}
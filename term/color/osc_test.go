@@ -0,0 +1,114 @@
+package color
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCursorLink(t *testing.T) {
+	withTerminfo(t, "")
+	if got, want := New().Link("https://x.io", "x").Build(), "x (https://x.io)"; got != want {
+		t.Errorf("not capable: got %q, want %q", got, want)
+	}
+	if got, want := New().Link("https://x.io\x1b[2J\a", "x").Build(), "x (https://x.io[2J)"; got != want {
+		t.Errorf("not capable, controls: got %q, want %q", got, want)
+	}
+
+	SetColorLevel(Level256)
+	defer SetColorLevel(0)
+	if got, want := New().Link("https://x.io", "x").Build(), "\x1b]8;;https://x.io\x1b\\x\x1b]8;;\x1b\\"; got != want {
+		t.Errorf("capable: got %q, want %q", got, want)
+	}
+}
+
+func TestCPTAnchor(t *testing.T) {
+	withTerminfo(t, "")
+	for _, c := range []struct {
+		src, want string
+	}{
+		{`see <a href="https://x.io">x</a>.`, "see x (https://x.io)."},
+		{`<a href="https://x.io">https://x.io</a>`, "https://x.io"},
+		{`<a href="https://x.io"></a>`, "https://x.io"},
+	} {
+		if got := ParseANSI(GetCPTC().Translate(c.src, Reset)).Plain(); got != c.want {
+			t.Errorf("Translate(%q) = %q, want %q", c.src, got, c.want)
+		}
+		if got := GetCPTNC().Translate(c.src, Reset); got != c.want {
+			t.Errorf("no color: Translate(%q) = %q, want %q", c.src, got, c.want)
+		}
+	}
+
+	SetColorLevel(Level256)
+	defer SetColorLevel(0)
+	got := GetCPTC().Translate(`<a href="https://x.io"><b>x</b></a>`, Reset)
+	if !strings.HasPrefix(got, "\x1b]8;;https://x.io\x1b\\") || !strings.HasSuffix(got, "\x1b]8;;\x1b\\") {
+		t.Errorf("capable: got %q", got)
+	}
+	if spans := ParseANSI(got); spans.Plain() != "x" || spans[0].Style.Link != "https://x.io" {
+		t.Errorf("capable: spans %+v", spans)
+	}
+
+	// the controls in href can't end the sequence
+	got = GetCPTC().Translate(`<a href="https://x.io&#27;\&#27;]2;pwned&#7;">x</a>`, Reset)
+	if want := "\x1b]8;;https://x.io\\]2;pwned\x1b\\x\x1b]8;;\x1b\\"; got != want {
+		t.Errorf("malicious href: got %q, want %q", got, want)
+	}
+	if got, want := Hyperlink("https://x.io\x1b\\\x07\u009c", "x"), "\x1b]8;;https://x.io\\\x1b\\x\x1b]8;;\x1b\\"; got != want {
+		t.Errorf("Hyperlink: got %q, want %q", got, want)
+	}
+}
+
+func TestParseCPTLink(t *testing.T) {
+	spans := ParseCPT(`<a href="https://x.io">x</a>`)
+	if len(spans) != 1 || spans[0].Style.Link != "https://x.io" || spans[0].Text != "x" {
+		t.Errorf("got %+v", spans)
+	}
+}
+
+func TestCursorOSC(t *testing.T) {
+	withTerminfo(t, "")
+	if got := New().Title("t").Clipboard("c").Build(); got != "" {
+		t.Errorf("not capable: got %q", got)
+	}
+	if got, want := New().Notify("build", "done").Build(), "build: done\n"; got != want {
+		t.Errorf("not capable: got %q, want %q", got, want)
+	}
+	if got, want := New().Notify("b\x1b]2;x\a", "done\x1b[2J").Build(), "b]2;x: done[2J\n"; got != want {
+		t.Errorf("not capable, controls: got %q, want %q", got, want)
+	}
+
+	SetColorLevel(Level256)
+	defer SetColorLevel(0)
+	for _, c := range []struct {
+		got, want string
+	}{
+		{New().Title("a\x07b").Build(), "\x1b]2;ab\x1b\\"},
+		{New().IconTitle("t").Build(), "\x1b]0;t\x1b\\"},
+		{New().Clipboard("hi").Build(), "\x1b]52;c;aGk=\x1b\\"},
+	} {
+		if c.got != c.want {
+			t.Errorf("got %q, want %q", c.got, c.want)
+		}
+	}
+}
+
+func TestNotifySeq(t *testing.T) {
+	for _, c := range []struct {
+		term  string
+		vte   bool
+		title string
+		body  string
+		want  string
+	}{
+		{"xterm-kitty", false, "build", "done", "\x1b]9;build: done\x1b\\"},
+		{"xterm-256color", false, "", "done", "\x1b]9;done\x1b\\"},
+		{"xterm-256color", false, "build", "", "\x1b]9;build\x1b\\"},
+		{"xterm-256color", true, "a;b", "done", "\x1b]777;notify;a,b;done\x1b\\"},
+		{"rxvt-unicode", false, "build", "done", "\x1b]777;notify;build;done\x1b\\"},
+		{"foot", false, "build", "done\n", "\x1b]777;notify;build;done\x1b\\"},
+	} {
+		if got := notifySeq(c.term, c.vte, c.title, c.body); got != c.want {
+			t.Errorf("notifySeq(%q, %v, %q, %q) = %q, want %q", c.term, c.vte, c.title, c.body, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,309 @@
+package color
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/hedzr/is/states"
+	"github.com/hedzr/is/term"
+	"github.com/hedzr/is/term/chk"
+)
+
+// Stdout and Stderr are the outputs of os.Stdout and os.Stderr,
+// each one has its own profile. For example, the colors are
+// written to Stderr if it's a terminal while Stdout is
+// redirected to a file.
+var (
+	Stdout = NewOutput(os.Stdout)
+	Stderr = NewOutput(os.Stderr)
+)
+
+// Output is a writer with its own profile: is it a tty, the
+// color level, the width and the no-color mode. The profile is
+// detected from the underlying writer, or set by the options.
+//
+// What is written through an Output is adapted to the profile:
+//
+//   - the colors are downsampled for the color level (see
+//     [DownsampleString]),
+//   - the SGR sequences are removed if it isn't colorful, such as
+//     in no-color mode,
+//   - all escape sequences (such as the cursor movements) are
+//     removed if it isn't a tty, so a file gets the plain text.
+//
+// An Output is a [Writer], so it can be used by [Cursor.WithWriter],
+// [RowsBlock.WithWriter], [NewTranslatingWriter], and so on, and
+// [chk.IsTty], [chk.IsColorful] and [ColorLevel] report its
+// profile:
+//
+//	out := color.NewOutput(os.Stderr)
+//	out.Colored(color.FgRed, "failed: %v", err)
+//	fmt.Fprint(out, out.Translate("<b>done</b>\n"))
+type Output struct {
+	w  io.Writer
+	fd uintptr
+
+	tty                  bool
+	level                int64
+	width                int
+	noColor              bool
+	levelSet, noColorSet bool
+
+	mu      sync.Mutex
+	pending []byte // an incomplete escape sequence
+}
+
+// OutputOpt is the option of [NewOutput].
+type OutputOpt func(o *Output)
+
+// WithOutputTty sets if the output is a tty.
+func WithOutputTty(tty bool) OutputOpt {
+	return func(o *Output) { o.tty = tty }
+}
+
+// WithOutputLevel sets the color level, such as [Level256]. 0
+// disables the colors.
+func WithOutputLevel(level int64) OutputOpt {
+	return func(o *Output) { o.level, o.levelSet = level, true }
+}
+
+// WithOutputWidth sets the width in columns.
+func WithOutputWidth(cols int) OutputOpt {
+	return func(o *Output) { o.width = cols }
+}
+
+// WithOutputNoColor sets the no-color mode of the output, which
+// follows `states.Env().IsNoColorMode()` by default.
+func WithOutputNoColor(b bool) OutputOpt {
+	return func(o *Output) { o.noColor, o.noColorSet = b, true }
+}
+
+// NewOutput returns an Output of w, its profile is detected
+// unless it's set by the options.
+//
+// The color level is detected when it's asked, so a level forced
+// by [SetColorLevel] later is honoured.
+func NewOutput(w io.Writer, opts ...OutputOpt) *Output {
+	o := &Output{w: w, fd: ^uintptr(0), tty: chk.IsTty(w)}
+	if f, ok := w.(interface{ Fd() uintptr }); ok {
+		o.fd = f.Fd()
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// Fd returns the file descriptor of the underlying writer, or an
+// invalid one.
+func (o *Output) Fd() uintptr { return o.fd }
+
+// Unwrap returns the underlying writer.
+func (o *Output) Unwrap() io.Writer { return o.w }
+
+// IsTty reports whether the output is a tty.
+func (o *Output) IsTty() bool { return o.tty }
+
+// NoColor reports whether the output is in no-color mode.
+func (o *Output) NoColor() bool {
+	if o.noColorSet {
+		return o.noColor
+	}
+	return states.Env().IsNoColorMode()
+}
+
+// Level returns the color level of the output, see [ColorLevel].
+func (o *Output) Level() int64 {
+	if o.levelSet {
+		return o.level
+	}
+	if level := forcedLevel.Load(); level > 0 {
+		return level
+	}
+	if !o.tty {
+		return 0
+	}
+	return ColorLevel(o.w)
+}
+
+// IsColorful reports whether the colors are written.
+func (o *Output) IsColorful() bool {
+	return !o.NoColor() && o.Level() > 0
+}
+
+// Width returns the columns of the output, the terminal width if
+// it's a tty, or 0 if unknown.
+func (o *Output) Width() int {
+	if o.width > 0 || !o.tty {
+		return o.width
+	}
+	if f, ok := o.w.(*os.File); ok && f == os.Stdout {
+		cols, _ := term.GetTtySize()
+		return cols
+	}
+	cols, _, _ := term.GetTtySizeByFd(o.fd)
+	return cols
+}
+
+// Write writes p adapted to the profile, see [Output]. An
+// incomplete escape sequence at the end is held until the next
+// Write or Flush.
+func (o *Output) Write(p []byte) (n int, err error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	colorful, level := o.IsColorful(), o.Level()
+	if len(o.pending) == 0 && colorful && level >= Level16m {
+		return o.w.Write(p)
+	}
+
+	s := string(append(o.pending, p...))
+	o.pending = o.pending[:0]
+	if i := pendingAt(s); i < len(s) && len(s)-i <= maxLineLen {
+		o.pending = append(o.pending, s[i:]...)
+		s = s[:i]
+	}
+	if s == "" {
+		return len(p), nil
+	}
+	if _, err = io.WriteString(o.w, o.adapt(s, colorful, level)); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// adapt downsamples the colors in s, or strips the escape
+// sequences the output can't take.
+func (o *Output) adapt(s string, colorful bool, level int64) string {
+	switch {
+	case colorful:
+		return DownsampleString(s, level)
+	case o.tty:
+		return stripEscapes(s, isSGR)
+	}
+	return stripEscapes(s, nil)
+}
+
+// WriteString writes s, see [Output.Write].
+func (o *Output) WriteString(s string) (n int, err error) {
+	return o.Write([]byte(s))
+}
+
+// Flush writes the held incomplete escape sequence, adapted like
+// [Output.Write] does.
+func (o *Output) Flush() (err error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if len(o.pending) == 0 {
+		return
+	}
+	s := o.adapt(string(o.pending), o.IsColorful(), o.Level())
+	o.pending = o.pending[:0]
+	if s != "" {
+		_, err = io.WriteString(o.w, s)
+	}
+	return
+}
+
+// Translator returns the CPT translator for the output: the
+// colored one if it's colorful, or the one strips the tags. The
+// links are written by OSC 8 if the output is capable.
+func (o *Output) Translator() Translator {
+	if !o.IsColorful() {
+		return &cptNC
+	}
+	return &cpTranslator{out: o}
+}
+
+// Translate translates the CPT markups in s for the output.
+func (o *Output) Translate(s string) string {
+	if o.IsColorful() {
+		return o.Translator().Translate(s, Reset)
+	}
+	var sb strings.Builder
+	tw := NewTranslatingWriter(&sb, &cptNC)
+	_, _ = tw.Write([]byte(s))
+	_ = tw.Close()
+	return sb.String()
+}
+
+// Text prints formatted message without any predefined ansi escaping.
+func (o *Output) Text(format string, args ...any) {
+	_, _ = fmt.Fprintf(o, format, args...)
+}
+
+// Highlight prints formatted message in bold, a newline is
+// appended if it doesn't end with one.
+func (o *Output) Highlight(format string, args ...any) {
+	o.styled("\x1b[0;1m", format, args...)
+}
+
+// Dim prints formatted message in dim.
+func (o *Output) Dim(format string, args ...any) {
+	o.styled("\x1b[2m\x1b[37m", format, args...)
+}
+
+// Dimf prints formatted message in dim in verbose mode.
+func (o *Output) Dimf(format string, args ...any) {
+	if states.Env().IsVerboseMode() {
+		o.Dim(format, args...)
+	}
+}
+
+// Colored prints formatted message in clr.
+func (o *Output) Colored(clr Color, format string, args ...any) {
+	o.styled(clr.Color(), format, args...)
+}
+
+// Coloredf prints formatted message in clr in verbose mode.
+func (o *Output) Coloredf(clr Color, format string, args ...any) {
+	if states.Env().IsVerboseMode() {
+		o.Colored(clr, format, args...)
+	}
+}
+
+func (o *Output) styled(sgr, format string, args ...any) {
+	text := fmt.Sprintf(format, args...)
+	var sb strings.Builder
+	_, _ = sb.WriteString(sgr + text + "\x1b[0m")
+	if !strings.HasSuffix(text, "\n") {
+		_ = sb.WriteByte('\n')
+	}
+	_, _ = o.WriteString(sb.String())
+}
+
+// stripEscapes removes the escape sequences from s, or those
+// matched by only if it isn't nil.
+func stripEscapes(s string, only func(seq string) bool) string {
+	if !strings.Contains(s, "\x1b") {
+		return s
+	}
+	var sb strings.Builder
+	scanEscapes(s, func(part string, esc bool) bool {
+		if !esc || only != nil && !only(part) {
+			_, _ = sb.WriteString(part)
+		}
+		return true
+	})
+	return sb.String()
+}
+
+// pendingAt returns the start of the unterminated escape sequence
+// at the end of s, or len(s).
+func pendingAt(s string) int {
+	for i := 0; i < len(s); {
+		j := strings.IndexByte(s[i:], '\x1b')
+		if j < 0 {
+			break
+		}
+		i += j
+		n := escapeLen(s[i:])
+		if n == 0 || i+n >= len(s) && !escapeComplete(s[i:]) {
+			return i
+		}
+		i += max(n, 1)
+	}
+	return len(s)
+}
@@ -0,0 +1,102 @@
+package color
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/hedzr/is/term/chk"
+)
+
+func TestOutputWrite(t *testing.T) {
+	const src = "\x1b[31mred\x1b[0m \x1b[2K\x1b]8;;https://x.io\x1b\\link\x1b]8;;\x1b\\ \x1b[38;5;196mx\x1b[0m"
+	for _, c := range []struct {
+		name string
+		opts []OutputOpt
+		want string
+	}{
+		{"file", nil, "red link x"},
+		{"tty no color", []OutputOpt{WithOutputTty(true), WithOutputLevel(0)},
+			"red \x1b[2K\x1b]8;;https://x.io\x1b\\link\x1b]8;;\x1b\\ x"},
+		{"no-color mode", []OutputOpt{WithOutputTty(true), WithOutputLevel(Level16m), WithOutputNoColor(true)},
+			"red \x1b[2K\x1b]8;;https://x.io\x1b\\link\x1b]8;;\x1b\\ x"},
+		{"16 colors", []OutputOpt{WithOutputLevel(Level16)}, DownsampleString(src, Level16)},
+		{"true colors", []OutputOpt{WithOutputLevel(Level16m)}, src},
+	} {
+		var buf bytes.Buffer
+		o := NewOutput(&buf, c.opts...)
+		_, _ = o.Write([]byte(src))
+		if got := buf.String(); got != c.want {
+			t.Errorf("%s: got %q, want %q", c.name, got, c.want)
+		}
+
+		// an escape sequence can be split into the writes
+		buf.Reset()
+		for i := range len(src) {
+			_, _ = o.Write([]byte{src[i]})
+		}
+		_ = o.Flush()
+		if got := buf.String(); got != c.want {
+			t.Errorf("%s, byte by byte: got %q, want %q", c.name, got, c.want)
+		}
+	}
+
+	// the held sequence is flushed like the written ones
+	for _, c := range []struct {
+		name string
+		opts []OutputOpt
+		want string
+	}{
+		{"file", nil, "x"},
+		{"tty no color", []OutputOpt{WithOutputTty(true), WithOutputLevel(0)}, "x\x1b[2"},
+		{"16 colors", []OutputOpt{WithOutputLevel(Level16)}, "x\x1b[2"},
+	} {
+		var buf bytes.Buffer
+		o := NewOutput(&buf, c.opts...)
+		_, _ = o.Write([]byte("x\x1b[2"))
+		_ = o.Flush()
+		if got := buf.String(); got != c.want {
+			t.Errorf("flush, %s: got %q, want %q", c.name, got, c.want)
+		}
+	}
+}
+
+func TestOutputProfile(t *testing.T) {
+	var b1, b2 bytes.Buffer
+	file := NewOutput(&b1)
+	tty := NewOutput(&b2, WithOutputTty(true), WithOutputLevel(Level256), WithOutputNoColor(false), WithOutputWidth(100))
+
+	if chk.IsTty(file) || chk.IsColorful(file) || ColorLevel(file) != 0 || file.Width() != 0 {
+		t.Errorf("file: tty %v, colorful %v, level %d, width %d", chk.IsTty(file), chk.IsColorful(file), ColorLevel(file), file.Width())
+	}
+	if !chk.IsTty(tty) || !chk.IsColorful(tty) || ColorLevel(tty) != Level256 || tty.Width() != 100 {
+		t.Errorf("tty: tty %v, colorful %v, level %d, width %d", chk.IsTty(tty), chk.IsColorful(tty), ColorLevel(tty), tty.Width())
+	}
+
+	// a forced level makes the outputs colorful unless they have
+	// their own levels
+	SetColorLevel(Level16)
+	defer SetColorLevel(0)
+	if file.Level() != Level16 || tty.Level() != Level256 {
+		t.Errorf("levels: %d, %d", file.Level(), tty.Level())
+	}
+}
+
+func TestOutputHelpers(t *testing.T) {
+	var b1, b2 bytes.Buffer
+	file := NewOutput(&b1)
+	tty := NewOutput(&b2, WithOutputTty(true), WithOutputLevel(Level16m), WithOutputNoColor(false))
+
+	for _, o := range []*Output{file, tty} {
+		o.Highlight("hi %d", 1)
+		o.Colored(FgRed, "red\n")
+		o.Text("<%s>", "t")
+		New().WithWriter(o).Color16(FgGreen).Println("green").Flush()
+		_, _ = o.WriteString(o.Translate("<b>b</b> &lt;<a href=\"https://x.io\">l</a>\n"))
+	}
+	if got, want := b1.String(), "hi 1\nred\n<t>green\nb <l (https://x.io)\n"; got != want {
+		t.Errorf("file: got %q, want %q", got, want)
+	}
+	if got, want := b2.String(), "\x1b[0;1mhi 1\x1b[0m\n\x1b[31mred\n\x1b[0m<t>\x1b[32mgreen\x1b[0m\n\x1b[1mb\x1b[0m"; len(got) < len(want) || got[:len(want)] != want {
+		t.Errorf("tty: got %q, want the prefix %q", got, want)
+	}
+}
@@ -0,0 +1,368 @@
+package color
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/hedzr/is/states"
+)
+
+// ParseRGB parses a true color, which is one of:
+//
+//	#rgb, #rrggbb
+//	rgb(255, 135, 0), rgb(100% 50% 0%), rgba(...) (the alpha is ignored)
+//	hsl(30, 100%, 50%), hsl(30deg 100% 50%), hsla(...)
+//	rgb:ff/87/00 (the X11 form)
+//	orange, rebeccapurple, light-blue (the CSS/X11 names)
+func ParseRGB(s string) (Color16m, error) {
+	spec := strings.ToLower(strings.TrimSpace(s))
+	var rgb [3]byte
+	var ok bool
+	switch {
+	case strings.HasPrefix(spec, "#"):
+		rgb, ok = parseHex(spec[1:])
+	case strings.HasPrefix(spec, "rgb:"):
+		rgb, ok = parseXColor(spec)
+	case strings.HasPrefix(spec, "rgb(") || strings.HasPrefix(spec, "rgba("):
+		rgb, ok = parseRGBFunc(spec)
+	case strings.HasPrefix(spec, "hsl(") || strings.HasPrefix(spec, "hsla("):
+		var c Color16m
+		if c, ok = parseHSLFunc(spec); ok {
+			return c, nil
+		}
+	default:
+		var v uint32
+		if v, ok = cssColors[strings.ReplaceAll(spec, "-", "")]; ok {
+			rgb = [3]byte{byte(v >> 16), byte(v >> 8), byte(v)}
+		}
+	}
+	if !ok {
+		return Color16m{}, fmt.Errorf("color: invalid color %q", s)
+	}
+	return Color16m{clr: [4]byte{rgb[0], rgb[1], rgb[2]}}, nil
+}
+
+// funcArgs returns the arguments of "name(a, b, c)" or
+// "name(a b c / alpha)", at least 3 of them.
+func funcArgs(spec string) ([]string, bool) {
+	i := strings.IndexByte(spec, '(')
+	if i < 0 || !strings.HasSuffix(spec, ")") {
+		return nil, false
+	}
+	body, _, _ := strings.Cut(spec[i+1:len(spec)-1], "/")
+	args := strings.FieldsFunc(body, func(r rune) bool { return r == ',' || r == ' ' || r == '\t' })
+	return args, len(args) == 3 || len(args) == 4 && strings.Contains(body, ",")
+}
+
+// parseNum parses a number or a percentage, which is scaled to
+// full.
+func parseNum(s string, full float64) (float64, bool) {
+	pct := strings.HasSuffix(s, "%")
+	v, err := strconv.ParseFloat(strings.TrimSuffix(s, "%"), 64)
+	if err != nil {
+		return 0, false
+	}
+	if pct {
+		v = v * full / 100
+	}
+	return math.Max(0, math.Min(v, full)), true
+}
+
+func parseRGBFunc(spec string) (rgb [3]byte, ok bool) {
+	args, ok := funcArgs(spec)
+	if !ok {
+		return
+	}
+	for i := range 3 {
+		v, ok := parseNum(args[i], 255)
+		if !ok {
+			return rgb, false
+		}
+		rgb[i] = byte(math.Round(v))
+	}
+	return rgb, true
+}
+
+func parseHSLFunc(spec string) (c Color16m, ok bool) {
+	args, ok := funcArgs(spec)
+	if !ok {
+		return
+	}
+	h, err := strconv.ParseFloat(strings.TrimSuffix(args[0], "deg"), 64)
+	if err != nil {
+		return c, false
+	}
+	s, ok1 := parseNum(args[1], 1)
+	l, ok2 := parseNum(args[2], 1)
+	if !ok1 || !ok2 || !strings.HasSuffix(args[1], "%") || !strings.HasSuffix(args[2], "%") {
+		return c, false
+	}
+	return NewColorHSL(h, s, l), true
+}
+
+// RGB returns the red, green and blue components.
+func (c Color16m) RGB() (r, g, b byte) { return c.clr[0], c.clr[1], c.clr[2] }
+
+// Hex returns the color in "#rrggbb".
+func (c Color16m) Hex() string {
+	return fmt.Sprintf("#%02x%02x%02x", c.clr[0], c.clr[1], c.clr[2])
+}
+
+// IsBg reports whether c is a background color.
+func (c Color16m) IsBg() bool { return c.bg }
+
+// AsBg returns c as a background color.
+func (c Color16m) AsBg() Color16m { c.bg = true; return c }
+
+// AsFg returns c as a foreground color.
+func (c Color16m) AsFg() Color16m { c.bg = false; return c }
+
+// with returns a color of rgb, which is a background color if c
+// is.
+func (c Color16m) with(rgb [3]byte) Color16m {
+	return Color16m{clr: [4]byte{rgb[0], rgb[1], rgb[2]}, bg: c.bg}
+}
+
+func (c Color16m) rgb() [3]byte { return [3]byte{c.clr[0], c.clr[1], c.clr[2]} }
+
+// toByte rounds and clamps v in 0..1 to a byte.
+func toByte(v float64) byte {
+	return byte(math.Round(clamp01(v) * 255))
+}
+
+// HSL returns the hue (0..360), saturation and lightness (0..1).
+func (c Color16m) HSL() (h, s, l float64) {
+	r, g, b := float64(c.clr[0])/255, float64(c.clr[1])/255, float64(c.clr[2])/255
+	hi, lo := math.Max(r, math.Max(g, b)), math.Min(r, math.Min(g, b))
+	l = (hi + lo) / 2
+	if d := hi - lo; d > 0 {
+		s = d / (1 - math.Abs(2*l-1))
+		h = hue(r, g, b, hi, d)
+	}
+	return
+}
+
+// HSV returns the hue (0..360), saturation and value (0..1).
+func (c Color16m) HSV() (h, s, v float64) {
+	r, g, b := float64(c.clr[0])/255, float64(c.clr[1])/255, float64(c.clr[2])/255
+	v = math.Max(r, math.Max(g, b))
+	if d := v - math.Min(r, math.Min(g, b)); d > 0 {
+		s = d / v
+		h = hue(r, g, b, v, d)
+	}
+	return
+}
+
+// hue returns the hue in degrees, hi is the max component and d
+// is the chroma.
+func hue(r, g, b, hi, d float64) (h float64) {
+	switch hi {
+	case r:
+		h = math.Mod((g-b)/d, 6)
+	case g:
+		h = (b-r)/d + 2
+	default:
+		h = (r-g)/d + 4
+	}
+	if h *= 60; h < 0 {
+		h += 360
+	}
+	return
+}
+
+// fromHue returns the rgb of the hue h, chroma and the offset m.
+func fromHue(h, chroma, m float64) [3]byte {
+	h = math.Mod(math.Mod(h, 360)+360, 360) / 60
+	x := chroma * (1 - math.Abs(math.Mod(h, 2)-1))
+	var r, g, b float64
+	switch int(h) {
+	case 0:
+		r, g = chroma, x
+	case 1:
+		r, g = x, chroma
+	case 2:
+		g, b = chroma, x
+	case 3:
+		g, b = x, chroma
+	case 4:
+		r, b = x, chroma
+	default:
+		r, b = chroma, x
+	}
+	return [3]byte{toByte(r + m), toByte(g + m), toByte(b + m)}
+}
+
+// NewColorHSL returns the foreground color of the hue h (in
+// degrees), saturation s and lightness l (0..1).
+func NewColorHSL(h, s, l float64) Color16m {
+	s, l = clamp01(s), clamp01(l)
+	chroma := (1 - math.Abs(2*l-1)) * s
+	return Color16m{}.with(fromHue(h, chroma, l-chroma/2))
+}
+
+// NewColorHSV returns the foreground color of the hue h (in
+// degrees), saturation s and value v (0..1).
+func NewColorHSV(h, s, v float64) Color16m {
+	s, v = clamp01(s), clamp01(v)
+	chroma := v * s
+	return Color16m{}.with(fromHue(h, chroma, v-chroma))
+}
+
+// OKLab returns the color in the OKLab color space, the lightness
+// l is in 0..1.
+func (c Color16m) OKLab() (l, a, b float64) {
+	lab := toOKLab(c.rgb())
+	return lab.l, lab.a, lab.b
+}
+
+// NewColorOKLab returns the foreground color of an OKLab color,
+// which is clipped into the sRGB gamut.
+func NewColorOKLab(l, a, b float64) Color16m {
+	return Color16m{}.with(oklab{l, a, b}.rgb())
+}
+
+// rgb converts c into sRGB.
+func (c oklab) rgb() [3]byte {
+	l := math.Pow(c.l+0.3963377774*c.a+0.2158037573*c.b, 3)
+	m := math.Pow(c.l-0.1055613458*c.a-0.0638541728*c.b, 3)
+	s := math.Pow(c.l-0.0894841775*c.a-1.2914855480*c.b, 3)
+	return [3]byte{
+		gammaRGB(4.0767416621*l - 3.3077115913*m + 0.2309699292*s),
+		gammaRGB(-1.2684380046*l + 2.6097574011*m - 0.3413193965*s),
+		gammaRGB(-0.0041960863*l - 0.7034186147*m + 1.7076147010*s),
+	}
+}
+
+// gammaRGB converts a linear component to the sRGB one, see
+// [linearRGB].
+func gammaRGB(v float64) byte {
+	if v <= 0.0031308 {
+		return toByte(12.92 * v)
+	}
+	return toByte(1.055*math.Pow(v, 1/2.4) - 0.055)
+}
+
+func clamp01(v float64) float64 { return math.Max(0, math.Min(v, 1)) }
+
+// Lighten increases the lightness (HSL) by amount (0..1).
+func (c Color16m) Lighten(amount float64) Color16m {
+	h, s, l := c.HSL()
+	return c.with(NewColorHSL(h, s, l+amount).rgb())
+}
+
+// Darken decreases the lightness (HSL) by amount (0..1).
+func (c Color16m) Darken(amount float64) Color16m { return c.Lighten(-amount) }
+
+// Saturate increases the saturation (HSL) by amount (0..1).
+func (c Color16m) Saturate(amount float64) Color16m {
+	h, s, l := c.HSL()
+	return c.with(NewColorHSL(h, s+amount, l).rgb())
+}
+
+// Desaturate decreases the saturation (HSL) by amount (0..1).
+func (c Color16m) Desaturate(amount float64) Color16m { return c.Saturate(-amount) }
+
+// Mix returns the color between c (t=0) and o (t=1), which is
+// interpolated in OKLab, so the middle colors are perceptually
+// even.
+func (c Color16m) Mix(o Color16m, t float64) Color16m {
+	t = clamp01(t)
+	a, b := toOKLab(c.rgb()), toOKLab(o.rgb())
+	return c.with(oklab{
+		l: a.l + (b.l-a.l)*t,
+		a: a.a + (b.a-a.a)*t,
+		b: a.b + (b.b-a.b)*t,
+	}.rgb())
+}
+
+// Gradient returns n colors evenly spaced across the stops, from
+// the first to the last one. For example:
+//
+//	red, _ := color.ParseRGB("red")
+//	blue, _ := color.ParseRGB("blue")
+//	colors := color.Gradient(10, red, blue)
+func Gradient(n int, stops ...Color16m) []Color16m {
+	if n <= 0 || len(stops) == 0 {
+		return nil
+	}
+	ret := make([]Color16m, n)
+	for i := range ret {
+		if n == 1 || len(stops) == 1 {
+			ret[i] = stops[0]
+			continue
+		}
+		pos := float64(i) / float64(n-1) * float64(len(stops)-1)
+		k := min(int(pos), len(stops)-2)
+		ret[i] = stops[k].Mix(stops[k+1], pos-float64(k))
+	}
+	return ret
+}
+
+// Luminance returns the relative luminance of WCAG 2, 0 for black
+// and 1 for white.
+func (c Color16m) Luminance() float64 { return relativeLuminance(c.rgb()) }
+
+// Contrast returns the WCAG 2 contrast ratio of c and o, from 1
+// to 21. The ratio of the normal text to its background should
+// be at least 4.5 (AA) or 7 (AAA).
+func (c Color16m) Contrast(o Color16m) float64 {
+	l1, l2 := c.Luminance(), o.Luminance()
+	if l1 < l2 {
+		l1, l2 = l2, l1
+	}
+	return (l1 + 0.05) / (l2 + 0.05)
+}
+
+// ReadableOn returns the foreground color of the candidates which
+// has the highest contrast ratio to the background bg, the
+// candidates are black and white if none is given.
+func ReadableOn(bg Color16m, candidates ...Color16m) Color16m {
+	if len(candidates) == 0 {
+		candidates = []Color16m{{}, {clr: [4]byte{255, 255, 255}}}
+	}
+	best, ratio := candidates[0], -1.0
+	for _, c := range candidates {
+		if r := c.Contrast(bg); r > ratio {
+			best, ratio = c, r
+		}
+	}
+	return best.AsFg()
+}
+
+// GradientText paints each character of s in the gradient across
+// the stops, see [Gradient]. The spaces and the escape sequences
+// in s are kept. s is returned as is in no-color mode.
+//
+//	fmt.Println(color.GradientText("Hello, world!", red, yellow, green))
+func GradientText(s string, stops ...Color16m) string {
+	if states.Env().IsNoColorMode() || len(stops) == 0 || s == "" {
+		return s
+	}
+	n := 0
+	for i := 0; i < len(s); {
+		m, _, esc := nextToken(s[i:])
+		if !esc && s[i] != ' ' && s[i] != '\n' {
+			n++
+		}
+		i += m
+	}
+	colors := Gradient(n, stops...)
+
+	var sb strings.Builder
+	k := 0
+	for i := 0; i < len(s); {
+		m, _, esc := nextToken(s[i:])
+		if !esc && s[i] != ' ' && s[i] != '\n' && k < len(colors) {
+			_, _ = sb.WriteString(colors[k].AsFg().Color())
+			k++
+		}
+		_, _ = sb.WriteString(s[i : i+m])
+		i += m
+	}
+	if k > 0 {
+		_, _ = sb.WriteString(ResetToNormalColor.Color())
+	}
+	return sb.String()
+}
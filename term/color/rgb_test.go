@@ -0,0 +1,147 @@
+package color
+
+import (
+	"math"
+	"testing"
+)
+
+func TestParseRGB(t *testing.T) {
+	for _, c := range []struct {
+		spec, want string
+	}{
+		{"#f80", "#ff8800"},
+		{"#FF8700", "#ff8700"},
+		{"rgb(255, 135, 0)", "#ff8700"},
+		{"rgb(100% 50% 0% / 0.5)", "#ff8000"},
+		{"rgba(1,2,3,0.5)", "#010203"},
+		{"hsl(30, 100%, 50%)", "#ff8000"},
+		{"hsl(390deg 100% 50%)", "#ff8000"},
+		{"rgb:ff/87/00", "#ff8700"},
+		{"Orange", "#ffa500"},
+		{"light-blue", "#add8e6"},
+		{"hsl(30, 1, 0.5)", ""},
+		{"rgb(1, 2)", ""},
+		{"#12345", ""},
+		{"nope", ""},
+	} {
+		got, err := ParseRGB(c.spec)
+		switch {
+		case c.want == "" && err == nil:
+			t.Errorf("%q: want an error, got %s", c.spec, got.Hex())
+		case c.want != "" && err != nil:
+			t.Errorf("%q: %v", c.spec, err)
+		case c.want != "" && got.Hex() != c.want:
+			t.Errorf("%q: got %s, want %s", c.spec, got.Hex(), c.want)
+		}
+	}
+}
+
+func TestColor16mSpaces(t *testing.T) {
+	for _, spec := range []string{"#3366cc", "#000000", "#ffffff", "#ff0000", "#0a0b0c", "#808000"} {
+		c, _ := ParseRGB(spec)
+		if got := NewColorHSL(c.HSL()).Hex(); got != spec {
+			t.Errorf("%s: HSL round trip %s", spec, got)
+		}
+		if got := NewColorHSV(c.HSV()).Hex(); got != spec {
+			t.Errorf("%s: HSV round trip %s", spec, got)
+		}
+		if got := NewColorOKLab(c.OKLab()).Hex(); got != spec {
+			t.Errorf("%s: OKLab round trip %s", spec, got)
+		}
+	}
+
+	c, _ := ParseRGB("#3366cc")
+	if h, s, l := c.HSL(); math.Abs(h-220) > 1e-9 || math.Abs(s-0.6) > 1e-9 || math.Abs(l-0.5) > 1e-9 {
+		t.Errorf("HSL: %v %v %v", h, s, l)
+	}
+	if h, s, v := c.HSV(); math.Abs(h-220) > 1e-9 || math.Abs(s-0.75) > 1e-9 || math.Abs(v-0.8) > 1e-9 {
+		t.Errorf("HSV: %v %v %v", h, s, v)
+	}
+}
+
+func TestColor16mAdjust(t *testing.T) {
+	c, _ := ParseRGB("#3366cc")
+	for _, tc := range []struct {
+		got  Color16m
+		want string
+	}{
+		{c.Lighten(0.2), "#85a3e0"},
+		{c.Darken(0.2), "#1f3d7a"},
+		{c.Darken(1), "#000000"},
+		{c.Saturate(0.2), "#195ee6"},
+		{c.Desaturate(1), "#808080"},
+		{c.Mix(c, 0.5), "#3366cc"},
+	} {
+		if got := tc.got.Hex(); got != tc.want {
+			t.Errorf("got %s, want %s", got, tc.want)
+		}
+	}
+	if !c.AsBg().Lighten(0.1).IsBg() {
+		t.Error("the background flag is lost")
+	}
+}
+
+func TestGradient(t *testing.T) {
+	black, white := Color16m{}, Color16m{clr: [4]byte{255, 255, 255}}
+	red, _ := ParseRGB("red")
+	var got []string
+	for _, c := range Gradient(5, black, white) {
+		got = append(got, c.Hex())
+	}
+	want := []string{"#000000", "#222222", "#636363", "#aeaeae", "#ffffff"}
+	if len(got) != len(want) {
+		t.Fatalf("got %v", got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("got %v, want %v", got, want)
+			break
+		}
+	}
+	if g := Gradient(3, black, red, white); g[1] != red {
+		t.Errorf("the middle stop: %s", g[1].Hex())
+	}
+	if g := Gradient(2, red); len(g) != 2 || g[1] != red {
+		t.Errorf("a single stop: %v", g)
+	}
+	if Gradient(0, red) != nil || Gradient(3) != nil {
+		t.Error("want nil")
+	}
+}
+
+func TestContrast(t *testing.T) {
+	black, white := Color16m{}, Color16m{clr: [4]byte{255, 255, 255}}
+	if r := black.Contrast(white); math.Abs(r-21) > 1e-9 {
+		t.Errorf("black/white: %v", r)
+	}
+	if r := white.Contrast(white); r != 1 {
+		t.Errorf("white/white: %v", r)
+	}
+	navy, _ := ParseRGB("navy")
+	yellow, _ := ParseRGB("yellow")
+	for _, c := range []struct {
+		bg, want   Color16m
+		candidates []Color16m
+	}{
+		{navy, white, nil},
+		{yellow, black, nil},
+		{navy.AsBg(), yellow, []Color16m{navy, yellow}},
+	} {
+		if got := ReadableOn(c.bg, c.candidates...); got != c.want {
+			t.Errorf("on %s: got %s, want %s", c.bg.Hex(), got.Hex(), c.want.Hex())
+		}
+	}
+}
+
+func TestGradientText(t *testing.T) {
+	red, _ := ParseRGB("red")
+	blue, _ := ParseRGB("blue")
+	got := GradientText("ab c", red, blue.AsBg())
+	want := "\x1b[38;2;255;0;0ma\x1b[38;2;140;83;162mb \x1b[38;2;0;0;255mc\x1b[0m"
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if got := GradientText("x"); got != "x" {
+		t.Errorf("no stops: %q", got)
+	}
+}
@@ -0,0 +1,289 @@
+package color
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/hedzr/is/basics"
+	"github.com/hedzr/is/term"
+	"github.com/hedzr/is/term/chk"
+)
+
+// Screen is a full-screen session on the alternate screen
+// buffer, it's fit for the small dashboards.
+//
+// A Screen enters the alternate screen buffer (DECSET 1049),
+// switches the terminal into raw mode, hides the cursor and
+// tracks the terminal size. Everything will be restored by
+// [Screen.Close], which is safe to be called many times.
+//
+// A Screen is a [basics.Peripheral] and a [basics.Openable],
+// so it can be managed by [basics.Catch]. The terminal will
+// always be restored after SIGINT/SIGTERM caught:
+//
+//	scr := color.NewScreen()
+//	is.Signals().Catch().
+//	  WithPeripherals(scr).
+//	  WaitFor(ctx, func(ctx context.Context, closer func()) {
+//	    defer closer()
+//	    cols, rows := scr.Size()
+//	    scr.Clear()
+//	    scr.MoveTo(1, 1).Printf("size: %dx%d", cols, rows)
+//	    ...
+//	  })
+//
+// Or use [Screen.Run] to restore the terminal on return or
+// panic:
+//
+//	err := color.NewScreen().Run(ctx, func(ctx context.Context, scr *color.Screen) error {
+//	  ...
+//	})
+//
+// Note that Ctrl-C doesn't raise SIGINT in raw mode, read it
+// from stdin if you need it, or disable raw mode by
+// [WithScreenRawMode](false).
+type Screen struct {
+	w          Writer
+	raw        bool
+	hideCursor bool
+	onResize   []func(cols, rows int)
+
+	mu         sync.RWMutex
+	opened     bool
+	registered bool
+	cols, rows int
+	restoreRaw func()
+	stopWatch  func()
+}
+
+// ScreenOpt is a functional option for [NewScreen].
+type ScreenOpt func(s *Screen)
+
+// WithScreenWriter sets the output device, default is os.Stdout.
+func WithScreenWriter(w Writer) ScreenOpt {
+	return func(s *Screen) {
+		s.w = w
+	}
+}
+
+// WithScreenRawMode enables or disables the raw mode, default is true.
+func WithScreenRawMode(raw bool) ScreenOpt {
+	return func(s *Screen) {
+		s.raw = raw
+	}
+}
+
+// WithScreenHideCursor hides the cursor while the screen is
+// opened, default is true.
+func WithScreenHideCursor(hide bool) ScreenOpt {
+	return func(s *Screen) {
+		s.hideCursor = hide
+	}
+}
+
+// WithScreenOnResize adds a callback which will be invoked
+// with the new size after the terminal resized.
+func WithScreenOnResize(cb func(cols, rows int)) ScreenOpt {
+	return func(s *Screen) {
+		if cb != nil {
+			s.onResize = append(s.onResize, cb)
+		}
+	}
+}
+
+// NewScreen returns a new Screen, call [Screen.Open] to
+// enter it.
+func NewScreen(opts ...ScreenOpt) *Screen {
+	s := &Screen{
+		w:          os.Stdout,
+		raw:        true,
+		hideCursor: true,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Open enters the alternate screen buffer and the raw mode, the
+// raw mode is set on the terminal of the output device.
+//
+// Open registers the screen to [basics.RegisterPeripheral]
+// so that [basics.Close] restores the terminal too, and
+// [Screen.Close] removes it.
+func (s *Screen) Open(ctx context.Context) (err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.opened {
+		return
+	}
+
+	tty := chk.IsTty(s.w)
+	if s.raw && tty {
+		if s.restoreRaw, err = term.MakeRawWrappedFd(int(s.w.Fd())); err != nil {
+			s.restoreRaw = nil
+			return
+		}
+	}
+
+	if !s.registered {
+		s.registered = true
+		basics.RegisterPeripheral(s)
+	}
+
+	s.opened = true
+	s.write(capSeq("smcup", aecAltScreenOn))
+	if s.hideCursor {
+		s.write(capSeq("civis", aecHideCursor))
+	}
+	s.write(csiSeq('H', 1, 1, csi+"H") + csiSeq('J', 2, 0, csi+"2J"))
+
+	s.cols, s.rows = s.querySize()
+	if tty {
+		s.stopWatch = watchResize(ctx, s.resized)
+	}
+	return
+}
+
+// Close leaves the alternate screen buffer, restores the
+// cursor and the terminal mode.
+func (s *Screen) Close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.opened {
+		return
+	}
+	s.opened = false
+	if s.registered {
+		s.registered = false
+		basics.UnregisterPeripheral(s)
+	}
+
+	if s.stopWatch != nil {
+		s.stopWatch()
+		s.stopWatch = nil
+	}
+	if s.hideCursor {
+		s.write(capSeq("cnorm", aecShowCursor))
+	}
+	s.write(capSeq("rmcup", aecAltScreenOff))
+	if s.restoreRaw != nil {
+		s.restoreRaw()
+		s.restoreRaw = nil
+	}
+}
+
+// Run opens the screen, runs fn and closes the screen at last.
+//
+// The terminal will be restored even if fn panics, and the
+// panic will be raised again after restored.
+func (s *Screen) Run(ctx context.Context, fn func(ctx context.Context, s *Screen) error) (err error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if err = s.Open(ctx); err != nil {
+		return
+	}
+	defer func() {
+		if e := recover(); e != nil {
+			s.Close()
+			panic(e)
+		}
+		s.Close()
+	}()
+	return fn(ctx, s)
+}
+
+// IsOpened reports whether the screen is opened.
+func (s *Screen) IsOpened() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.opened
+}
+
+// Size returns the current size of the terminal.
+func (s *Screen) Size() (cols, rows int) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.cols == 0 && s.rows == 0 {
+		return s.querySize()
+	}
+	return s.cols, s.rows
+}
+
+// Clear erases the whole screen and moves the cursor to
+// the top-left corner.
+func (s *Screen) Clear() *Screen {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.write(csiSeq('H', 1, 1, csi+"H") + csiSeq('J', 2, 0, csi+"2J"))
+	return s
+}
+
+// MoveTo moves the cursor to col, row (1-based), like
+// [Cursor.MoveTo].
+func (s *Screen) MoveTo(col, row int) *Screen {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.write(csiSeq('H', row, col, ecmaSeq('H', row, col)))
+	return s
+}
+
+// Write writes p to the screen directly.
+func (s *Screen) Write(p []byte) (n int, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.w.Write(p)
+}
+
+// Printf writes a formatted string to the screen at the
+// current cursor position.
+func (s *Screen) Printf(format string, args ...any) *Screen {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.write(fmt.Sprintf(format, args...))
+	return s
+}
+
+// Fd returns the file descriptor of the output device, so
+// that a Screen can be used as a [Writer].
+func (s *Screen) Fd() uintptr { return s.w.Fd() }
+
+// NewGrid returns a [Grid] covers the whole screen, it should be
+// re-created or resized after the terminal resized.
+func (s *Screen) NewGrid() *Grid {
+	cols, rows := s.Size()
+	return NewGrid(cols, rows).WithWriter(s).SetOrigin(1, 1)
+}
+
+// Cursor returns a [Cursor] bound to the screen output.
+func (s *Screen) Cursor() *Cursor { return New().WithWriter(s.w) }
+
+func (s *Screen) write(str string) {
+	if str != "" {
+		_, _ = s.w.Write([]byte(str))
+	}
+}
+
+func (s *Screen) querySize() (cols, rows int) {
+	cols, rows, _ = term.GetTtySizeByFd(s.w.Fd())
+	return
+}
+
+func (s *Screen) resized() {
+	cols, rows := s.querySize()
+
+	s.mu.Lock()
+	changed := cols != s.cols || rows != s.rows
+	s.cols, s.rows = cols, rows
+	cbs := s.onResize
+	s.mu.Unlock()
+
+	if changed {
+		for _, cb := range cbs {
+			cb(cols, rows)
+		}
+	}
+}
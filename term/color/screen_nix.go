@@ -0,0 +1,34 @@
+//go:build !windows && !plan9 && !appengine && !wasm
+// +build !windows,!plan9,!appengine,!wasm
+
+package color
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// watchResize invokes cb after SIGWINCH caught, till ctx
+// done or the returned stop func called.
+func watchResize(ctx context.Context, cb func()) (stop func()) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, syscall.SIGWINCH)
+	go func() {
+		defer signal.Stop(ch)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ch:
+				cb()
+			}
+		}
+	}()
+	return cancel
+}
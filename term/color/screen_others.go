@@ -0,0 +1,32 @@
+//go:build windows || plan9 || appengine || wasm
+// +build windows plan9 appengine wasm
+
+package color
+
+import (
+	"context"
+	"time"
+)
+
+// watchResize polls the terminal size since there is no
+// SIGWINCH, cb will be invoked periodically, till ctx done
+// or the returned stop func called.
+func watchResize(ctx context.Context, cb func()) (stop func()) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	go func() {
+		ticker := time.NewTicker(250 * time.Millisecond)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				cb()
+			}
+		}
+	}()
+	return cancel
+}
@@ -0,0 +1,55 @@
+package color
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/hedzr/is/basics"
+)
+
+func TestScreenRun(t *testing.T) {
+	withTerminfo(t, "")
+
+	f, err := os.CreateTemp(t.TempDir(), "screen")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	scr := NewScreen(WithScreenWriter(f))
+	func() {
+		defer func() {
+			if e := recover(); e == nil {
+				t.Error("want the panic raised again")
+			}
+		}()
+		_ = scr.Run(context.TODO(), func(ctx context.Context, s *Screen) error {
+			if !s.IsOpened() {
+				t.Error("screen should be opened")
+			}
+			s.MoveTo(3, 2).Printf("hello")
+			panic("boom")
+		})
+	}()
+	if scr.IsOpened() {
+		t.Error("screen should be closed after panic")
+	}
+	scr.Close() // closing twice is ok
+	for _, p := range basics.ClosersClosers() {
+		if p == basics.Peripheral(scr) {
+			t.Error("the closed screen should be unregistered")
+		}
+	}
+
+	data, _ := os.ReadFile(f.Name())
+	got := string(data)
+	want := aecAltScreenOn + aecHideCursor + "\x1b[H\x1b[2J\x1b[2;3Hhello" + aecShowCursor + aecAltScreenOff
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if strings.Count(got, aecAltScreenOff) != 1 {
+		t.Errorf("the screen should be restored once")
+	}
+}
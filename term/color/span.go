@@ -0,0 +1,321 @@
+package color
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+)
+
+// UnderlineStyle is the shape of the underline, set by the
+// SGR 4:n sub-parameters.
+type UnderlineStyle int
+
+const (
+	UnderlineNone UnderlineStyle = iota
+	UnderlineSingle
+	UnderlineDouble
+	UnderlineCurly
+	UnderlineDotted
+	UnderlineDashed
+)
+
+// SpanStyle is the effective style of a [Span].
+//
+// A nil Fg/Bg/UnderlineColor means the default color of the
+// terminal. The colors are [Color16] (such as [FgRed] and
+// [BgBlue]), [Color256] or [Color16m] values.
+type SpanStyle struct {
+	Fg             Color
+	Bg             Color
+	Attrs          Attr // AttrUnderline is set if Underline is not UnderlineNone
+	Underline      UnderlineStyle
+	UnderlineColor Color
+	Link           string // the URI of the OSC 8 hyperlink
+}
+
+// IsZero reports whether st is the default style.
+func (st SpanStyle) IsZero() bool { return st.Equal(SpanStyle{}) }
+
+// Equal reports whether two styles are the same.
+func (st SpanStyle) Equal(o SpanStyle) bool {
+	return st.Attrs == o.Attrs && st.Underline == o.Underline && st.Link == o.Link &&
+		sameColor(st.Fg, o.Fg) && sameColor(st.Bg, o.Bg) &&
+		sameColor(st.UnderlineColor, o.UnderlineColor)
+}
+
+// Span is a piece of text in the same style.
+type Span struct {
+	Text  string
+	Style SpanStyle
+}
+
+// Spans is the styled text parsed by [ParseANSI].
+type Spans []Span
+
+// ParseANSI parses the text containing the escape sequences into
+// the spans, such as the output of a command captured by
+// exec.New().WithStdoutCaught(...).
+//
+// The SGR sequences (including the 256 and true colors, in both
+// the ';' and ':' forms, the underline styles and colors) and the
+// OSC 8 hyperlinks build the styles of the following text. The
+// other escape sequences (the cursor movements, the titles, ...)
+// are dropped. The adjacent spans in the same style are merged.
+func ParseANSI(s string) (spans Spans) {
+	var st SpanStyle
+	var text strings.Builder
+	flush := func() {
+		if text.Len() == 0 {
+			return
+		}
+		if n := len(spans); n > 0 && spans[n-1].Style.Equal(st) {
+			spans[n-1].Text += text.String()
+		} else {
+			spans = append(spans, Span{Text: text.String(), Style: st})
+		}
+		text.Reset()
+	}
+	for i := 0; i < len(s); {
+		n := escapeLen(s[i:])
+		if n == 0 {
+			n = 1
+			for i+n < len(s) && s[i+n] != '\x1b' {
+				n++
+			}
+			_, _ = text.WriteString(s[i : i+n])
+			i += n
+			continue
+		}
+		seq := s[i : i+n]
+		i += n
+		next := st
+		switch {
+		case isSGR(seq):
+			next.applySGR(seq[2 : len(seq)-1])
+		case strings.HasPrefix(seq, "\x1b]8;"):
+			next.Link = parseOSC8(seq)
+		default:
+			continue
+		}
+		if !next.Equal(st) {
+			flush()
+			st = next
+		}
+	}
+	flush()
+	return
+}
+
+// parseOSC8 returns the URI of an OSC 8 sequence, which is empty
+// for the closing one.
+func parseOSC8(seq string) string {
+	body := strings.TrimSuffix(strings.TrimSuffix(seq, "\x1b\\"), "\a")[4:]
+	if i := strings.IndexByte(body, ';'); i >= 0 {
+		return body[i+1:]
+	}
+	return ""
+}
+
+// applySGR applies the parameters of an SGR sequence.
+func (st *SpanStyle) applySGR(params string) {
+	if params == "" {
+		*st = SpanStyle{Link: st.Link}
+		return
+	}
+	fields := strings.Split(params, ";")
+	for k := 0; k < len(fields); k++ {
+		sub := strings.Split(fields[k], ":")
+		code, err := strconv.Atoi(sub[0])
+		if err != nil && sub[0] != "" {
+			continue
+		}
+		switch {
+		case code == 0:
+			*st = SpanStyle{Link: st.Link}
+		case code == 1:
+			st.Attrs |= AttrBold
+		case code == 2:
+			st.Attrs |= AttrDim
+		case code == 3:
+			st.Attrs |= AttrItalic
+		case code == 4:
+			u := UnderlineSingle
+			if len(sub) > 1 {
+				n, _ := strconv.Atoi(sub[1])
+				u = UnderlineStyle(min(max(n, 0), int(UnderlineDashed)))
+			}
+			st.setUnderline(u)
+		case code == 5 || code == 6:
+			st.Attrs |= AttrBlink
+		case code == 7:
+			st.Attrs |= AttrReverse
+		case code == 8:
+			st.Attrs |= AttrHidden
+		case code == 9:
+			st.Attrs |= AttrStrike
+		case code == 51:
+			st.Attrs |= AttrFramed
+		case code == 53:
+			st.Attrs |= AttrOverline
+		case code == 21:
+			st.setUnderline(UnderlineDouble)
+		case code == 22:
+			st.Attrs &^= AttrBold | AttrDim
+		case code == 23:
+			st.Attrs &^= AttrItalic
+		case code == 24:
+			st.setUnderline(UnderlineNone)
+		case code == 25:
+			st.Attrs &^= AttrBlink
+		case code == 27:
+			st.Attrs &^= AttrReverse
+		case code == 28:
+			st.Attrs &^= AttrHidden
+		case code == 29:
+			st.Attrs &^= AttrStrike
+		case code == 54:
+			st.Attrs &^= AttrFramed
+		case code == 55:
+			st.Attrs &^= AttrOverline
+		case code >= 30 && code <= 37, code >= 90 && code <= 97:
+			st.Fg = Color16(code)
+		case code >= 40 && code <= 47, code >= 100 && code <= 107:
+			st.Bg = Color16(code)
+		case code == 39:
+			st.Fg = nil
+		case code == 49:
+			st.Bg = nil
+		case code == 59:
+			st.UnderlineColor = nil
+		case code == 38 || code == 48 || code == 58:
+			var clr Color
+			if len(sub) > 1 {
+				clr = extendedColor(sub[1:], code == 48, true)
+			} else {
+				var used int
+				clr, used = extendedColorFields(fields[k+1:], code == 48)
+				k += used
+			}
+			switch code {
+			case 38:
+				st.Fg = clr
+			case 48:
+				st.Bg = clr
+			default:
+				st.UnderlineColor = clr
+			}
+		}
+	}
+}
+
+func (st *SpanStyle) setUnderline(u UnderlineStyle) {
+	st.Underline = u
+	if u == UnderlineNone {
+		st.Attrs &^= AttrUnderline
+	} else {
+		st.Attrs |= AttrUnderline
+	}
+}
+
+// extendedColorFields parses the ';' form of an extended color,
+// such as "5;n" and "2;r;g;b", and returns the count of the
+// fields used.
+func extendedColorFields(fields []string, bg bool) (Color, int) {
+	if len(fields) == 0 {
+		return nil, 0
+	}
+	switch fields[0] {
+	case "5":
+		if len(fields) >= 2 {
+			return extendedColor(fields[:2], bg, false), 2
+		}
+	case "2":
+		if len(fields) >= 4 {
+			return extendedColor(fields[:4], bg, false), 4
+		}
+	}
+	return nil, len(fields)
+}
+
+// extendedColor parses the sub-parameters of an extended color,
+// the ':' form of true color may have a color space id before
+// r, g and b, such as "2::r:g:b".
+func extendedColor(sub []string, bg, colonForm bool) Color {
+	num := func(s string) byte {
+		n, _ := strconv.Atoi(s)
+		return byte(min(max(n, 0), 255))
+	}
+	switch sub[0] {
+	case "5":
+		if len(sub) >= 2 {
+			return Color256{clr: [4]byte{num(sub[1])}, bg: bg}
+		}
+	case "2":
+		rgb := sub[1:]
+		if colonForm && len(rgb) >= 4 {
+			rgb = rgb[1:] // skip the color space id
+		}
+		if len(rgb) >= 3 {
+			return Color16m{clr: [4]byte{num(rgb[0]), num(rgb[1]), num(rgb[2])}, bg: bg}
+		}
+	}
+	return nil
+}
+
+// Plain returns the text without any styles.
+func (spans Spans) Plain() string {
+	var sb strings.Builder
+	for _, sp := range spans {
+		_, _ = sb.WriteString(sp.Text)
+	}
+	return sb.String()
+}
+
+// String renders the spans back into the text with the minimal
+// escape sequences, the styles are closed at the end.
+func (spans Spans) String() string {
+	var buf bytes.Buffer
+	var pen SpanStyle
+	for _, sp := range spans {
+		writeSpanTransition(&buf, pen, sp.Style)
+		pen = sp.Style
+		_, _ = buf.WriteString(sp.Text)
+	}
+	writeSpanTransition(&buf, pen, SpanStyle{})
+	return buf.String()
+}
+
+// writeSpanTransition writes the sequences which change the
+// style from to to, see [writeSGRTransition].
+func writeSpanTransition(buf *bytes.Buffer, from, to SpanStyle) {
+	if from.Link != to.Link {
+		_, _ = buf.WriteString("\x1b]8;;" + to.Link + "\x1b\\")
+	}
+	fc := Cell{Fg: from.Fg, Bg: from.Bg, Attrs: from.Attrs}
+	tc := Cell{Fg: to.Fg, Bg: to.Bg, Attrs: to.Attrs}
+	if to.Underline > UnderlineSingle {
+		// the underline style is written below
+		fc.Attrs &^= AttrUnderline
+		tc.Attrs &^= AttrUnderline
+	}
+	if fc.Attrs&^tc.Attrs != 0 {
+		from = SpanStyle{} // writeSGRTransition restarts from scratch
+	}
+	writeSGRTransition(buf, fc, tc)
+	if from.Underline != to.Underline && to.Underline > UnderlineSingle {
+		_, _ = buf.WriteString(csi + "4:" + strconv.Itoa(int(to.Underline)) + "m")
+	} else if from.Underline > UnderlineSingle && to.Underline == UnderlineSingle {
+		_, _ = buf.WriteString(csi + "4m")
+	}
+	if !sameColor(from.UnderlineColor, to.UnderlineColor) {
+		switch c := to.UnderlineColor.(type) {
+		case nil:
+			_, _ = buf.WriteString(csi + "59m")
+		case Color256:
+			_, _ = buf.WriteString(csi + "58:5:" + strconv.Itoa(int(c.clr[0])) + "m")
+		case Color16m:
+			_, _ = buf.WriteString(csi + "58:2::" + strconv.Itoa(int(c.clr[0])) + ":" +
+				strconv.Itoa(int(c.clr[1])) + ":" + strconv.Itoa(int(c.clr[2])) + "m")
+		}
+	}
+}
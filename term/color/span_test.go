@@ -0,0 +1,72 @@
+package color
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseANSI(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Spans
+	}{
+		{"", nil},
+		{"plain", Spans{{Text: "plain"}}},
+		{"\x1b[31mred\x1b[0m ok", Spans{
+			{Text: "red", Style: SpanStyle{Fg: FgRed}},
+			{Text: " ok"},
+		}},
+		{"\x1b[1;3;44mx\x1b[22my", Spans{
+			{Text: "x", Style: SpanStyle{Bg: BgBlue, Attrs: AttrBold | AttrItalic}},
+			{Text: "y", Style: SpanStyle{Bg: BgBlue, Attrs: AttrItalic}},
+		}},
+		{"\x1b[38;5;208ma\x1b[48;2;1;2;3mb\x1b[39;49mc", Spans{
+			{Text: "a", Style: SpanStyle{Fg: Color256{clr: [4]byte{208}}}},
+			{Text: "b", Style: SpanStyle{Fg: Color256{clr: [4]byte{208}}, Bg: Color16m{clr: [4]byte{1, 2, 3}, bg: true}}},
+			{Text: "c"},
+		}},
+		{"\x1b[38:2::10:20:30mrgb\x1b[m", Spans{
+			{Text: "rgb", Style: SpanStyle{Fg: Color16m{clr: [4]byte{10, 20, 30}}}},
+		}},
+		{"\x1b[4:3;58:5:196mcurly\x1b[24;59m", Spans{
+			{Text: "curly", Style: SpanStyle{Attrs: AttrUnderline, Underline: UnderlineCurly,
+				UnderlineColor: Color256{clr: [4]byte{196}}}},
+		}},
+		{"\x1b[4mu\x1b[21md", Spans{
+			{Text: "u", Style: SpanStyle{Attrs: AttrUnderline, Underline: UnderlineSingle}},
+			{Text: "d", Style: SpanStyle{Attrs: AttrUnderline, Underline: UnderlineDouble}},
+		}},
+		{"\x1b]8;;https://example.com\x1b\\link\x1b]8;;\x1b\\", Spans{
+			{Text: "link", Style: SpanStyle{Link: "https://example.com"}},
+		}},
+		{"a\x1b[2K\x1b[1Ab\x1b[31m\x1b[0mc", Spans{{Text: "abc"}}},
+		{"\x1b[32mgo\x1b[32mod", Spans{{Text: "good", Style: SpanStyle{Fg: FgGreen}}}},
+	}
+	for i, tc := range tests {
+		if got := ParseANSI(tc.in); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%d. ParseANSI(%q) =\n%+v\nwant\n%+v", i, tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestSpansString(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"plain", "plain"},
+		{"\x1b[31mred\x1b[0m ok", "\x1b[31mred\x1b[39m ok"},
+		{"\x1b[1mb\x1b[22m", "\x1b[1mb\x1b[0m"},
+		{"\x1b[4:3mc\x1b[0m", "\x1b[4:3mc\x1b[0m"},
+		{"\x1b]8;;u\ax\x1b]8;;\a", "\x1b]8;;u\x1b\\x\x1b]8;;\x1b\\"},
+	}
+	for i, tc := range tests {
+		spans := ParseANSI(tc.in)
+		if got := spans.String(); got != tc.want {
+			t.Errorf("%d. String() = %q, want %q", i, got, tc.want)
+		}
+		if got, want := ParseANSI(spans.String()), spans; !reflect.DeepEqual(got, want) {
+			t.Errorf("%d. round trip = %+v, want %+v", i, got, want)
+		}
+	}
+}
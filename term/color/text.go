@@ -0,0 +1,428 @@
+package color
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// StringWidth returns the count of terminal columns occupied by
+// s. The escape sequences (SGR, CSI, OSC, ...) are zero-width,
+// the East Asian wide characters occupy two columns, and a
+// grapheme cluster (such as a letter with the combining marks,
+// an emoji ZWJ sequence or a flag) is measured as a whole.
+func StringWidth(s string) (width int) {
+	for i := 0; i < len(s); {
+		n, w, _ := nextToken(s[i:])
+		width += w
+		i += n
+	}
+	return
+}
+
+// Truncate cuts the end of s to fit in width columns, and appends
+// tail (such as "…") if s was cut. The escape sequences are kept,
+// and the active attributes are closed at the end.
+func Truncate(s string, width int, tail string) string {
+	if StringWidth(s) <= width {
+		return s
+	}
+	budget := width - StringWidth(tail)
+	if budget < 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	var st textState
+	used := 0
+	for i := 0; i < len(s); {
+		n, w, esc := nextToken(s[i:])
+		if esc {
+			st.update(s[i : i+n])
+		} else if used+w > budget {
+			break
+		}
+		used += w
+		_, _ = sb.WriteString(s[i : i+n])
+		i += n
+	}
+	_, _ = sb.WriteString(tail)
+	_, _ = sb.WriteString(st.closing())
+	return sb.String()
+}
+
+// TruncateMiddle cuts the middle of s to fit in width columns,
+// and puts mid (such as "…") there if s was cut. It's fit for
+// the paths and URLs, whose both ends are significant.
+func TruncateMiddle(s string, width int, mid string) string {
+	if StringWidth(s) <= width {
+		return s
+	}
+	budget := width - StringWidth(mid)
+	if budget < 0 {
+		return ""
+	}
+	tokens := tokenize(s)
+	right := budget / 2
+
+	// the tail part, tokens[j:], the unused columns are given to
+	// the head part
+	j, used := len(tokens), 0
+	for j > 0 {
+		t := tokens[j-1]
+		if !t.esc && used+t.width > right {
+			break
+		}
+		used += t.width
+		j--
+	}
+
+	left := budget - used
+
+	var sb strings.Builder
+	var st textState
+	used = 0
+	for _, t := range tokens[:j] {
+		if !t.esc && used+t.width > left {
+			break
+		}
+		st.update(t.text)
+		used += t.width
+		_, _ = sb.WriteString(t.text)
+	}
+	_, _ = sb.WriteString(st.closing())
+	_, _ = sb.WriteString(mid)
+
+	// restore the state at the beginning of the tail part
+	st = textState{}
+	for _, t := range tokens[:j] {
+		st.update(t.text)
+	}
+	_, _ = sb.WriteString(st.opening())
+	for _, t := range tokens[j:] {
+		_, _ = sb.WriteString(t.text)
+	}
+	return sb.String()
+}
+
+// PadRight appends the spaces to s to fill width columns.
+func PadRight(s string, width int) string {
+	return s + strings.Repeat(" ", max(width-StringWidth(s), 0))
+}
+
+// PadLeft prepends the spaces to s to fill width columns.
+func PadLeft(s string, width int) string {
+	return strings.Repeat(" ", max(width-StringWidth(s), 0)) + s
+}
+
+// Center puts s in the middle of width columns, the extra space
+// is at the right.
+func Center(s string, width int) string {
+	gap := max(width-StringWidth(s), 0)
+	return strings.Repeat(" ", gap/2) + s + strings.Repeat(" ", gap-gap/2)
+}
+
+// WordWrap breaks s into the lines fit in width columns, at the
+// spaces preferably. The words longer than width are broken
+// forcibly, the existing line breaks are kept.
+//
+// The active SGR attributes (and the OSC 8 hyperlink) are closed
+// at the end of a line and reopened at the start of the next
+// line, so that each line can be drawn alone, such as in a table
+// cell.
+func WordWrap(s string, width int) (lines []string) {
+	return wrap(s, width, false)
+}
+
+// HardWrap breaks s into the lines of exactly width columns
+// (except the last one and the ones ended by the existing line
+// breaks), regardless of the words. The active attributes are
+// carried as [WordWrap] does.
+func HardWrap(s string, width int) (lines []string) {
+	return wrap(s, width, true)
+}
+
+func wrap(s string, width int, hard bool) (lines []string) {
+	w := wrapper{width: max(width, 1), hard: hard}
+	for _, t := range tokenize(s) {
+		switch {
+		case t.text == "\n":
+			w.flushWord()
+			w.newline()
+		case hard:
+			w.put([]token{t})
+		case t.text == " " || t.text == "\t":
+			w.flushWord()
+			w.spaces = append(w.spaces, token{text: " ", width: 1})
+		default:
+			w.word = append(w.word, t)
+		}
+	}
+	w.flushWord()
+	w.newline()
+	return w.lines
+}
+
+// token is a grapheme cluster or an escape sequence.
+type token struct {
+	text  string
+	width int
+	esc   bool
+}
+
+func tokenize(s string) (tokens []token) {
+	for i := 0; i < len(s); {
+		n, w, esc := nextToken(s[i:])
+		tokens = append(tokens, token{text: s[i : i+n], width: w, esc: esc})
+		i += n
+	}
+	return
+}
+
+// nextToken returns the length and the width of the escape
+// sequence or the grapheme cluster at the beginning of s.
+func nextToken(s string) (n, width int, esc bool) {
+	if n = escapeLen(s); n > 0 {
+		return n, 0, true
+	}
+	n, width = clusterLen(s)
+	return
+}
+
+type wrapper struct {
+	width  int
+	hard   bool
+	lines  []string
+	line   strings.Builder
+	used   int // columns of line
+	word   []token
+	spaces []token
+	st     textState
+}
+
+func tokensWidth(tokens []token) (w int) {
+	for _, t := range tokens {
+		w += t.width
+	}
+	return
+}
+
+// flushWord puts the pending spaces and word into the line.
+func (w *wrapper) flushWord() {
+	if len(w.word) == 0 {
+		return
+	}
+	if w.used > 0 && w.used+tokensWidth(w.spaces)+tokensWidth(w.word) > w.width {
+		w.newline()
+	}
+	w.put(w.spaces)
+	w.spaces = w.spaces[:0]
+	w.put(w.word)
+	w.word = w.word[:0]
+}
+
+// put writes tokens into the line, and breaks it forcibly if full.
+func (w *wrapper) put(tokens []token) {
+	for _, t := range tokens {
+		if t.esc {
+			w.st.update(t.text)
+			_, _ = w.line.WriteString(t.text)
+			continue
+		}
+		if w.used > 0 && w.used+t.width > w.width {
+			w.newline()
+			if t.text == " " && !w.hard {
+				continue
+			}
+		}
+		_, _ = w.line.WriteString(t.text)
+		w.used += t.width
+	}
+}
+
+// newline ends the line, the trailing spaces are dropped.
+func (w *wrapper) newline() {
+	w.spaces = w.spaces[:0]
+	_, _ = w.line.WriteString(w.st.closing())
+	w.lines = append(w.lines, w.line.String())
+	w.line.Reset()
+	_, _ = w.line.WriteString(w.st.opening())
+	w.used = 0
+}
+
+// textState records the SGR sequences since the last reset, and
+// the open OSC 8 hyperlink.
+type textState struct {
+	sgr  []string
+	link string
+}
+
+func (st *textState) update(seq string) {
+	switch {
+	case isSGR(seq):
+		params := seq[2 : len(seq)-1]
+		if rest, ok := lastReset(params); ok {
+			st.sgr = st.sgr[:0]
+			if rest != "" {
+				st.sgr = append(st.sgr, csi+rest+"m")
+			}
+			return
+		}
+		st.sgr = append(st.sgr, seq)
+	case strings.HasPrefix(seq, "\x1b]8;"):
+		uri := strings.TrimRight(strings.TrimSuffix(seq, "\x1b\\"), "\a")
+		if i := strings.IndexByte(uri[4:], ';'); i >= 0 && uri[4+i+1:] != "" {
+			st.link = seq
+		} else {
+			st.link = ""
+		}
+	}
+}
+
+// lastReset returns the SGR parameters following the last reset
+// in params, and whether there is one. Only the empty params and a
+// standalone 0 are resets, the arguments of the extended colors,
+// such as 0 of "38;2;255;0;0", are skipped.
+func lastReset(params string) (rest string, ok bool) {
+	if params == "" {
+		return "", true
+	}
+	fields := strings.Split(params, ";")
+	for k := 0; k < len(fields); k++ {
+		switch fields[k] {
+		case "0":
+			rest, ok = strings.Join(fields[k+1:], ";"), true
+		case "38", "48", "58":
+			_, used := extendedColorFields(fields[k+1:], false)
+			k += used
+		}
+	}
+	return
+}
+
+// opening returns the sequences to restore the state.
+func (st *textState) opening() string { return st.link + strings.Join(st.sgr, "") }
+
+// closing returns the sequences to close the active attributes
+// and hyperlink.
+func (st *textState) closing() (s string) {
+	if len(st.sgr) > 0 {
+		s = "\x1b[0m"
+	}
+	if st.link != "" {
+		s += "\x1b]8;;\x1b\\"
+	}
+	return
+}
+
+func isSGR(seq string) bool {
+	return len(seq) >= 3 && seq[0] == '\x1b' && seq[1] == '[' && seq[len(seq)-1] == 'm'
+}
+
+// escapeLen returns the length of the escape sequence at the
+// beginning of s, or 0.
+func escapeLen(s string) int {
+	if len(s) < 2 || s[0] != '\x1b' {
+		return 0
+	}
+	switch s[1] {
+	case '[': // CSI: parameters, intermediates, and a final byte
+		for i := 2; i < len(s); i++ {
+			switch c := s[i]; {
+			case c >= 0x40 && c <= 0x7e:
+				return i + 1
+			case c < 0x20 || c > 0x7e: // malformed
+				return i
+			}
+		}
+		return len(s)
+	case ']', 'P', 'X', '^', '_': // OSC, DCS, ...: terminated by BEL or ST
+		for i := 2; i < len(s); i++ {
+			if s[i] == '\a' {
+				return i + 1
+			}
+			if s[i] == '\x1b' && i+1 < len(s) && s[i+1] == '\\' {
+				return i + 2
+			}
+		}
+		return len(s)
+	}
+	return 2
+}
+
+// scanEscapes calls fn with the runs of the text and the escape
+// sequences in s in order, till fn returns false. It returns the
+// length of the parts fn has returned true for.
+func scanEscapes(s string, fn func(part string, esc bool) bool) int {
+	for i := 0; i < len(s); {
+		n, esc := escapeLen(s[i:]), true
+		if n == 0 {
+			j := strings.IndexByte(s[i+1:], '\x1b')
+			if j < 0 {
+				j = len(s) - i - 1
+			}
+			n, esc = 1+j, false
+		}
+		if !fn(s[i:i+n], esc) {
+			return i
+		}
+		i += n
+	}
+	return len(s)
+}
+
+// clusterLen returns the length and the width of the grapheme
+// cluster at the beginning of s.
+//
+// It's a simplified segmentation which covers the combining
+// marks, the variation selectors, the emoji modifiers, tags and
+// ZWJ sequences, the regional indicator pairs (flags) and the
+// Hangul jamo.
+func clusterLen(s string) (n, width int) {
+	r, size := utf8.DecodeRuneInString(s)
+	n, width = size, runeWidth(r)
+	if isRegionalIndicator(r) {
+		if r2, size2 := utf8.DecodeRuneInString(s[n:]); isRegionalIndicator(r2) {
+			return n + size2, 2
+		}
+		return n, 1
+	}
+	for n < len(s) {
+		r2, size2 := utf8.DecodeRuneInString(s[n:])
+		switch {
+		case r2 == 0x200d: // ZWJ, joins the next one
+			n += size2
+			if n < len(s) {
+				_, size3 := utf8.DecodeRuneInString(s[n:])
+				n += size3
+			}
+		case r2 == 0xfe0f: // emoji presentation
+			n += size2
+			width = max(width, 2)
+		case r2 == 0xfe0e: // text presentation
+			n += size2
+			width = min(width, 1)
+		case isGraphemeExtend(r2):
+			n += size2
+		default:
+			return
+		}
+	}
+	return
+}
+
+func isRegionalIndicator(r rune) bool { return r >= 0x1f1e6 && r <= 0x1f1ff }
+
+// isGraphemeExtend reports whether r joins the preceding rune.
+func isGraphemeExtend(r rune) bool {
+	switch {
+	case r >= 0x1f3fb && r <= 0x1f3ff: // emoji modifiers (skin tones)
+		return true
+	case r >= 0xe0020 && r <= 0xe007f: // tags
+		return true
+	case r >= 0x1160 && r <= 0x11ff: // Hangul jamo vowels and finals
+		return true
+	case r == 0x200c: // ZWNJ
+		return true
+	}
+	return r >= 0x300 && runeWidth(r) == 0 && r != 0x200b && r != 0x2060 && r != 0xfeff
+}
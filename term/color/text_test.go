@@ -0,0 +1,158 @@
+package color
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestStringWidth(t *testing.T) {
+	tests := []struct {
+		in   string
+		want int
+	}{
+		{"", 0},
+		{"hello", 5},
+		{"中文", 4},
+		{"\x1b[1;31mred\x1b[0m", 3},
+		{"\x1b]8;;https://example.com\x1b\\link\x1b]8;;\x1b\\", 4},
+		{"\x1b]0;title\a", 0},
+		{"e\u0301", 1},  // combining acute
+		{"a\u200bb", 2}, // zero width space
+	}
+	for i, tc := range tests {
+		if got := StringWidth(tc.in); got != tc.want {
+			t.Errorf("%d. StringWidth(%q) = %d, want %d", i, tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		in    string
+		width int
+		want  string
+	}{
+		{"hello", 5, "hello"},
+		{"hello world", 8, "hello w…"},
+		{"中文字符", 5, "中文…"},
+		{"\x1b[31mhello world\x1b[0m", 6, "\x1b[31mhello…\x1b[0m"},
+		{"abc", 0, ""},
+	}
+	for i, tc := range tests {
+		if got := Truncate(tc.in, tc.width, "…"); got != tc.want {
+			t.Errorf("%d. Truncate(%q, %d) = %q, want %q", i, tc.in, tc.width, got, tc.want)
+		}
+	}
+}
+
+func TestWordWrap(t *testing.T) {
+	tests := []struct {
+		in    string
+		width int
+		want  []string
+	}{
+		{"", 5, []string{""}},
+		{"the quick brown fox", 10, []string{"the quick", "brown fox"}},
+		{"the quick\nbrown fox", 20, []string{"the quick", "brown fox"}},
+		{"abcdefghij", 4, []string{"abcd", "efgh", "ij"}},
+		{"中文中文中", 4, []string{"中文", "中文", "中"}},
+		{"a \x1b[1mbold text\x1b[0m end", 6, []string{"a \x1b[1mbold\x1b[0m", "\x1b[1mtext\x1b[0m", "end"}},
+		{"\x1b[38;2;255;0;0mhello world\x1b[0m", 6, []string{"\x1b[38;2;255;0;0mhello\x1b[0m", "\x1b[38;2;255;0;0mworld\x1b[0m"}},
+		{"\x1b[38;5;0mab cd\x1b[0m", 3, []string{"\x1b[38;5;0mab\x1b[0m", "\x1b[38;5;0mcd\x1b[0m"}},
+		{"\x1b[1mab \x1b[0;32mcd ef\x1b[0m", 3, []string{"\x1b[1mab\x1b[0m", "\x1b[1m\x1b[0;32mcd\x1b[0m", "\x1b[32mef\x1b[0m"}},
+	}
+	for i, tc := range tests {
+		if got := WordWrap(tc.in, tc.width); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%d. WordWrap(%q, %d) =\n%q\nwant\n%q", i, tc.in, tc.width, got, tc.want)
+		}
+	}
+}
+
+func TestStringWidthGraphemes(t *testing.T) {
+	tests := []struct {
+		in   string
+		want int
+	}{
+		{"🇨🇳", 2},
+		{"🇨🇳🇺🇸", 4},
+		{"👨‍👩‍👧", 2},
+		{"❤️", 2},
+		{"👍🏽", 2},
+		{"☺︎", 1},
+		{"한", 2},
+		{"한", 2},
+	}
+	for i, tc := range tests {
+		if got := StringWidth(tc.in); got != tc.want {
+			t.Errorf("%d. StringWidth(%q) = %d, want %d", i, tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestTruncateMiddle(t *testing.T) {
+	tests := []struct {
+		in    string
+		width int
+		want  string
+	}{
+		{"hello", 5, "hello"},
+		{"/usr/local/bin/tool", 9, "/usr…tool"},
+		{"中文中文中文", 7, "中文…文"},
+		{"\x1b[31mabcdefgh\x1b[0m", 5, "\x1b[31mab\x1b[0m…\x1b[31mgh\x1b[0m"},
+	}
+	for i, tc := range tests {
+		if got := TruncateMiddle(tc.in, tc.width, "…"); got != tc.want {
+			t.Errorf("%d. TruncateMiddle(%q, %d) = %q, want %q", i, tc.in, tc.width, got, tc.want)
+		}
+	}
+}
+
+func TestHardWrap(t *testing.T) {
+	tests := []struct {
+		in    string
+		width int
+		want  []string
+	}{
+		{"the quick brown", 6, []string{"the qu", "ick br", "own"}},
+		{"ab\ncdefg", 3, []string{"ab", "cde", "fg"}},
+		{"中文中", 3, []string{"中", "文", "中"}},
+		{"\x1b[1mabcd\x1b[0m", 2, []string{"\x1b[1mab\x1b[0m", "\x1b[1mcd\x1b[0m"}},
+	}
+	for i, tc := range tests {
+		if got := HardWrap(tc.in, tc.width); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%d. HardWrap(%q, %d) =\n%q\nwant\n%q", i, tc.in, tc.width, got, tc.want)
+		}
+	}
+}
+
+func TestWordWrapHyperlink(t *testing.T) {
+	in := "\x1b]8;;https://example.com\x1b\\click here\x1b]8;;\x1b\\ ok"
+	want := []string{
+		"\x1b]8;;https://example.com\x1b\\click\x1b]8;;\x1b\\",
+		"\x1b]8;;https://example.com\x1b\\here\x1b]8;;\x1b\\ ok",
+	}
+	if got := WordWrap(in, 7); !reflect.DeepEqual(got, want) {
+		t.Errorf("WordWrap =\n%q\nwant\n%q", got, want)
+	}
+}
+
+func TestPad(t *testing.T) {
+	tests := []struct {
+		fn    func(string, int) string
+		in    string
+		width int
+		want  string
+	}{
+		{PadRight, "ab", 4, "ab  "},
+		{PadRight, "中", 4, "中  "},
+		{PadRight, "abcde", 4, "abcde"},
+		{PadLeft, "\x1b[1mab\x1b[0m", 4, "  \x1b[1mab\x1b[0m"},
+		{Center, "ab", 5, " ab  "},
+		{Center, "中", 6, "  中  "},
+	}
+	for i, tc := range tests {
+		if got := tc.fn(tc.in, tc.width); got != tc.want {
+			t.Errorf("%d. pad(%q, %d) = %q, want %q", i, tc.in, tc.width, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,210 @@
+package color
+
+import (
+	"strconv"
+	"strings"
+)
+
+// TextStyle is a structured text style: the foreground, the
+// background and the underline colors, and the set of attributes.
+// Unlike a [Style], which appends the raw codes, a TextStyle knows
+// its state, so [Diff] can write the minimal transition from one
+// style to another.
+//
+// A nil Fg/Bg/UnderlineColor means the default color of the
+// terminal. The zero TextStyle is the default style.
+//
+//	st := color.TextStyle{}.WithFg(color.FgRed).With(color.AttrBold)
+//	fmt.Println(st.Wrap("failed"))
+type TextStyle struct {
+	Fg             Color
+	Bg             Color
+	UnderlineColor Color
+	Attrs          Attr
+	Underline      UnderlineStyle // the shape of AttrUnderline, UnderlineSingle if not set
+}
+
+// WithFg returns st in the foreground color c.
+func (st TextStyle) WithFg(c Color) TextStyle { st.Fg = c; return st }
+
+// WithBg returns st in the background color c.
+func (st TextStyle) WithBg(c Color) TextStyle { st.Bg = c; return st }
+
+// WithUnderlineColor returns st with the underline color c.
+func (st TextStyle) WithUnderlineColor(c Color) TextStyle { st.UnderlineColor = c; return st }
+
+// WithUnderline returns st underlined in the shape u, UnderlineNone
+// removes the underline.
+func (st TextStyle) WithUnderline(u UnderlineStyle) TextStyle {
+	if u == UnderlineNone {
+		return st.Without(AttrUnderline)
+	}
+	st.Attrs |= AttrUnderline
+	st.Underline = u
+	return st
+}
+
+// With returns st with the attributes added.
+func (st TextStyle) With(attrs Attr) TextStyle { st.Attrs |= attrs; return st }
+
+// Without returns st with the attributes removed.
+func (st TextStyle) Without(attrs Attr) TextStyle {
+	st.Attrs &^= attrs
+	if attrs&AttrUnderline != 0 {
+		st.Underline = UnderlineNone
+	}
+	return st
+}
+
+// Apply returns st changed by the SGR codes of clr, such as
+// [FgRed], [BgBoldOrBright], a [Style] or [UnderlineCurly]. [Reset]
+// returns the default style, nil returns st.
+func (st TextStyle) Apply(clr Color) TextStyle {
+	if clr == nil {
+		return st
+	}
+	s := sgrOf(clr)
+	for i := 0; i < len(s); {
+		n := max(escapeLen(s[i:]), 1)
+		if seq := s[i : i+n]; isSGR(seq) {
+			st = st.ApplySGR(seq[2 : len(seq)-1])
+		}
+		i += n
+	}
+	return st
+}
+
+// ApplySGR returns st changed by the parameters of an SGR
+// sequence, such as "1;31" and "4:3".
+func (st TextStyle) ApplySGR(params string) TextStyle {
+	sp := SpanStyle{Fg: st.Fg, Bg: st.Bg, Attrs: st.Attrs, Underline: st.underline(), UnderlineColor: st.UnderlineColor}
+	sp.applySGR(params)
+	return TextStyle{Fg: sp.Fg, Bg: sp.Bg, UnderlineColor: sp.UnderlineColor, Attrs: sp.Attrs, Underline: sp.Underline}
+}
+
+// underline returns the effective underline shape.
+func (st TextStyle) underline() UnderlineStyle {
+	switch {
+	case st.Attrs&AttrUnderline == 0:
+		return UnderlineNone
+	case st.Underline == UnderlineNone:
+		return UnderlineSingle
+	}
+	return st.Underline
+}
+
+// IsZero reports whether st is the default style.
+func (st TextStyle) IsZero() bool { return st.Equal(TextStyle{}) }
+
+// Equal reports whether two styles look the same.
+func (st TextStyle) Equal(o TextStyle) bool {
+	return st.Attrs == o.Attrs && st.underline() == o.underline() &&
+		sameColor(st.Fg, o.Fg) && sameColor(st.Bg, o.Bg) &&
+		sameUnderlineColor(st.UnderlineColor, o.UnderlineColor)
+}
+
+// sameUnderlineColor compares two underline colors by SGR 58, so
+// [FgRed] is the same as the 256 color 1.
+func sameUnderlineColor(a, b Color) bool {
+	if isDefaultColor(a) || isDefaultColor(b) {
+		return isDefaultColor(a) && isDefaultColor(b)
+	}
+	return NewUnderlineColor(a) == NewUnderlineColor(b)
+}
+
+// SGR returns the escape sequence which sets st from the default
+// style.
+func (st TextStyle) SGR() string { return Diff(TextStyle{}, st) }
+
+// String returns the escape sequence of st, see [TextStyle.SGR].
+func (st TextStyle) String() string { return st.SGR() }
+
+// Wrap returns text in st, followed by the transition back to the
+// default style.
+func (st TextStyle) Wrap(text string) string {
+	return st.SGR() + text + Diff(st, TextStyle{})
+}
+
+// Diff returns the shortest SGR sequence which changes the style
+// from to to: only the attributes turned off and on and the colors
+// changed are written, or a reset followed by to if that's shorter.
+// It's empty if the styles are the same.
+//
+//	bold := color.TextStyle{}.With(color.AttrBold)
+//	color.Diff(bold, bold.With(color.AttrItalic)) // "\x1b[3m"
+//	color.Diff(bold.With(color.AttrItalic), bold) // "\x1b[23m"
+func Diff(from, to TextStyle) string {
+	if from.Equal(to) {
+		return ""
+	}
+	codes := transition(from, to)
+	if full := append([]string{"0"}, transition(TextStyle{}, to)...); paramsLen(full) <= paramsLen(codes) {
+		codes = full
+	}
+	return csi + strings.Join(codes, ";") + "m"
+}
+
+// attrResetSGR maps an Attr bit to the SGR code which turns it
+// off, in bit order. 22 turns off both bold and dim.
+var attrResetSGR = [...]int{22, 22, 23, 24, 25, 27, 28, 29, 55, 54}
+
+// transition returns the SGR parameters changing from to to.
+func transition(from, to TextStyle) (codes []string) {
+	cur := from.Attrs
+	for i, code := range attrResetSGR {
+		bit := Attr(1 << i)
+		if from.Attrs&bit != 0 && to.Attrs&bit == 0 && cur&bit != 0 {
+			codes = append(codes, strconv.Itoa(code))
+			if code == 22 {
+				cur &^= AttrBold | AttrDim
+			} else {
+				cur &^= bit
+			}
+		}
+	}
+	for i, code := range attrSGR {
+		bit := Attr(1 << i)
+		switch {
+		case to.Attrs&bit == 0:
+		case bit == AttrUnderline:
+			if cur&bit == 0 || from.underline() != to.underline() {
+				codes = append(codes, string(to.underline().param()))
+			}
+		case cur&bit == 0:
+			codes = append(codes, strconv.Itoa(code))
+		}
+	}
+
+	if !sameColor(from.Fg, to.Fg) {
+		codes = append(codes, colorParams(to.Fg, "39"))
+	}
+	if !sameColor(from.Bg, to.Bg) {
+		codes = append(codes, colorParams(to.Bg, "49"))
+	}
+	if !sameUnderlineColor(from.UnderlineColor, to.UnderlineColor) {
+		if isDefaultColor(to.UnderlineColor) {
+			codes = append(codes, "59")
+		} else {
+			codes = append(codes, string(NewUnderlineColor(to.UnderlineColor)))
+		}
+	}
+	return
+}
+
+// colorParams returns the SGR parameters of clr, such as "31" and
+// "38;5;208", or def for the default color.
+func colorParams(clr Color, def string) string {
+	if isDefaultColor(clr) {
+		return def
+	}
+	s := strings.TrimSuffix(strings.TrimPrefix(sgrOf(clr), csi), "m")
+	return strings.ReplaceAll(s, "m"+csi, ";")
+}
+
+// paramsLen returns the length of the joined parameters.
+func paramsLen(codes []string) (n int) {
+	for _, c := range codes {
+		n += len(c) + 1
+	}
+	return
+}
@@ -0,0 +1,84 @@
+package color
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	bold := TextStyle{}.With(AttrBold)
+	red := TextStyle{}.WithFg(FgRed)
+	for _, c := range []struct {
+		name     string
+		from, to TextStyle
+		want     string
+	}{
+		{"same", bold, bold, ""},
+		{"from default", TextStyle{}, bold.WithFg(FgRed), "\x1b[1;31m"},
+		{"to default", bold.WithFg(FgRed), TextStyle{}, "\x1b[0m"},
+		{"add", bold, bold.With(AttrItalic), "\x1b[3m"},
+		{"remove", bold.With(AttrItalic), bold, "\x1b[23m"},
+		{"bold off keeps dim", red.With(AttrBold | AttrDim), red.With(AttrDim), "\x1b[22;2m"},
+		{"reset is shorter", bold.With(AttrItalic | AttrStrike), red, "\x1b[0;31m"},
+		{"fg", red, TextStyle{}.WithFg(*NewColor256(208, false)), "\x1b[38;5;208m"},
+		{"fg off", red.WithBg(BgBlue), TextStyle{}.WithBg(BgBlue), "\x1b[39m"},
+		{"bg", red, red.WithBg(*NewColor16m(1, 2, 3, true)), "\x1b[48;2;1;2;3m"},
+		{"underline", red, red.WithUnderline(UnderlineCurly), "\x1b[4:3m"},
+		{"underline shape", red.WithUnderline(UnderlineCurly), red.With(AttrUnderline), "\x1b[4m"},
+		{"underline color", red.WithUnderline(UnderlineSingle), red.WithUnderline(UnderlineSingle).WithUnderlineColor(FgRed), "\x1b[58:5:1m"},
+		{"underline off", red.With(AttrBold).WithUnderline(UnderlineDotted).WithUnderlineColor(FgRed), red.With(AttrBold), "\x1b[24;59m"},
+		{"overline framed", TextStyle{}, TextStyle{}.With(AttrOverline | AttrFramed), "\x1b[53;51m"},
+	} {
+		got := Diff(c.from, c.to)
+		if got != c.want {
+			t.Errorf("%s: got %q, want %q", c.name, got, c.want)
+		}
+		// the transition gets the style
+		if st := c.from.ApplySGR(got[min(2, len(got)):max(len(got)-1, 0)]); got != "" && !st.Equal(c.to) {
+			t.Errorf("%s: %q changes %+v to %+v, want %+v", c.name, got, c.from, st, c.to)
+		}
+	}
+}
+
+func TestTextStyleApply(t *testing.T) {
+	st := TextStyle{}.Apply(FgRed).Apply(BgBoldOrBright).Apply(NewStyle().Add(FgRed, UnderlineCurly, NewUnderlineColor(FgBlue)))
+	want := TextStyle{Fg: FgRed, Attrs: AttrBold | AttrUnderline, Underline: UnderlineCurly, UnderlineColor: *NewColor256(4, false)}
+	if !st.Equal(want) {
+		t.Errorf("got %+v, want %+v", st, want)
+	}
+	if got := st.Apply(Reset); !got.IsZero() {
+		t.Errorf("reset: %+v", got)
+	}
+	if got, want := st.Apply(nil), st; !got.Equal(want) {
+		t.Errorf("nil: %+v", got)
+	}
+	green := TextStyle{Fg: FgGreen, Attrs: AttrBold}
+	if got, want := green.Wrap("ok"), "\x1b[1;32mok\x1b[0m"; got != want {
+		t.Errorf("wrap: got %q, want %q", got, want)
+	}
+}
+
+func TestCPTNesting(t *testing.T) {
+	for _, c := range []struct {
+		src, want string
+	}{
+		{"<b>a<i>b</i>c</b>", "\x1b[1ma\x1b[3mb\x1b[23mc\x1b[0m"},
+		{"<i>a<b>b</b>c</i>", "\x1b[3ma\x1b[1mb\x1b[22mc\x1b[0m"},
+		{"<b>a<dim>b</dim>c</b>", "\x1b[1ma\x1b[2mb\x1b[0;1mc\x1b[0m"},
+		{"<font color=\"red\">a<font color=\"blue\">b</font>c</font>", "\x1b[31ma\x1b[34mb\x1b[31mc\x1b[0m"},
+		{"<b><b>a</b>b</b>", "\x1b[1mab\x1b[0m"},
+		{"<u style=\"curly\">a<u>b</u>c</u>", "\x1b[4:3ma\x1b[4mb\x1b[4:3mc\x1b[0m"},
+	} {
+		if got := GetCPTC().Translate(c.src, Reset); got != c.want {
+			t.Errorf("%q: got %q, want %q", c.src, got, c.want)
+		}
+
+		var buf bytes.Buffer
+		tw := NewTranslatingWriter(&buf, GetCPTC())
+		_, _ = tw.Write([]byte(c.src))
+		_ = tw.Close()
+		if got := buf.String(); got != c.want {
+			t.Errorf("%q writer: got %q, want %q", c.src, got, c.want)
+		}
+	}
+}
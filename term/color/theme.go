@@ -0,0 +1,394 @@
+package color
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/hedzr/is/dirs"
+)
+
+// Role is a semantic role of text, such as [RoleError], which is
+// mapped to a [Color] by a [Theme].
+type Role string
+
+const (
+	RoleError   Role = "error"
+	RoleWarning Role = "warning"
+	RoleSuccess Role = "success"
+	RoleInfo    Role = "info"
+	RoleMuted   Role = "muted"
+	RoleAccent  Role = "accent"
+	RoleHeading Role = "heading"
+	RoleCode    Role = "code"
+)
+
+// Theme maps the roles to the colors (or styles, see
+// [ParseColor]).
+//
+// The role names can be used in the CPT markups:
+//
+//	color.GetCPT().Translate(`<font color="error">failed</font>`, color.Reset)
+//
+// A theme may define its own roles besides the predefined ones.
+type Theme struct {
+	Name  string
+	Roles map[Role]Color
+}
+
+// ThemeDark is the built-in theme for the dark backgrounds, it's
+// the default theme.
+var ThemeDark = &Theme{
+	Name: "dark",
+	Roles: map[Role]Color{
+		RoleError:   NewStyle().Add(BgBoldOrBright, FgLightRed),
+		RoleWarning: FgLightYellow,
+		RoleSuccess: FgLightGreen,
+		RoleInfo:    FgLightBlue,
+		RoleMuted:   FgDarkGray,
+		RoleAccent:  FgLightCyan,
+		RoleHeading: NewStyle().Add(BgBoldOrBright, FgWhite),
+		RoleCode:    FgLightMagenta,
+	},
+}
+
+// ThemeLight is the built-in theme for the light backgrounds.
+var ThemeLight = &Theme{
+	Name: "light",
+	Roles: map[Role]Color{
+		RoleError:   NewStyle().Add(BgBoldOrBright, FgRed),
+		RoleWarning: FgYellow,
+		RoleSuccess: FgGreen,
+		RoleInfo:    FgBlue,
+		RoleMuted:   FgDarkGray,
+		RoleAccent:  FgMagenta,
+		RoleHeading: NewStyle().Add(BgBoldOrBright, FgBlack),
+		RoleCode:    FgCyan,
+	},
+}
+
+var builtinThemes = map[string]*Theme{
+	ThemeDark.Name:  ThemeDark,
+	ThemeLight.Name: ThemeLight,
+}
+
+var currentTheme atomic.Pointer[Theme]
+
+// SetTheme sets the current theme, nil restores [ThemeDark].
+func SetTheme(t *Theme) { currentTheme.Store(t) }
+
+// CurrentTheme returns the current theme.
+func CurrentTheme() *Theme {
+	if t := currentTheme.Load(); t != nil {
+		return t
+	}
+	return ThemeDark
+}
+
+// Color returns the color of a role, or nil if the role is not
+// defined.
+func (t *Theme) Color(role Role) Color {
+	return t.Roles[role]
+}
+
+// Wrap paints text in the color of a role, text is returned as
+// is if the role is not defined.
+func (t *Theme) Wrap(role Role, text string) string {
+	if clr := t.Color(role); clr != nil {
+		return clr.Wrap(text)
+	}
+	return text
+}
+
+// themeExts are the supported theme file formats.
+var themeExts = []string{".yaml", ".yml", ".json", ".toml"}
+
+// LoadTheme loads the theme file name.{yaml,yml,json,toml} under
+// the themes directory of the app, that is, dirs.ConfigDir(app,
+// "themes"). The built-in theme of the name ("dark" or "light")
+// is returned if no file is found, and "auto" is [AutoTheme].
+//
+// A theme file defines the roles, and may extend another theme
+// whose roles are inherited, [ThemeDark] by default:
+//
+//	# ~/.config/myapp/themes/mine.yaml
+//	extends: light
+//	accent: "bold #ff8700"
+//	error: red on lightyellow
+func LoadTheme(app, name string) (*Theme, error) {
+	dir := dirs.ConfigDir(app, "themes")
+	for _, ext := range themeExts {
+		path := filepath.Join(dir, name+ext)
+		if _, err := os.Stat(path); err == nil {
+			return ReadThemeFile(path)
+		}
+	}
+	if t, ok := builtinThemes[name]; ok {
+		return t, nil
+	}
+	if name == "auto" {
+		return AutoTheme(), nil
+	}
+	return nil, fmt.Errorf("color: theme %q not found in %s: %w", name, dir, fs.ErrNotExist)
+}
+
+// ReadThemeFile reads a theme file, the format is decided by the
+// extension of path. The name of the theme is the base name of
+// path if the file doesn't specify it.
+func ReadThemeFile(path string) (*Theme, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	ext := filepath.Ext(path)
+	t, err := ParseTheme(data, strings.TrimPrefix(ext, "."))
+	if err != nil {
+		return nil, fmt.Errorf("%w (%s)", err, path)
+	}
+	if t.Name == "" {
+		t.Name = strings.TrimSuffix(filepath.Base(path), ext)
+	}
+	return t, nil
+}
+
+// ParseTheme parses a theme in the format "json", "yaml" ("yml")
+// or "toml".
+//
+// The roles are the top-level keys, or the keys of a "roles"
+// object (table, mapping). The "name" and "extends" keys are
+// reserved. Only a flat subset of YAML and TOML is supported:
+// the key-value pairs, the comments and the "roles" table. The
+// keys of the other tables (mappings) are rejected.
+func ParseTheme(data []byte, format string) (*Theme, error) {
+	var kv map[string]string
+	switch strings.ToLower(format) {
+	case "json":
+		var m map[string]any
+		if err := json.Unmarshal(data, &m); err != nil {
+			return nil, fmt.Errorf("color: bad theme: %w", err)
+		}
+		kv = make(map[string]string)
+		if err := flattenJSON(kv, m, false); err != nil {
+			return nil, err
+		}
+	case "yaml", "yml", "toml":
+		var err error
+		if kv, err = parseKeyValues(string(data)); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("color: unknown theme format %q", format)
+	}
+
+	base := ThemeDark
+	if name, ok := kv["extends"]; ok {
+		if base, ok = builtinThemes[name]; !ok {
+			return nil, fmt.Errorf("color: unknown base theme %q", name)
+		}
+	}
+	t := &Theme{Name: kv["name"], Roles: make(map[Role]Color, len(base.Roles)+len(kv))}
+	for role, clr := range base.Roles {
+		t.Roles[role] = clr
+	}
+	for key, spec := range kv {
+		if key == "name" || key == "extends" {
+			continue
+		}
+		clr, err := ParseColor(spec)
+		if err != nil {
+			return nil, fmt.Errorf("color: bad role %q: %w", key, err)
+		}
+		t.Roles[Role(key)] = clr
+	}
+	return t, nil
+}
+
+// flattenJSON collects the top-level keys and those of the
+// "roles" object, the other objects are rejected.
+func flattenJSON(kv map[string]string, m map[string]any, nested bool) error {
+	for k, v := range m {
+		switch x := v.(type) {
+		case string:
+			kv[strings.ToLower(k)] = x
+		case map[string]any:
+			if nested || k != "roles" {
+				return fmt.Errorf("color: unknown theme table %q", k)
+			}
+			if err := flattenJSON(kv, x, true); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// parseKeyValues parses the "key: value" (YAML) or "key = value"
+// (TOML) lines, the top-level ones and those of the "roles"
+// table (TOML) or mapping (YAML).
+func parseKeyValues(s string) (map[string]string, error) {
+	kv := make(map[string]string)
+	table, mapping := "", false
+	for _, line := range strings.Split(s, "\n") {
+		indented := line != "" && (line[0] == ' ' || line[0] == '\t')
+		line = strings.TrimSpace(line)
+		if line == "" || line[0] == '#' || line == "---" {
+			continue
+		}
+		if line[0] == '[' {
+			end := strings.IndexByte(line, ']')
+			if end < 0 {
+				end = len(line)
+			}
+			table, mapping = strings.ToLower(unquote(strings.TrimSpace(line[1:end]))), false
+			continue
+		}
+		i := strings.IndexAny(line, ":=")
+		if i < 0 {
+			continue
+		}
+		key := strings.ToLower(unquote(strings.TrimSpace(line[:i])))
+		if mapping && !indented {
+			table, mapping = "", false
+		}
+		if !indented && strings.TrimSpace(line[i+1:]) == "" {
+			table, mapping = key, true // a YAML mapping
+			continue
+		}
+		if table != "" && table != "roles" {
+			return nil, fmt.Errorf("color: unknown theme table %q", table)
+		}
+		value := strings.TrimSpace(line[i+1:])
+		if value != "" && value[0] != '"' && value[0] != '\'' {
+			// a comment is a '#' after a space
+			if j := strings.Index(value, " #"); j >= 0 {
+				value = strings.TrimSpace(value[:j])
+			}
+		}
+		if value = unquote(value); key != "" && value != "" {
+			kv[key] = value
+		}
+	}
+	return kv, nil
+}
+
+func unquote(s string) string {
+	if len(s) >= 2 {
+		switch {
+		case s[0] == '"':
+			if i := strings.LastIndexByte(s, '"'); i > 0 {
+				if u, err := strconv.Unquote(s[:i+1]); err == nil {
+					return u
+				}
+			}
+		case s[0] == '\'':
+			if i := strings.LastIndexByte(s, '\''); i > 0 {
+				return s[1:i]
+			}
+		}
+	}
+	return s
+}
+
+var attrColors = map[string]Color16{
+	"bold": BgBoldOrBright, "dim": BgDim, "italic": BgItalic,
+	"underline": BgUnderline, "blink": BgBlink,
+	"reverse": BgInverse, "inverse": BgInverse, "hidden": BgHidden,
+	"strike": BgStrikeout, "strikethrough": BgStrikeout, "strikeout": BgStrikeout,
+}
+
+// ParseColor parses a color specification, which is a list of
+// the attributes (bold, dim, italic, underline, blink, reverse,
+// hidden and strike) and the colors, a color after "on" is the
+// background color. For example:
+//
+//	red
+//	bold #ff8700
+//	italic light-cyan on navy
+//	208 on #333
+//
+// A color is one of the 16 ANSI color names of the CPT markups
+// (such as "red" and "light-blue"), a CSS color name (such as
+// "orange" and "rebeccapurple"), a hex "#rgb" or "#rrggbb", or
+// an xterm 256 color number. A single color is returned as is,
+// and a list is returned as a [Style].
+func ParseColor(spec string) (Color, error) {
+	var items []Color
+	bg := false
+	for _, word := range strings.Fields(strings.ToLower(spec)) {
+		if word == "on" {
+			bg = true
+			continue
+		}
+		if clr, ok := attrColors[word]; ok && !bg {
+			items = append(items, clr)
+			continue
+		}
+		clr, ok := namedColor(word, bg)
+		if !ok {
+			return nil, fmt.Errorf("color: unknown color %q", word)
+		}
+		items, bg = append(items, clr), false
+	}
+	if bg {
+		return nil, fmt.Errorf("color: no color after \"on\" in %q", spec)
+	}
+	switch len(items) {
+	case 0:
+		return nil, fmt.Errorf("color: empty color %q", spec)
+	case 1:
+		return items[0], nil
+	}
+	return NewStyle().Add(items...), nil
+}
+
+// namedColor returns the color of a name, a hex value or a 256
+// color number.
+func namedColor(name string, bg bool) (Color, bool) {
+	cpt.onceInit()
+	if clr, ok := cptCM[name]; ok {
+		if c16, ok := clr.(Color16); ok && bg {
+			return c16 + 10, true
+		}
+		return clr, true
+	}
+	if name == "default" {
+		if bg {
+			return BgDefault, true
+		}
+		return FgDefault, true
+	}
+	if strings.HasPrefix(name, "#") {
+		rgb, ok := parseHex(name[1:])
+		if !ok {
+			return nil, false
+		}
+		return Color16m{clr: [4]byte{rgb[0], rgb[1], rgb[2]}, bg: bg}, true
+	}
+	if n, err := strconv.ParseUint(name, 10, 8); err == nil {
+		return Color256{clr: [4]byte{byte(n)}, bg: bg}, true
+	}
+	if v, ok := cssColors[strings.ReplaceAll(name, "-", "")]; ok {
+		return Color16m{clr: [4]byte{byte(v >> 16), byte(v >> 8), byte(v)}, bg: bg}, true
+	}
+	return nil, false
+}
+
+// parseHex parses "rgb" or "rrggbb".
+func parseHex(s string) (rgb [3]byte, ok bool) {
+	if len(s) == 3 {
+		s = string([]byte{s[0], s[0], s[1], s[1], s[2], s[2]})
+	}
+	if len(s) != 6 {
+		return
+	}
+	v, err := strconv.ParseUint(s, 16, 32)
+	if err != nil {
+		return
+	}
+	return [3]byte{byte(v >> 16), byte(v >> 8), byte(v)}, true
+}
@@ -0,0 +1,144 @@
+package color
+
+import (
+	"bytes"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseColor(t *testing.T) {
+	tests := []struct {
+		spec string
+		want string
+	}{
+		{"red", "\x1b[31m"},
+		{"light-blue", "\x1b[94m"},
+		{"on red", "\x1b[41m"},
+		{"#ff8700", "\x1b[38;2;255;135;0m"},
+		{"#abc", "\x1b[38;2;170;187;204m"},
+		{"208", "\x1b[38;5;208m"},
+		{"orange", "\x1b[38;2;255;165;0m"},
+		{"Rebecca-Purple", "\x1b[38;2;102;51;153m"},
+		{"bold red on #000", "\x1b[1m\x1b[31m\x1b[48;2;0;0;0m"},
+		{"italic default on default", "\x1b[3m\x1b[39m\x1b[49m"},
+	}
+	for i, tc := range tests {
+		clr, err := ParseColor(tc.spec)
+		if err != nil {
+			t.Errorf("%d. ParseColor(%q) failed: %v", i, tc.spec, err)
+			continue
+		}
+		if got := clr.Color(); got != tc.want {
+			t.Errorf("%d. ParseColor(%q) = %q, want %q", i, tc.spec, got, tc.want)
+		}
+	}
+	for _, spec := range []string{"", "nocolor", "#12", "256", "bold on"} {
+		if _, err := ParseColor(spec); err == nil {
+			t.Errorf("ParseColor(%q) should fail", spec)
+		}
+	}
+}
+
+func TestParseTheme(t *testing.T) {
+	tests := []struct {
+		format, data string
+	}{
+		{"json", `{"name": "mine", "extends": "light", "roles": {"accent": "bold #ff8700", "link": "underline blue"}}`},
+		{"yaml", "# my theme\nname: mine\nextends: light\nroles:\n  accent: \"bold #ff8700\" # orange\n  link: underline blue\n"},
+		{"toml", "name = \"mine\"\nextends = 'light'\n\n[roles]\naccent = \"bold #ff8700\"\nlink = \"underline blue\"\n"},
+	}
+	for _, tc := range tests {
+		th, err := ParseTheme([]byte(tc.data), tc.format)
+		if err != nil {
+			t.Errorf("%s: %v", tc.format, err)
+			continue
+		}
+		if th.Name != "mine" {
+			t.Errorf("%s: name = %q", tc.format, th.Name)
+		}
+		if got := th.Color(RoleAccent).Color(); got != "\x1b[1m\x1b[38;2;255;135;0m" {
+			t.Errorf("%s: accent = %q", tc.format, got)
+		}
+		if got := th.Color("link").Color(); got != "\x1b[4m\x1b[34m" {
+			t.Errorf("%s: link = %q", tc.format, got)
+		}
+		if got := th.Color(RoleSuccess); got != FgGreen {
+			t.Errorf("%s: success = %v, want inherited from light", tc.format, got)
+		}
+	}
+	if _, err := ParseTheme([]byte("error: nocolor"), "yaml"); err == nil {
+		t.Error("a bad color should fail")
+	}
+	if _, err := ParseTheme([]byte("extends: neon"), "yaml"); err == nil {
+		t.Error("an unknown base should fail")
+	}
+	for _, tc := range []struct {
+		format, data string
+	}{
+		{"toml", "[roles]\naccent = \"red\"\n\n[meta]\nauthor = \"me\"\n"},
+		{"yaml", "roles:\n  accent: red\nmeta:\n  author: me\n"},
+		{"json", `{"roles": {"accent": "red"}, "meta": {"author": "me"}}`},
+	} {
+		if _, err := ParseTheme([]byte(tc.data), tc.format); err == nil {
+			t.Errorf("%s: the keys of the other tables should fail", tc.format)
+		}
+	}
+}
+
+func TestLoadTheme(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", "")
+	t.Setenv("APPDATA", home)
+	dir := filepath.Join(home, ".config", "demo", "themes")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "ocean.yaml"), []byte("muted: '#555'\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	th, err := LoadTheme("demo", "ocean")
+	if err != nil {
+		t.Skipf("the config dir of this platform is not under $HOME: %v", err)
+	}
+	if th.Name != "ocean" || th.Color(RoleMuted).Color() != "\x1b[38;2;85;85;85m" {
+		t.Errorf("got %q, %q", th.Name, th.Color(RoleMuted).Color())
+	}
+	if th, err = LoadTheme("demo", "light"); err != nil || th != ThemeLight {
+		t.Errorf("LoadTheme(light) = %v, %v", th, err)
+	}
+	if _, err = LoadTheme("demo", "nope"); err == nil {
+		t.Error("LoadTheme(nope) should fail")
+	}
+}
+
+func TestCPTThemeRoles(t *testing.T) {
+	SetTheme(&Theme{Name: "t", Roles: map[Role]Color{RoleAccent: FgMagenta}})
+	defer SetTheme(nil)
+	tests := []struct {
+		in, want string
+	}{
+		{`<font color="accent">a</font>`, "\x1b[35ma\x1b[0m"},
+		{`<font color="#ff0000">a</font>`, "\x1b[38;2;255;0;0ma\x1b[0m"},
+		{`<font color="orange">a</font>`, "\x1b[38;2;255;165;0ma\x1b[0m"},
+		{`<font color="red">a</font>`, "\x1b[31ma\x1b[0m"},
+	}
+	for i, tc := range tests {
+		if got := GetCPTC().Translate(tc.in, Reset); got != tc.want {
+			t.Errorf("%d. Translate(%q) = %q, want %q", i, tc.in, got, tc.want)
+		}
+	}
+
+	// an unknown name is reported
+	var log bytes.Buffer
+	defer slog.SetDefault(slog.Default())
+	slog.SetDefault(slog.New(slog.NewTextHandler(&log, nil)))
+	_ = GetCPTC().Translate(`<font color="nocolor">a</font>`, Reset)
+	if !strings.Contains(log.String(), "unknown color name") || !strings.Contains(log.String(), "nocolor") {
+		t.Errorf("want a warning, got %q", log.String())
+	}
+}
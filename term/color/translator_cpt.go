@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 	"log/slog"
+	"os"
 	"strings"
 	"sync"
 	"unicode"
@@ -36,37 +37,31 @@ var _ Translator = (*cpTranslator)(nil) // ensure cpTranslator implements Transl
 type cpTranslator struct {
 	noColorMode     bool // strip color code simply
 	noLeadingSpaces bool
+	forceLinks      bool      // write OSC 8 hyperlinks even if os.Stdout isn't capable
+	out             io.Writer // the output whose OSC 8 capability is checked, os.Stdout if nil
 }
 
 func (c *cpTranslator) Translate(s string, initialFg Color) string {
 	return c.TranslateTo(s, initialFg)
 }
 
-func (c *cpTranslator) resetColors(sb *strings.Builder, states []Color) func() { //nolint:revive,gocritic
-	return func() {
-		var st string
-		st = "\x1b[0m"
-		_, _ = (*sb).WriteString(st)
-		if len(states) > 0 {
-			st = fmt.Sprintf("\x1b[%dm", states[len(states)-1])
-			_, _ = (*sb).WriteString(st)
-		}
-	}
-}
-
-func (c *cpTranslator) colorize(sb *strings.Builder, states []Color, walker *func(node *html.Node, level int)) func(node *html.Node, clr Color, representation string, level int) { //nolint:revive,gocritic,lll
+// colorize returns the function which writes a styled element:
+// the style of the element is applied on the top of the styles
+// stack, and only the transitions are written, see [Diff].
+func (c *cpTranslator) colorize(sb *strings.Builder, styles *[]TextStyle, walker *func(node *html.Node, level int)) func(node *html.Node, clr Color, representation string, level int) { //nolint:revive,gocritic,lll
 	return func(node *html.Node, clr Color, representation string, level int) {
+		cur := (*styles)[len(*styles)-1]
+		next := cur.Apply(clr)
 		if representation != "" {
-			_, _ = (*sb).WriteString(fmt.Sprintf("\x1b[%sm", representation))
-		} else {
-			_, _ = (*sb).WriteString(fmt.Sprintf("\x1b[%dm", clr))
+			next = cur.ApplySGR(representation)
 		}
-		states = append(states, clr) //nolint:revive
+		_, _ = (*sb).WriteString(Diff(cur, next))
+		*styles = append(*styles, next)
 		for child := node.FirstChild; child != nil; child = child.NextSibling {
 			(*walker)(child, level+1)
 		}
-		states = states[0 : len(states)-1] //nolint:revive
-		c.resetColors(sb, states)()
+		*styles = (*styles)[:len(*styles)-1]
+		_, _ = (*sb).WriteString(Diff(next, cur))
 	}
 }
 
@@ -102,13 +97,14 @@ func (c *cpTranslator) TranslateTo(s string, initialState Color) string {
 }
 
 func (c *cpTranslator) translateTo(root *html.Node, leading, source string, initialState Color) string {
-	states, _ := []Color{initialState}, source //nolint:revive,gocritic
+	// the initial style is assumed on the terminal already
+	styles, _ := []TextStyle{TextStyle{}.Apply(initialState)}, source //nolint:revive,gocritic
 	var sb strings.Builder
 
 	_, _ = sb.WriteString(leading)
 
 	var walker func(node *html.Node, level int)
-	colorize := c.colorize(&sb, states, &walker)
+	colorize := c.colorize(&sb, &styles, &walker)
 	// nilfn := func(node *html.Node, level int) {}
 	colorizeIt := func(clr Color) func(node *html.Node, level int) {
 		return func(node *html.Node, level int) {
@@ -117,16 +113,9 @@ func (c *cpTranslator) translateTo(root *html.Node, leading, source string, init
 	}
 	m := map[string]func(node *html.Node, level int){
 		"html": nil, "head": nil, "body": nil,
-		"b": colorizeIt(BgBoldOrBright), "strong": colorizeIt(BgBoldOrBright), "em": colorizeIt(BgBoldOrBright),
-		"i": colorizeIt(BgItalic), "cite": colorizeIt(BgItalic),
-		"u":    colorizeIt(BgUnderline),
-		"mark": colorizeIt(BgInverse),
-		"hide": colorizeIt(BgHidden),
-		"del":  colorizeIt(BgStrikeout),
-		"dim":  colorizeIt(BgDim),
-		"dbl":  colorizeIt(Color16(21)), // doubly underlined
-		"over": colorizeIt(Color16(53)), // overlined
-		// "box":  colorizeIt(Color16(51)), // framed (not work)
+	}
+	for tag, clr := range cptTags {
+		m[tag] = colorizeIt(clr)
 	}
 
 	walker = func(node *html.Node, level int) {
@@ -134,6 +123,10 @@ func (c *cpTranslator) translateTo(root *html.Node, leading, source string, init
 		case html.DocumentNode, html.DoctypeNode, html.CommentNode:
 		case html.ErrorNode:
 		case html.ElementNode:
+			if node.Data == "u" && len(node.Attr) > 0 {
+				colorize(node, c.underline(node.Attr), "", level)
+				return
+			}
 			if fn, ok := m[node.Data]; ok {
 				if fn != nil {
 					fn(node, level)
@@ -150,10 +143,22 @@ func (c *cpTranslator) translateTo(root *html.Node, leading, source string, init
 						return
 					}
 				}
+			case "a":
+				c.anchor(&sb, node, level, walker)
+				return
 			case "kbd", "code":
+				if lang := attrOf(node.Attr, "lang"); lang != "" && node.Data == "code" {
+					code := highlight(textOf(node), lang)
+					_, _ = sb.WriteString(code)
+					if strings.Contains(code, "\x1b") {
+						// the highlighted tokens end with the resets
+						_, _ = sb.WriteString(Diff(TextStyle{}, styles[len(styles)-1]))
+					}
+					return
+				}
 				// printf "\033[%sm%s\033[0m\n" "51;1" "text here"
 				// draw a frame arround the character(s), rarely supported.
-				colorize(node, Reset, "51;1", level)
+				colorize(node, Reset, string(sgrFramed), level)
 				return
 			default:
 				// Logger.Debugf("%v, %v, lvl #%d\n", node.Type, node.Data, level)
@@ -178,6 +183,53 @@ func (c *cpTranslator) translateTo(root *html.Node, leading, source string, init
 	return sb.String()
 }
 
+// underline returns the style of a <u style="curly" color="red">
+// element, see [UnderlineStyle] and [NewUnderlineColor]. A color
+// which is a [Style], such as a theme role "bold red", falls back
+// to the default underline color.
+func (c *cpTranslator) underline(attrs []html.Attribute) Color {
+	sty := NewStyle().Add(UnderlineSingle)
+	for _, a := range attrs {
+		switch a.Key {
+		case "style":
+			if u, ok := underlineStyles[strings.ToLower(strings.TrimSpace(a.Val))]; ok {
+				sty.Items[0] = u
+			}
+		case "color":
+			sty.Items = append(sty.Items, NewUnderlineColor(c.toColorInt(a.Val)))
+		}
+	}
+	return sty
+}
+
+// anchor writes an <a href> element as an OSC 8 hyperlink, or
+// "text (url)" if the output isn't capable, see [Cursor.Link].
+func (c *cpTranslator) anchor(sb *strings.Builder, node *html.Node, level int, walker func(node *html.Node, level int)) {
+	url := sanitizeOSC(attrOf(node.Attr, "href"))
+	out := c.out
+	if out == nil {
+		out = os.Stdout
+	}
+	capable := url != "" && (c.forceLinks || oscCapable(out))
+	if capable {
+		_, _ = sb.WriteString(osc("8", "", url))
+	}
+	start := sb.Len()
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		walker(child, level+1)
+	}
+	switch {
+	case capable:
+		_, _ = sb.WriteString(osc("8", "", ""))
+	case url != "":
+		if plain := term.StripEscapes(sb.String()[start:]); plain == "" {
+			_, _ = sb.WriteString(url)
+		} else if plain != url {
+			_, _ = sb.WriteString(" (" + url + ")")
+		}
+	}
+}
+
 func (c *cpTranslator) _sz(s string) string {
 	return s
 }
@@ -185,9 +237,9 @@ func (c *cpTranslator) _sz(s string) string {
 func (c *cpTranslator) _ss(s string) string {
 	if term.IsAnsiEscaped(s) {
 		clean := term.StripEscapes(s)
-		return c.stripHTMLTags(clean)
+		return c.stripHTMLTags(expandAnchors(clean))
 	}
-	return c.stripHTMLTags(s)
+	return c.stripHTMLTags(expandAnchors(s))
 }
 
 func (c *cpTranslator) StripLeftTabsAndColorize(s string) string {
@@ -383,12 +435,72 @@ func (c *cpTranslator) toColorString(clr Color) string { //nolint:revive
 
 func (c *cpTranslator) ToColorInt(s string) Color { return c.toColorInt(s) }
 
+// toColorInt returns the color of a name, which can be one of
+// the 16 ANSI color names, a role of the current theme (see
+// [SetTheme]), or a color specification (see [ParseColor]). An
+// unknown name is reported by slog and becomes [Reset].
 func (c *cpTranslator) toColorInt(s string) Color { //nolint:revive
 	c.onceInit()
-	if i, ok := cptCM[strings.ToLower(s)]; ok {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if i, ok := cptCM[s]; ok {
 		return i
 	}
-	return Reset
+	if clr := CurrentTheme().Color(Role(s)); clr != nil {
+		return clr
+	}
+	clr, err := ParseColor(s)
+	if err != nil {
+		slog.Warn("color: unknown color name", "name", s, "err", err)
+		return Reset
+	}
+	return clr
+}
+
+// sgrOf returns the escape sequence of clr.
+func sgrOf(clr Color) string {
+	if c16, ok := clr.(Color16); ok {
+		return fmt.Sprintf("\x1b[%dm", int(c16))
+	}
+	return clr.Color()
+}
+
+// cptTags are the CPT tags of the effects.
+var cptTags = map[string]Color{
+	"b": BgBoldOrBright, "strong": BgBoldOrBright, "em": BgBoldOrBright,
+	"i": BgItalic, "cite": BgItalic,
+	"u":    BgUnderline,
+	"mark": BgInverse,
+	"hide": BgHidden,
+	"del":  BgStrikeout,
+	"dim":  BgDim,
+	"dbl":  Color16(21), // doubly underlined
+	"over": Color16(53), // overlined
+	// "box":  Color16(51), // framed (not work)
+}
+
+// sgrFramed is the style of <kbd> and <code>.
+const sgrFramed SGRParam = "51;1"
+
+// textOf returns the text of node and its descendants.
+func textOf(node *html.Node) string {
+	if node.Type == html.TextNode {
+		return node.Data
+	}
+	var sb strings.Builder
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		_, _ = sb.WriteString(textOf(child))
+	}
+	return sb.String()
+}
+
+// attrOf returns the value of the attribute key.
+func attrOf(attrs []html.Attribute, key string) string {
+	for _, a := range attrs {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
 }
 
 const (
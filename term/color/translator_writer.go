@@ -0,0 +1,379 @@
+package color
+
+import (
+	"bytes"
+	"io"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// The limits of the pending bytes of a [TranslatingWriter], a
+// longer tag or entity is written as the plain text.
+const (
+	maxTagLen    = 1024
+	maxEntityLen = 32
+	maxTagDepth  = 64
+	maxLineLen   = 64 << 10
+)
+
+// cptEscaper escapes the CPT markup characters.
+var cptEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// EscapeCPT escapes '<', '>' and '&' in s as the entities, so
+// the user data can be put in the CPT markups without injecting
+// any tags:
+//
+//	color.GetCPT().Translate("<b>"+color.EscapeCPT(name)+"</b>", color.Reset)
+func EscapeCPT(s string) string { return cptEscaper.Replace(s) }
+
+// TranslatingWriter translates the CPT markups (see [GetCPT])
+// written through it, incrementally. The markups split across the
+// writes are handled, and the memory is bounded: an incomplete tag
+// or entity is held until the next Write, and the styles are kept
+// in a stack across the writes.
+//
+// The literal '<', '>' and '&' can be written as "&lt;", "&gt;"
+// and "&amp;", see [EscapeCPT]. A '<' which cannot start a tag,
+// such as in "a < b", is written as is.
+type TranslatingWriter struct {
+	w       io.Writer
+	t       Translator
+	colored bool
+	stream  bool // t is a CPT translator, or else it's line based
+	pending []byte
+	stack   []cptFrame
+	pen     TextStyle // the style written last
+}
+
+// cptFrame is an open tag.
+type cptFrame struct {
+	name   string
+	clr    Color
+	style  TextStyle // the style of the element, applied on the outer ones
+	url    string    // the href of an <a>
+	linked bool      // the OSC 8 hyperlink is written
+	text   []byte    // the text of an unlinked <a>, up to len(url)+1 bytes
+	lang   string    // the language of a <code lang>, see [HighlightCode]
+	code   []byte    // the code to highlight, up to maxLineLen bytes
+}
+
+// NewTranslatingWriter returns a writer which translates the CPT
+// markups into w by t: [GetCPTC] writes the colors, [GetCPTNC]
+// strips the tags, and nil means [GetCPT]. Another translator
+// translates the lines one by one.
+//
+//	tw := color.NewTranslatingWriter(os.Stdout, nil)
+//	defer tw.Close()
+//	fmt.Fprintf(tw, "<b>%s</b> is <font color=%q>ready</font>\n", color.EscapeCPT(name), "green")
+func NewTranslatingWriter(w io.Writer, t Translator) *TranslatingWriter {
+	if t == nil {
+		t = GetCPT()
+	}
+	tw := &TranslatingWriter{w: w, t: t}
+	if c, ok := t.(*cpTranslator); ok {
+		tw.stream, tw.colored = true, !c.noColorMode
+	}
+	return tw
+}
+
+// Write translates p, an incomplete tag or entity at the end is
+// held until the next Write, Flush or Close.
+func (tw *TranslatingWriter) Write(p []byte) (n int, err error) {
+	if !tw.stream {
+		return tw.writeLines(p)
+	}
+	data := append(tw.pending, p...)
+	tw.pending = nil
+
+	var out bytes.Buffer
+	for i := 0; i < len(data); {
+		var n int
+		switch data[i] {
+		case '<':
+			n = tagLen(data[i:])
+			if n > 0 {
+				tw.tag(&out, string(data[i:i+n]))
+			}
+		case '&':
+			n = entityLen(data[i:])
+			if n > 0 {
+				tw.text(&out, html.UnescapeString(string(data[i:i+n])))
+			}
+		default:
+			n = 1
+			for i+n < len(data) && data[i+n] != '<' && data[i+n] != '&' {
+				n++
+			}
+			tw.text(&out, string(data[i:i+n]))
+		}
+		switch {
+		case n < 0: // incomplete
+			tw.pending = append([]byte(nil), data[i:]...)
+			i = len(data)
+		case n == 0: // not a tag or entity
+			tw.text(&out, string(data[i]))
+			i++
+		default:
+			i += n
+		}
+	}
+	if err = tw.flushOut(&out); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// writeLines translates the complete lines by a translator other
+// than the CPT ones.
+func (tw *TranslatingWriter) writeLines(p []byte) (n int, err error) {
+	data := append(tw.pending, p...)
+	tw.pending = nil
+	cut := bytes.LastIndexByte(data, '\n') + 1
+	if cut == 0 && len(data) > maxLineLen {
+		cut = len(data)
+	}
+	tw.pending = append([]byte(nil), data[cut:]...)
+	if cut > 0 {
+		if _, err = io.WriteString(tw.w, tw.t.Translate(string(data[:cut]), Reset)); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+func (tw *TranslatingWriter) flushOut(out *bytes.Buffer) (err error) {
+	if out.Len() > 0 {
+		_, err = io.WriteString(tw.w, DownsampleString(out.String(), ColorLevel(tw.w)))
+	}
+	return
+}
+
+// tagLen returns the length of the tag at the beginning of s, -1
+// if it's incomplete, or 0 if s doesn't start with a tag.
+func tagLen(s []byte) int {
+	if len(s) < 2 {
+		return -1
+	}
+	if c := s[1]; c != '/' && c != '!' && (c|0x20 < 'a' || c|0x20 > 'z') {
+		return 0
+	}
+	var quote byte
+	for j := 1; j < len(s); j++ {
+		if j >= maxTagLen {
+			return 0
+		}
+		switch c := s[j]; {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '>':
+			return j + 1
+		}
+	}
+	return -1
+}
+
+// entityLen returns the length of the character reference at the
+// beginning of s, such as "&lt;" and "&#60;", -1 if it's
+// incomplete, or 0 if s doesn't start with one.
+func entityLen(s []byte) int {
+	for j := 1; j < len(s); j++ {
+		if j >= maxEntityLen {
+			return 0
+		}
+		switch c := s[j]; {
+		case c == ';':
+			if j == 1 {
+				return 0
+			}
+			return j + 1
+		case c == '#' && j == 1,
+			c >= '0' && c <= '9', c|0x20 >= 'a' && c|0x20 <= 'z':
+		default:
+			return 0
+		}
+	}
+	return -1
+}
+
+// text writes the plain text, which is recorded for the unlinked
+// <a> elements too.
+func (tw *TranslatingWriter) text(out *bytes.Buffer, s string) {
+	if f := tw.collecting(); f != nil {
+		if f.code = append(f.code, s...); len(f.code) > maxLineLen {
+			tw.writeCode(out, f)
+		}
+		return
+	}
+	_, _ = out.WriteString(s)
+	for i := range tw.stack {
+		if f := &tw.stack[i]; f.name == "a" && !f.linked && len(f.text) <= len(f.url) {
+			f.text = append(f.text, s[:min(len(s), len(f.url)+1-len(f.text))]...)
+		}
+	}
+}
+
+// tag handles a start or end tag, the unknown tags, comments, ...
+// are dropped.
+func (tw *TranslatingWriter) tag(out *bytes.Buffer, s string) {
+	z := html.NewTokenizer(strings.NewReader(s))
+	if tw.collecting() != nil { // no tags in the code
+		if z.Next() == html.EndTagToken && z.Token().Data == "code" {
+			tw.close(out, "code")
+		}
+		return
+	}
+	switch z.Next() {
+	case html.StartTagToken:
+		tok := z.Token()
+		tw.open(out, tok.Data, tok.Attr)
+	case html.EndTagToken:
+		tw.close(out, z.Token().Data)
+	}
+}
+
+func (tw *TranslatingWriter) open(out *bytes.Buffer, name string, attrs []html.Attribute) {
+	if len(tw.stack) >= maxTagDepth {
+		return
+	}
+	var clr Color
+	var lang string
+	switch name {
+	case "font":
+		if v := attrOf(attrs, "color"); v != "" {
+			clr = cpt.toColorInt(v)
+		}
+	case "u":
+		clr = BgUnderline
+		if len(attrs) > 0 {
+			clr = cpt.underline(attrs)
+		}
+	case "a":
+	case "kbd", "code":
+		clr = sgrFramed
+		if lang = attrOf(attrs, "lang"); lang != "" && name == "code" {
+			clr = nil
+		}
+	default:
+		var ok bool
+		if clr, ok = cptTags[name]; !ok {
+			return
+		}
+	}
+	tw.stack = append(tw.stack, cptFrame{name: name, clr: clr, lang: lang})
+	f := &tw.stack[len(tw.stack)-1]
+	if !tw.colored {
+		f.clr = nil
+	}
+	if name == "a" {
+		f.url = sanitizeOSC(attrOf(attrs, "href"))
+		if f.linked = tw.colored && f.url != "" && oscCapable(tw.w); f.linked {
+			_, _ = out.WriteString(osc("8", "", f.url))
+		}
+	}
+	f.style = tw.style(len(tw.stack) - 1).Apply(f.clr)
+	tw.restyle(out, f.style)
+}
+
+// style returns the style of the frames below k.
+func (tw *TranslatingWriter) style(k int) TextStyle {
+	if k > 0 {
+		return tw.stack[k-1].style
+	}
+	return TextStyle{}
+}
+
+// restyle writes the transition from the pen to st.
+func (tw *TranslatingWriter) restyle(out *bytes.Buffer, st TextStyle) {
+	_, _ = out.WriteString(Diff(tw.pen, st))
+	tw.pen = st
+}
+
+// close pops the frames till the last open tag of name, and
+// restores the styles of the rest.
+func (tw *TranslatingWriter) close(out *bytes.Buffer, name string) {
+	k := len(tw.stack) - 1
+	for k >= 0 && tw.stack[k].name != name {
+		k--
+	}
+	if k >= 0 {
+		tw.popTo(out, k)
+	}
+}
+
+func (tw *TranslatingWriter) popTo(out *bytes.Buffer, k int) {
+	for i := len(tw.stack) - 1; i >= k; i-- {
+		f := &tw.stack[i]
+		if f.lang != "" {
+			tw.writeCode(out, f)
+		}
+		switch {
+		case f.name != "a" || f.url == "":
+		case f.linked:
+			_, _ = out.WriteString(osc("8", "", ""))
+		default:
+			tw.stack = tw.stack[:i] // the fallback text is not recorded
+			if text := string(f.text); text == "" {
+				tw.text(out, f.url)
+			} else if text != f.url {
+				tw.text(out, " ("+f.url+")")
+			}
+		}
+	}
+	tw.stack = tw.stack[:k]
+	tw.restyle(out, tw.style(k))
+}
+
+// collecting returns the innermost frame if it's a <code lang>,
+// whose text is held to be highlighted.
+func (tw *TranslatingWriter) collecting() *cptFrame {
+	if n := len(tw.stack); n > 0 && tw.stack[n-1].lang != "" {
+		return &tw.stack[n-1]
+	}
+	return nil
+}
+
+// writeCode writes the code held in f, highlighted if colored.
+func (tw *TranslatingWriter) writeCode(out *bytes.Buffer, f *cptFrame) {
+	if tw.colored {
+		code := highlight(string(f.code), f.lang)
+		_, _ = out.WriteString(code)
+		if strings.Contains(code, "\x1b") {
+			tw.pen = TextStyle{} // the highlighted tokens end with the resets
+		}
+	} else {
+		_, _ = out.Write(f.code)
+	}
+	f.code = f.code[:0]
+}
+
+// Flush writes the held bytes as the plain text.
+func (tw *TranslatingWriter) Flush() (err error) {
+	if len(tw.pending) == 0 {
+		return
+	}
+	if !tw.stream {
+		_, err = io.WriteString(tw.w, tw.t.Translate(string(tw.pending), Reset))
+		tw.pending = nil
+		return
+	}
+	var out bytes.Buffer
+	tw.text(&out, string(tw.pending))
+	tw.pending = nil
+	return tw.flushOut(&out)
+}
+
+// Close flushes the held bytes, and closes the open tags. The
+// underlying writer is not closed.
+func (tw *TranslatingWriter) Close() (err error) {
+	if err = tw.Flush(); err != nil || len(tw.stack) == 0 {
+		return
+	}
+	var out bytes.Buffer
+	tw.popTo(&out, 0)
+	return tw.flushOut(&out)
+}
@@ -0,0 +1,126 @@
+package color
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestTranslatingWriter(t *testing.T) {
+	for _, c := range []struct {
+		src, want string
+	}{
+		{"plain text", "plain text"},
+		{"<b>bold</b> x", "\x1b[1mbold\x1b[0m x"},
+		{"<font color=\"red\">r<i>i</i>r</font>", "\x1b[31mr\x1b[3mi\x1b[23mr\x1b[0m"},
+		{"<u style='curly'>u</u>", "\x1b[4:3mu\x1b[0m"},
+		{"<kbd>k</kbd>", "\x1b[1;51mk\x1b[0m"},
+		{"a < b && c &gt; d &lt;b&gt;", "a < b && c > d <b>"},
+		{"<unknown>x</unknown></b>", "x"},
+		{"<b>open", "\x1b[1mopen\x1b[0m"},
+		{"5 &lt 6 &#60;", "5 &lt 6 <"},
+	} {
+		var buf bytes.Buffer
+		tw := NewTranslatingWriter(&buf, GetCPTC())
+		_, _ = tw.Write([]byte(c.src))
+		_ = tw.Close()
+		if got := buf.String(); got != c.want {
+			t.Errorf("%q: got %q, want %q", c.src, got, c.want)
+		}
+
+		// byte by byte
+		buf.Reset()
+		tw = NewTranslatingWriter(&buf, GetCPTC())
+		for i := range len(c.src) {
+			_, _ = tw.Write([]byte{c.src[i]})
+		}
+		_ = tw.Close()
+		if got := buf.String(); got != c.want {
+			t.Errorf("%q split: got %q, want %q", c.src, got, c.want)
+		}
+	}
+}
+
+func TestTranslatingWriterPlain(t *testing.T) {
+	for _, c := range []struct {
+		src, want string
+	}{
+		{"<b>bold</b> &lt;x&gt;", "bold <x>"},
+		{`see <a href="https://x.io">x</a>.`, "see x (https://x.io)."},
+		{`<a href="https://x.io">https://x.io</a>`, "https://x.io"},
+		{`<a href="https://x.io"></a>`, "https://x.io"},
+		{`<a href="https://x.io">t<b>b</b><i>i</i><u>u</u><dim>d</dim></a>`, "tbiud (https://x.io)"},
+	} {
+		var buf bytes.Buffer
+		tw := NewTranslatingWriter(&buf, GetCPTNC())
+		_, _ = tw.Write([]byte(c.src))
+		_ = tw.Close()
+		if got := buf.String(); got != c.want {
+			t.Errorf("%q: got %q, want %q", c.src, got, c.want)
+		}
+	}
+}
+
+func TestTranslatingWriterLink(t *testing.T) {
+	withTerminfo(t, "")
+	SetColorLevel(Level256)
+	defer SetColorLevel(0)
+	var buf bytes.Buffer
+	tw := NewTranslatingWriter(&buf, GetCPTC())
+	_, _ = tw.Write([]byte(`<a href="https://x.io"><b>x</b></a>`))
+	_ = tw.Close()
+	if got, want := buf.String(), "\x1b]8;;https://x.io\x1b\\\x1b[1mx\x1b[0m\x1b]8;;\x1b\\"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	// the controls in href can't end the sequence
+	buf.Reset()
+	tw = NewTranslatingWriter(&buf, GetCPTC())
+	_, _ = tw.Write([]byte(`<a href="https://x.io&#27;\&#27;]2;pwned&#7;">x</a>`))
+	_ = tw.Close()
+	if got, want := buf.String(), "\x1b]8;;https://x.io\\]2;pwned\x1b\\x\x1b]8;;\x1b\\"; got != want {
+		t.Errorf("malicious href: got %q, want %q", got, want)
+	}
+}
+
+func TestTranslatingWriterBounded(t *testing.T) {
+	var buf bytes.Buffer
+	tw := NewTranslatingWriter(&buf, GetCPTC())
+	long := "<" + strings.Repeat("x", 2*maxTagLen)
+	_, _ = tw.Write([]byte(long))
+	if len(tw.pending) > maxTagLen {
+		t.Errorf("pending %d bytes", len(tw.pending))
+	}
+	_ = tw.Close()
+	if got := buf.String(); got != long {
+		t.Errorf("got %d bytes, want %d", len(got), len(long))
+	}
+
+	buf.Reset()
+	tw = NewTranslatingWriter(&buf, GetCPTC())
+	_, _ = tw.Write([]byte(strings.Repeat("<b>", 2*maxTagDepth) + "x"))
+	if len(tw.stack) != maxTagDepth {
+		t.Errorf("stack depth %d", len(tw.stack))
+	}
+}
+
+func TestTranslatingWriterLines(t *testing.T) {
+	var buf bytes.Buffer
+	tw := NewTranslatingWriter(&buf, GetDummyTranslator())
+	_, _ = tw.Write([]byte("<b>a</b>\nb"))
+	if got := buf.String(); got != "<b>a</b>\n" {
+		t.Errorf("got %q", got)
+	}
+	_ = tw.Close()
+	if got := buf.String(); got != "<b>a</b>\nb" {
+		t.Errorf("got %q", got)
+	}
+}
+
+func TestEscapeCPT(t *testing.T) {
+	name := "<b>x & y</b>"
+	got := GetCPTC().Translate("<i>"+EscapeCPT(name)+"</i>", Reset)
+	if plain := ParseANSI(got).Plain(); plain != name {
+		t.Errorf("got %q", plain)
+	}
+}
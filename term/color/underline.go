@@ -0,0 +1,155 @@
+package color
+
+import (
+	"io"
+	"strconv"
+	"strings"
+)
+
+var _ Color = (*SGRParam)(nil)
+var _ Color = (*UnderlineStyle)(nil)
+var _ colorCSI = (*SGRParam)(nil)
+var _ colorCSI = (*UnderlineStyle)(nil)
+
+// SGRParam is an SGR parameter with the colon separated
+// sub-parameters, such as "4:3" (curly underline) and
+// "58:2::255:0:0" (red underline), see [CSIsgr.Sub].
+//
+// It can be grouped with the other SGR codes by [Style]:
+//
+//	color.FgRed.AddAndNew(color.UnderlineCurly) // "\x1b[31;4:3m"
+type SGRParam string
+
+func (c SGRParam) leading() string       { return csi }
+func (c SGRParam) ending() byte          { return 'm' }
+func (c SGRParam) prologue(out CWriter)  { _, _ = out.WriteString(csi) }
+func (c SGRParam) epilogue(out CWriter)  { _ = out.WriteByte('m') }
+func (c SGRParam) core(out CWriter)      { _, _ = out.WriteString(string(c)) }
+func (c SGRParam) ColorTo(out io.Writer) { wrString(out, c.Color()) }
+func (c SGRParam) String() string        { return c.Color() }
+func (c SGRParam) Color() string         { return c.Wrap("") }
+
+// Int returns the leading code, such as 4 of "4:3".
+func (c SGRParam) Int() int {
+	code, _, _ := strings.Cut(string(c), ":")
+	n, _ := strconv.Atoi(code)
+	return n
+}
+
+func (c SGRParam) Wrap(text string) string {
+	var sb = NewFmtBuf()
+	if c != "" {
+		c.prologue(sb)
+		c.core(sb)
+		c.epilogue(sb)
+	}
+	if len(text) > 0 {
+		_, _ = sb.WriteString(text)
+		Reset.ColorTo(sb)
+	}
+	return sb.PutBack()
+}
+
+func (c SGRParam) AddAndNew(colors ...Color) (newclr *Style) {
+	newclr = NewStyle().Add(c)
+	return newclr.Add(colors...)
+}
+
+func (c SGRParam) TryAppend(color colorCSI) (nc csiGroups, added bool) {
+	if b := color.ending(); b == c.ending() {
+		var g csiGroup
+		g, added = append(g, c, color), true
+		nc = csiGroups{g}
+	}
+	return
+}
+
+// Sub returns the code with the colon separated sub-parameters,
+// a negative one is empty. For example:
+//
+//	color.SGRunderline.Sub(3)                        // "4:3", curly underline
+//	color.SGRsetUnderlineColor.Sub(2, -1, 255, 0, 0) // "58:2::255:0:0"
+func (c CSIsgr) Sub(params ...int) SGRParam {
+	var sb strings.Builder
+	_, _ = sb.WriteString(strconv.Itoa(int(c)))
+	for _, p := range params {
+		_ = sb.WriteByte(':')
+		if p >= 0 {
+			_, _ = sb.WriteString(strconv.Itoa(p))
+		}
+	}
+	return SGRParam(sb.String())
+}
+
+// param returns the SGR parameter of the underline style:
+// "24", "4" or "4:n".
+func (u UnderlineStyle) param() SGRParam {
+	switch {
+	case u <= UnderlineNone:
+		return SGRParam(strconv.Itoa(int(SGRresetUnderline)))
+	case u == UnderlineSingle:
+		return SGRParam(strconv.Itoa(int(SGRunderline)))
+	}
+	return SGRunderline.Sub(int(min(u, UnderlineDashed)))
+}
+
+// The underline styles are [Color] values too, a style other
+// than UnderlineSingle is written as "4:n", which is supported
+// by kitty, WezTerm, iTerm2, VTE, ... The others may fall back
+// to a single underline or ignore it.
+//
+//	fmt.Println(color.UnderlineCurly.Wrap("typo"))
+
+func (u UnderlineStyle) leading() string       { return csi }
+func (u UnderlineStyle) ending() byte          { return 'm' }
+func (u UnderlineStyle) prologue(out CWriter)  { _, _ = out.WriteString(csi) }
+func (u UnderlineStyle) epilogue(out CWriter)  { _ = out.WriteByte('m') }
+func (u UnderlineStyle) core(out CWriter)      { u.param().core(out) }
+func (u UnderlineStyle) ColorTo(out io.Writer) { wrString(out, u.Color()) }
+func (u UnderlineStyle) String() string        { return u.Color() }
+func (u UnderlineStyle) Color() string         { return u.param().Color() }
+func (u UnderlineStyle) Int() int              { return int(u) }
+func (u UnderlineStyle) Wrap(text string) string {
+	return u.param().Wrap(text)
+}
+
+func (u UnderlineStyle) AddAndNew(colors ...Color) (newclr *Style) {
+	newclr = NewStyle().Add(u)
+	return newclr.Add(colors...)
+}
+
+func (u UnderlineStyle) TryAppend(color colorCSI) (nc csiGroups, added bool) {
+	return u.param().TryAppend(color)
+}
+
+// NewUnderlineColor returns the underline color (SGR 58) of c,
+// which is a [Color16] (fg or bg), [Color256] or [Color16m]. nil
+// or another color, such as a [Style], means the default
+// underline color (SGR 59).
+//
+//	color.NewStyle().Add(color.UnderlineCurly, color.NewUnderlineColor(color.FgRed))
+func NewUnderlineColor(c Color) SGRParam {
+	switch v := c.(type) {
+	case Color16:
+		if i := ansiIndex(v); i >= 0 {
+			return SGRsetUnderlineColor.Sub(5, i)
+		}
+	case Color256:
+		return SGRsetUnderlineColor.Sub(5, int(v.clr[0]))
+	case *Color256:
+		return SGRsetUnderlineColor.Sub(5, int(v.clr[0]))
+	case Color16m:
+		return SGRsetUnderlineColor.Sub(2, -1, int(v.clr[0]), int(v.clr[1]), int(v.clr[2]))
+	case *Color16m:
+		return SGRsetUnderlineColor.Sub(2, -1, int(v.clr[0]), int(v.clr[1]), int(v.clr[2]))
+	}
+	return SGRParam(strconv.Itoa(int(SGRdefaultUnderlineColor)))
+}
+
+// underlineStyles are the names of the underline styles in the
+// CPT markups, including the CSS text-decoration-style ones.
+var underlineStyles = map[string]UnderlineStyle{
+	"none": UnderlineNone, "single": UnderlineSingle, "solid": UnderlineSingle,
+	"double": UnderlineDouble, "curly": UnderlineCurly, "wavy": UnderlineCurly,
+	"dotted": UnderlineDotted, "dashed": UnderlineDashed,
+}
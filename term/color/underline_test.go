@@ -0,0 +1,64 @@
+package color
+
+import "testing"
+
+func TestUnderlineColors(t *testing.T) {
+	for _, c := range []struct {
+		clr  Color
+		want string
+	}{
+		{UnderlineNone, "\x1b[24m"},
+		{UnderlineSingle, "\x1b[4m"},
+		{UnderlineDouble, "\x1b[4:2m"},
+		{UnderlineCurly, "\x1b[4:3m"},
+		{UnderlineDotted, "\x1b[4:4m"},
+		{UnderlineDashed, "\x1b[4:5m"},
+		{SGRunderline.Sub(3), "\x1b[4:3m"},
+		{NewUnderlineColor(FgRed), "\x1b[58:5:1m"},
+		{NewUnderlineColor(BgLightBlue), "\x1b[58:5:12m"},
+		{NewUnderlineColor(NewColor256(208, false)), "\x1b[58:5:208m"},
+		{NewUnderlineColor(NewColor16m(255, 135, 0, false)), "\x1b[58:2::255:135:0m"},
+		{NewUnderlineColor(nil), "\x1b[59m"},
+		{FgRed.AddAndNew(UnderlineCurly), "\x1b[31;4:3m"},
+		{UnderlineDotted.AddAndNew(NewUnderlineColor(FgBlue)), "\x1b[4:4;58:5:4m"},
+		{FgRed.AddAndNew(NewColor256(208, true)), "\x1b[31;48;5;208m"},
+	} {
+		if got := c.clr.Color(); got != c.want {
+			t.Errorf("got %q, want %q", got, c.want)
+		}
+	}
+	if got, want := UnderlineCurly.Wrap("x"), "\x1b[4:3mx\x1b[0m"; got != want {
+		t.Errorf("Wrap: got %q, want %q", got, want)
+	}
+}
+
+func TestCPTUnderline(t *testing.T) {
+	for _, c := range []struct {
+		src  string
+		want SpanStyle
+	}{
+		{`<u>x</u>`, SpanStyle{Attrs: AttrUnderline, Underline: UnderlineSingle}},
+		{`<u style="curly">x</u>`, SpanStyle{Attrs: AttrUnderline, Underline: UnderlineCurly}},
+		{`<u style="wavy" color="red">x</u>`, SpanStyle{Attrs: AttrUnderline, Underline: UnderlineCurly, UnderlineColor: Color256{clr: [4]byte{1}}}},
+		{`<u style="dashed" color="#ff8700">x</u>`, SpanStyle{Attrs: AttrUnderline, Underline: UnderlineDashed, UnderlineColor: Color16m{clr: [4]byte{255, 135, 0}}}},
+		{`<u color="208">x</u>`, SpanStyle{Attrs: AttrUnderline, Underline: UnderlineSingle, UnderlineColor: Color256{clr: [4]byte{208}}}},
+	} {
+		spans := ParseANSI(GetCPTC().Translate(c.src, Reset))
+		if len(spans) != 1 || spans[0].Text != "x" || !spans[0].Style.Equal(c.want) {
+			t.Errorf("%s: got %+v, want %+v", c.src, spans, c.want)
+		}
+	}
+	if got, want := GetCPTNC().Translate(`<u style="curly" color="red">x</u>`, Reset), "x"; got != want {
+		t.Errorf("no color: got %q, want %q", got, want)
+	}
+
+	// a role of a style falls back to the default underline color
+	SetTheme(&Theme{Name: "t", Roles: map[Role]Color{RoleAccent: NewStyle().Add(BgBoldOrBright, FgRed), "link": FgBlue}})
+	defer SetTheme(nil)
+	if got, want := GetCPTC().Translate(`<u color="accent">x</u>`, Reset), "\x1b[4mx\x1b[0m"; got != want {
+		t.Errorf("style role: got %q, want %q", got, want)
+	}
+	if got, want := GetCPTC().Translate(`<u color="link">x</u>`, Reset), "\x1b[4;58:5:4mx\x1b[0m"; got != want {
+		t.Errorf("color role: got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,113 @@
+package color
+
+import (
+	"unicode"
+)
+
+// runeWidth returns the count of terminal columns occupied by r.
+//
+// It's 0 for the control and combining characters, 2 for the
+// East Asian wide/fullwidth characters and most emoji, or 1.
+func runeWidth(r rune) int {
+	switch {
+	case r == 0:
+		return 0
+	case r < 0x20 || (r >= 0x7f && r < 0xa0):
+		return 0
+	case r < 0x300:
+		return 1
+	case r == 0x200b || r == 0x200c || r == 0x200d || r == 0x2060 || r == 0xfeff:
+		return 0 // zero width space, ZWNJ, ZWJ, word joiner, BOM
+	case unicode.In(r, unicode.Mn, unicode.Me, unicode.Cf):
+		return 0
+	case isWideRune(r):
+		return 2
+	}
+	return 1
+}
+
+// wideRanges holds the East Asian Wide (W) and Fullwidth (F)
+// ranges, plus the emoji presentation blocks.
+var wideRanges = [][2]rune{
+	{0x1100, 0x115f},   // Hangul Jamo init. consonants
+	{0x231a, 0x231b},   // watch, hourglass
+	{0x2329, 0x232a},   // angle brackets
+	{0x23e9, 0x23ec},   //
+	{0x23f0, 0x23f0},   //
+	{0x23f3, 0x23f3},   //
+	{0x25fd, 0x25fe},   //
+	{0x2614, 0x2615},   //
+	{0x2648, 0x2653},   // zodiac
+	{0x267f, 0x267f},   //
+	{0x2693, 0x2693},   //
+	{0x26a1, 0x26a1},   //
+	{0x26aa, 0x26ab},   //
+	{0x26bd, 0x26be},   //
+	{0x26c4, 0x26c5},   //
+	{0x26ce, 0x26ce},   //
+	{0x26d4, 0x26d4},   //
+	{0x26ea, 0x26ea},   //
+	{0x26f2, 0x26f3},   //
+	{0x26f5, 0x26f5},   //
+	{0x26fa, 0x26fa},   //
+	{0x26fd, 0x26fd},   //
+	{0x2705, 0x2705},   //
+	{0x270a, 0x270b},   //
+	{0x2728, 0x2728},   //
+	{0x274c, 0x274c},   //
+	{0x274e, 0x274e},   //
+	{0x2753, 0x2755},   //
+	{0x2757, 0x2757},   //
+	{0x2795, 0x2797},   //
+	{0x27b0, 0x27b0},   //
+	{0x27bf, 0x27bf},   //
+	{0x2b1b, 0x2b1c},   //
+	{0x2b50, 0x2b50},   //
+	{0x2b55, 0x2b55},   //
+	{0x2e80, 0x303e},   // CJK radicals .. CJK symbols and punctuation
+	{0x3041, 0x33ff},   // Hiragana .. CJK compatibility
+	{0x3400, 0x4dbf},   // CJK unified ideographs extension A
+	{0x4e00, 0x9fff},   // CJK unified ideographs
+	{0xa000, 0xa4cf},   // Yi
+	{0xa960, 0xa97f},   // Hangul Jamo extended-A
+	{0xac00, 0xd7a3},   // Hangul syllables
+	{0xf900, 0xfaff},   // CJK compatibility ideographs
+	{0xfe10, 0xfe19},   // vertical forms
+	{0xfe30, 0xfe6f},   // CJK compatibility forms, small form variants
+	{0xff00, 0xff60},   // fullwidth forms
+	{0xffe0, 0xffe6},   // fullwidth signs
+	{0x16fe0, 0x16fe4}, //
+	{0x17000, 0x18cff}, // Tangut
+	{0x1b000, 0x1b2ff}, // Kana supplement, Nushu
+	{0x1f004, 0x1f004}, // mahjong
+	{0x1f0cf, 0x1f0cf}, // playing card
+	{0x1f18e, 0x1f18e}, //
+	{0x1f191, 0x1f19a}, //
+	{0x1f200, 0x1f251}, // enclosed ideographic supplement
+	{0x1f300, 0x1f64f}, // misc symbols and pictographs, emoticons
+	{0x1f680, 0x1f6ff}, // transport and map symbols
+	{0x1f7e0, 0x1f7eb}, // colored circles and squares
+	{0x1f90c, 0x1f9ff}, // supplemental symbols and pictographs
+	{0x1fa70, 0x1faff}, // symbols and pictographs extended-A
+	{0x20000, 0x2fffd}, // CJK unified ideographs extension B..F
+	{0x30000, 0x3fffd}, // CJK unified ideographs extension G..
+}
+
+func isWideRune(r rune) bool {
+	if r < wideRanges[0][0] {
+		return false
+	}
+	lo, hi := 0, len(wideRanges)-1
+	for lo <= hi {
+		mid := (lo + hi) / 2
+		switch {
+		case r < wideRanges[mid][0]:
+			hi = mid - 1
+		case r > wideRanges[mid][1]:
+			lo = mid + 1
+		default:
+			return true
+		}
+	}
+	return false
+}
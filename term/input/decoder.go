@@ -0,0 +1,365 @@
+package input
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Decoder turns the raw terminal input into events.
+//
+// The input can be fed in any fragments, an incomplete
+// sequence is kept till the following bytes arrived. A lone
+// ESC is ambiguous (an Escape key, or the beginning of a
+// sequence), it's kept till [Decoder.Flush] called, which
+// should be done after the input has been idle for a while.
+//
+// A Decoder is not safe for concurrent use.
+type Decoder struct {
+	buf     []byte
+	pasting bool
+}
+
+// NewDecoder returns a new Decoder.
+func NewDecoder() *Decoder { return &Decoder{} }
+
+// Feed decodes data and returns the events completed.
+func (d *Decoder) Feed(data []byte) []Event {
+	d.buf = append(d.buf, data...)
+	return d.decode(false)
+}
+
+// Flush decodes the pending bytes as complete input, for
+// example, a pending ESC becomes an Escape key.
+//
+// The pending text of a bracketed paste is kept.
+func (d *Decoder) Flush() []Event {
+	return d.decode(true)
+}
+
+// Pending reports whether there are bytes not decoded yet.
+func (d *Decoder) Pending() bool { return len(d.buf) > 0 }
+
+const (
+	pasteStart = "\x1b[200~"
+	pasteEnd   = "\x1b[201~"
+)
+
+func (d *Decoder) decode(final bool) (events []Event) {
+	for len(d.buf) > 0 {
+		if d.pasting {
+			i := bytes.Index(d.buf, []byte(pasteEnd))
+			if i < 0 {
+				break // waiting for more text
+			}
+			events = append(events, PasteEvent{Text: string(d.buf[:i])})
+			d.buf = d.buf[i+len(pasteEnd):]
+			d.pasting = false
+			continue
+		}
+
+		if bytes.HasPrefix(d.buf, []byte(pasteStart)) {
+			d.buf = d.buf[len(pasteStart):]
+			d.pasting = true
+			continue
+		}
+
+		ev, n := parse(d.buf, final)
+		if n == 0 {
+			break
+		}
+		if ev != nil {
+			events = append(events, ev)
+		}
+		d.buf = d.buf[n:]
+	}
+	if len(d.buf) == 0 {
+		d.buf = d.buf[:0:0] // release the underlying array
+	}
+	return
+}
+
+// parse decodes one event at the beginning of b. The n result
+// is the count of bytes consumed, or 0 if b is incomplete.
+func parse(b []byte, final bool) (ev Event, n int) {
+	c := b[0]
+	switch {
+	case c == 0x1b:
+		return parseEscape(b, final)
+	case c == '\r' || c == '\n':
+		return KeyEvent{Key: KeyEnter}, 1
+	case c == '\t':
+		return KeyEvent{Key: KeyTab}, 1
+	case c == 0x7f:
+		return KeyEvent{Key: KeyBackspace}, 1
+	case c == 0x08:
+		return KeyEvent{Key: KeyBackspace, Mod: ModCtrl}, 1
+	case c == 0:
+		return KeyEvent{Key: KeyRune, Rune: ' ', Mod: ModCtrl}, 1
+	case c < 0x1b:
+		return KeyEvent{Key: KeyRune, Rune: rune('a' + c - 1), Mod: ModCtrl}, 1
+	case c < 0x20:
+		return KeyEvent{Key: KeyRune, Rune: rune(`\]^_`[c-0x1c]), Mod: ModCtrl}, 1
+	case c < utf8.RuneSelf:
+		return KeyEvent{Key: KeyRune, Rune: rune(c)}, 1
+	}
+
+	if !utf8.FullRune(b) {
+		if final {
+			return KeyEvent{Key: KeyRune, Rune: utf8.RuneError}, 1
+		}
+		return nil, 0
+	}
+	r, size := utf8.DecodeRune(b)
+	return KeyEvent{Key: KeyRune, Rune: r}, size
+}
+
+func parseEscape(b []byte, final bool) (ev Event, n int) {
+	if len(b) == 1 {
+		if final {
+			return KeyEvent{Key: KeyEscape}, 1
+		}
+		return nil, 0
+	}
+
+	switch b[1] {
+	case '[':
+		if ev, n = parseCSI(b, final); n > 0 || !final {
+			return
+		}
+	case 'O':
+		if ev, n = parseSS3(b); n > 0 || !final {
+			return
+		}
+	case 0x1b:
+		// ESC ESC [ A: some terminals send Alt+Up in this way
+		if len(b) > 2 && (b[2] == '[' || b[2] == 'O') {
+			if ev, n = parseEscape(b[1:], final); n > 0 {
+				return withAlt(ev), n + 1
+			}
+			return
+		}
+		if len(b) == 2 && !final {
+			return nil, 0
+		}
+		return KeyEvent{Key: KeyEscape, Mod: ModAlt}, 2
+	}
+
+	// Alt + key
+	if ev, n = parse(b[1:], final); n == 0 {
+		return
+	}
+	return withAlt(ev), n + 1
+}
+
+func withAlt(ev Event) Event {
+	switch e := ev.(type) {
+	case KeyEvent:
+		e.Mod |= ModAlt
+		return e
+	}
+	return ev
+}
+
+var ss3Keys = map[byte]Key{
+	'A': KeyUp, 'B': KeyDown, 'C': KeyRight, 'D': KeyLeft,
+	'H': KeyHome, 'F': KeyEnd, 'E': KeyBegin, 'M': KeyEnter,
+	'P': KeyF1, 'Q': KeyF2, 'R': KeyF3, 'S': KeyF4,
+}
+
+// ss3Keypad maps the application keypad keys to their characters.
+const ss3Keypad = "jklmnopqrstuvwxyX" // * + , - . / 0..9 =
+
+// parseSS3 decodes `ESC O x`.
+func parseSS3(b []byte) (ev Event, n int) {
+	if len(b) < 3 {
+		return nil, 0
+	}
+	if k, ok := ss3Keys[b[2]]; ok {
+		return KeyEvent{Key: k}, 3
+	}
+	if i := strings.IndexByte(ss3Keypad, b[2]); i >= 0 {
+		return KeyEvent{Key: KeyRune, Rune: rune("*+,-./0123456789="[i])}, 3
+	}
+	return UnknownEvent{Raw: string(b[:3])}, 3
+}
+
+var csiLetterKeys = map[byte]Key{
+	'A': KeyUp, 'B': KeyDown, 'C': KeyRight, 'D': KeyLeft,
+	'H': KeyHome, 'F': KeyEnd, 'E': KeyBegin,
+	'P': KeyF1, 'Q': KeyF2, 'R': KeyF3, 'S': KeyF4,
+}
+
+var csiTildeKeys = map[int]Key{
+	1: KeyHome, 2: KeyInsert, 3: KeyDelete, 4: KeyEnd, 5: KeyPgUp, 6: KeyPgDn, 7: KeyHome, 8: KeyEnd,
+	11: KeyF1, 12: KeyF2, 13: KeyF3, 14: KeyF4, 15: KeyF5,
+	17: KeyF6, 18: KeyF7, 19: KeyF8, 20: KeyF9, 21: KeyF10,
+	23: KeyF11, 24: KeyF12, 25: KeyF13, 26: KeyF14, 28: KeyF15, 29: KeyF16,
+	31: KeyF17, 32: KeyF18, 33: KeyF19, 34: KeyF20,
+}
+
+// parseCSI decodes `ESC [ params intermediates final`.
+func parseCSI(b []byte, final bool) (ev Event, n int) {
+	if len(b) < 3 {
+		return nil, 0
+	}
+
+	// X10 mouse: ESC [ M cb cx cy
+	if b[2] == 'M' {
+		if len(b) < 6 {
+			if final {
+				return UnknownEvent{Raw: string(b)}, len(b)
+			}
+			return nil, 0
+		}
+		ev = mouseEvent(int(b[3])-32, int(b[4])-32, int(b[5])-32, false)
+		return ev, 6
+	}
+
+	i := 2
+	for i < len(b) && b[i] >= 0x20 && b[i] <= 0x3f {
+		i++
+	}
+	if i >= len(b) {
+		if final {
+			return UnknownEvent{Raw: string(b)}, len(b)
+		}
+		return nil, 0
+	}
+	fin := b[i]
+	n = i + 1
+	if fin < 0x40 || fin > 0x7e {
+		return UnknownEvent{Raw: string(b[:i])}, i
+	}
+
+	raw := string(b[2:i])
+	var marker byte
+	if raw != "" && strings.IndexByte("<=>?", raw[0]) >= 0 {
+		marker, raw = raw[0], raw[1:]
+	}
+	p := parseParams(raw)
+	param := func(i, def int) int {
+		if i < len(p) && p[i] >= 0 {
+			return p[i]
+		}
+		return def
+	}
+
+	unknown := UnknownEvent{Raw: string(b[:n])}
+	switch {
+	case marker == '<' && (fin == 'M' || fin == 'm'):
+		if len(p) < 3 {
+			return unknown, n
+		}
+		return mouseEvent(p[0], p[1], p[2], fin == 'm'), n
+	case marker != 0:
+		return unknown, n
+	}
+
+	switch fin {
+	case '~':
+		code, mod := param(0, 0), modFromParam(param(1, 1))
+		if code == 27 { // xterm modifyOtherKeys: CSI 27;mod;code ~
+			return codepointKey(param(2, 0), modFromParam(param(1, 1))), n
+		}
+		if code == 201 {
+			return nil, n // a stray paste end
+		}
+		if k, ok := csiTildeKeys[code]; ok {
+			return KeyEvent{Key: k, Mod: mod}, n
+		}
+	case 'u': // CSI code[:shifted] ; mod u
+		return codepointKey(param(0, 0), modFromParam(param(1, 1))), n
+	case 'Z':
+		return KeyEvent{Key: KeyTab, Mod: ModShift}, n
+	case 'I', 'O':
+		if len(p) == 0 {
+			return FocusEvent{Focused: fin == 'I'}, n
+		}
+	case 't':
+		if code := param(0, 0); (code == 8 || code == 48) && len(p) >= 3 {
+			return ResizeEvent{Cols: param(2, 0), Rows: param(1, 0)}, n
+		}
+	default:
+		if k, ok := csiLetterKeys[fin]; ok {
+			return KeyEvent{Key: k, Mod: modFromParam(param(1, 1))}, n
+		}
+	}
+	return unknown, n
+}
+
+// parseParams splits the CSI parameters, the sub-parameters
+// (after ':') are dropped. An omitted parameter is -1.
+func parseParams(s string) (p []int) {
+	if s == "" {
+		return
+	}
+	for _, f := range strings.Split(s, ";") {
+		if i := strings.IndexByte(f, ':'); i >= 0 {
+			f = f[:i]
+		}
+		v, err := strconv.Atoi(f)
+		if err != nil {
+			v = -1
+		}
+		p = append(p, v)
+	}
+	return
+}
+
+// codepointKey makes a key event from a CSI-u codepoint.
+func codepointKey(code int, mod Mod) Event {
+	switch code {
+	case 13:
+		return KeyEvent{Key: KeyEnter, Mod: mod}
+	case 9:
+		return KeyEvent{Key: KeyTab, Mod: mod}
+	case 27:
+		return KeyEvent{Key: KeyEscape, Mod: mod}
+	case 8, 127:
+		return KeyEvent{Key: KeyBackspace, Mod: mod}
+	}
+	if code <= 0 || code > utf8.MaxRune {
+		return KeyEvent{Key: KeyUnknown, Mod: mod}
+	}
+	return KeyEvent{Key: KeyRune, Rune: rune(code), Mod: mod}
+}
+
+// mouseEvent decodes the button code of xterm mouse reports.
+func mouseEvent(cb, x, y int, release bool) MouseEvent {
+	ev := MouseEvent{X: x, Y: y, Action: MousePress}
+	if cb&4 != 0 {
+		ev.Mod |= ModShift
+	}
+	if cb&8 != 0 {
+		ev.Mod |= ModAlt
+	}
+	if cb&16 != 0 {
+		ev.Mod |= ModCtrl
+	}
+	motion := cb&32 != 0
+	cb &^= 4 | 8 | 16 | 32
+
+	switch {
+	case cb >= 128:
+		ev.Button = MouseBackward + MouseButton(cb-128)
+	case cb >= 64:
+		ev.Button = MouseWheelUp + MouseButton(cb-64)
+	case cb == 3:
+		ev.Button = MouseNone // X10 release, or motion without button
+		if !motion {
+			ev.Action = MouseRelease
+		}
+	default:
+		ev.Button = MouseLeft + MouseButton(cb)
+	}
+
+	switch {
+	case release:
+		ev.Action = MouseRelease
+	case motion:
+		ev.Action = MouseMotion
+	}
+	return ev
+}
@@ -0,0 +1,93 @@
+package input
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func decodeAll(chunks ...string) (res []string) {
+	d := NewDecoder()
+	for _, c := range chunks {
+		for _, ev := range d.Feed([]byte(c)) {
+			res = append(res, ev.String())
+		}
+	}
+	for _, ev := range d.Flush() {
+		res = append(res, ev.String())
+	}
+	return
+}
+
+func TestDecoder(t *testing.T) {
+	tests := []struct {
+		in   []string
+		want string
+	}{
+		{[]string{"ab中"}, "a b 中"},
+		{[]string{"\r\t\x7f\x03\x00"}, "Enter Tab Backspace Ctrl+c Ctrl+ "},
+		{[]string{"\x1b"}, "Escape"},
+		{[]string{"\x1bx", "\x1b\x1b"}, "Alt+x Alt+Escape"},
+		{[]string{"\x1b[A\x1b[1;5B\x1bOC\x1b[1;3D"}, "Up Ctrl+Down Right Alt+Left"},
+		{[]string{"\x1b[H\x1b[F\x1b[1;2H\x1b[4~\x1b[5;5~\x1b[3~"}, "Home End Shift+Home End Ctrl+PgUp Delete"},
+		{[]string{"\x1bOP\x1b[1;2Q\x1b[15~\x1b[24;6~"}, "F1 Shift+F2 F5 Shift+Ctrl+F12"},
+		{[]string{"\x1b[Z\x1b\x1b[A"}, "Shift+Tab Alt+Up"},
+		{[]string{"\x1b[97;5u\x1b[13;2u\x1b[27;5;106~"}, "Ctrl+a Shift+Enter Ctrl+j"},
+		{[]string{"\x1b[200~hello\x1b[A", "\nworld\x1b[201~x"}, `Paste("hello\x1b[A\nworld") x`},
+		{[]string{"\x1b[I\x1b[O"}, "FocusIn FocusOut"},
+		{[]string{"\x1b[<0;10;20M\x1b[<0;10;20m"}, "Mouse(Left Press 10,20) Mouse(Left Release 10,20)"},
+		{[]string{"\x1b[<34;3;4M\x1b[<64;1;1M\x1b[<81;2;2M"}, "Mouse(Right Motion 3,4) Mouse(WheelUp Press 1,1) Mouse(Ctrl+WheelDown Press 2,2)"},
+		{[]string{"\x1b[M !!\x1b[M#!!"}, "Mouse(Left Press 1,1) Mouse(None Release 1,1)"},
+		{[]string{"\x1b[48;40;120;800;1920t"}, "Resize(120x40)"},
+		{[]string{"\x1b[99x"}, `Unknown("\x1b[99x")`},
+		// split at any place
+		{[]string{"\x1b", "[", "1;5", "A", "\xe4\xb8", "\xad"}, "Ctrl+Up 中"},
+		{[]string{"\x1b[<0;1", "0;20M"}, "Mouse(Left Press 10,20)"},
+	}
+	for i, tc := range tests {
+		if got := strings.Join(decodeAll(tc.in...), " "); got != tc.want {
+			t.Errorf("%d. decode %q:\n got %s\nwant %s", i, tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestDecoderPendingEsc(t *testing.T) {
+	d := NewDecoder()
+	if evs := d.Feed([]byte("\x1b")); len(evs) != 0 || !d.Pending() {
+		t.Fatalf("a lone ESC should be pending, got %v", evs)
+	}
+	if evs := d.Feed([]byte("[A")); len(evs) != 1 || evs[0] != (KeyEvent{Key: KeyUp}) {
+		t.Fatalf("want Up, got %v", evs)
+	}
+}
+
+type chunkReader struct {
+	chunks []string
+	delay  time.Duration
+}
+
+func (r *chunkReader) Read(p []byte) (n int, err error) {
+	if len(r.chunks) == 0 {
+		time.Sleep(r.delay)
+		return 0, context.Canceled
+	}
+	time.Sleep(r.delay)
+	n = copy(p, r.chunks[0])
+	r.chunks = r.chunks[1:]
+	return
+}
+
+func TestEvents(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	r := &chunkReader{chunks: []string{"a", "\x1b", "b\x1b[B"}, delay: 30 * time.Millisecond}
+	var got []string
+	for ev := range Events(ctx, r, WithEscTimeout(10*time.Millisecond)) {
+		got = append(got, ev.String())
+	}
+	if s := strings.Join(got, " "); s != "a Escape b Down" {
+		t.Errorf("got %s", s)
+	}
+}
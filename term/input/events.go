@@ -0,0 +1,277 @@
+// Package input decodes the raw bytes read from a terminal in
+// raw mode into typed events.
+//
+// The supported inputs are:
+//
+//   - the printable characters (UTF-8), Ctrl and Alt combinations
+//   - arrow, function, Home/End, PgUp/PgDn, Insert/Delete keys with
+//     modifiers, in both xterm (CSI) and application (SS3) forms
+//   - the CSI-u (fixterms/kitty) key reports
+//   - bracketed paste (DECSET 2004)
+//   - focus in/out (DECSET 1004)
+//   - SGR-1006 mouse reports, and the legacy X10 mouse reports
+//   - resize notifications, from SIGWINCH or in-band (DECSET 2048)
+//
+// A [Decoder] can be fed with byte streams directly, which is
+// useful in testing. [Events] reads a reader (such as os.Stdin)
+// and delivers the events on a channel:
+//
+//	restore, err := term.MakeRawWrapped()
+//	if err != nil { ... }
+//	defer restore()
+//
+//	for ev := range input.Events(ctx, os.Stdin, input.WithResize(os.Stdout)) {
+//	  switch e := ev.(type) {
+//	  case input.KeyEvent:
+//	    if e.Key == input.KeyRune && e.Rune == 'q' { return }
+//	  case input.MouseEvent:
+//	    ...
+//	  }
+//	}
+package input
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Event is an input event, one of [KeyEvent], [MouseEvent],
+// [PasteEvent], [FocusEvent], [ResizeEvent] and [UnknownEvent].
+type Event interface {
+	String() string
+}
+
+// Mod is a set of key modifiers.
+type Mod uint8
+
+const (
+	ModShift Mod = 1 << iota
+	ModAlt
+	ModCtrl
+	ModMeta
+
+	ModNone Mod = 0
+)
+
+func (m Mod) String() string {
+	var sb strings.Builder
+	for i, name := range []string{"Shift", "Alt", "Ctrl", "Meta"} {
+		if m&(1<<i) != 0 {
+			_, _ = sb.WriteString(name)
+			_ = sb.WriteByte('+')
+		}
+	}
+	return sb.String()
+}
+
+// modFromParam decodes the xterm modifier parameter, which is
+// 1 + (shift:1 | alt:2 | ctrl:4 | meta:8).
+func modFromParam(n int) Mod {
+	if n <= 1 {
+		return ModNone
+	}
+	return Mod(n-1) & (ModShift | ModAlt | ModCtrl | ModMeta)
+}
+
+// Key identifies a key, KeyRune means a character key and
+// its character is in [KeyEvent.Rune].
+type Key int
+
+const (
+	KeyUnknown Key = iota
+	KeyRune
+	KeyEnter
+	KeyTab
+	KeyBackspace
+	KeyEscape
+	KeyUp
+	KeyDown
+	KeyRight
+	KeyLeft
+	KeyHome
+	KeyEnd
+	KeyPgUp
+	KeyPgDn
+	KeyInsert
+	KeyDelete
+	KeyBegin // the center key (keypad 5)
+	KeyF1
+	KeyF2
+	KeyF3
+	KeyF4
+	KeyF5
+	KeyF6
+	KeyF7
+	KeyF8
+	KeyF9
+	KeyF10
+	KeyF11
+	KeyF12
+	KeyF13
+	KeyF14
+	KeyF15
+	KeyF16
+	KeyF17
+	KeyF18
+	KeyF19
+	KeyF20
+)
+
+var keyNames = map[Key]string{
+	KeyUnknown:   "Unknown",
+	KeyRune:      "Rune",
+	KeyEnter:     "Enter",
+	KeyTab:       "Tab",
+	KeyBackspace: "Backspace",
+	KeyEscape:    "Escape",
+	KeyUp:        "Up",
+	KeyDown:      "Down",
+	KeyRight:     "Right",
+	KeyLeft:      "Left",
+	KeyHome:      "Home",
+	KeyEnd:       "End",
+	KeyPgUp:      "PgUp",
+	KeyPgDn:      "PgDn",
+	KeyInsert:    "Insert",
+	KeyDelete:    "Delete",
+	KeyBegin:     "Begin",
+}
+
+func (k Key) String() string {
+	if k >= KeyF1 && k <= KeyF20 {
+		return "F" + strconv.Itoa(int(k-KeyF1)+1)
+	}
+	if s, ok := keyNames[k]; ok {
+		return s
+	}
+	return "Key(" + strconv.Itoa(int(k)) + ")"
+}
+
+// KeyEvent is a key press.
+//
+// Ctrl+letter is reported as KeyRune with ModCtrl and the
+// lowercase letter, for example, Ctrl+C is
+// `KeyEvent{Key: KeyRune, Rune: 'c', Mod: ModCtrl}`.
+type KeyEvent struct {
+	Key  Key
+	Rune rune // valid if Key is KeyRune
+	Mod  Mod
+}
+
+func (e KeyEvent) String() string {
+	if e.Key == KeyRune {
+		return e.Mod.String() + string(e.Rune)
+	}
+	return e.Mod.String() + e.Key.String()
+}
+
+// Is reports whether the event is the key r with exactly the
+// modifiers mod, such as `ev.Is('c', input.ModCtrl)`.
+func (e KeyEvent) Is(r rune, mod Mod) bool {
+	return e.Key == KeyRune && e.Rune == r && e.Mod == mod
+}
+
+// MouseButton identifies a mouse button or wheel direction.
+type MouseButton int
+
+const (
+	MouseNone MouseButton = iota
+	MouseLeft
+	MouseMiddle
+	MouseRight
+	MouseWheelUp
+	MouseWheelDown
+	MouseWheelLeft
+	MouseWheelRight
+	MouseBackward // button 8
+	MouseForward  // button 9
+)
+
+func (b MouseButton) String() string {
+	names := []string{"None", "Left", "Middle", "Right", "WheelUp", "WheelDown", "WheelLeft", "WheelRight", "Backward", "Forward"}
+	if b >= 0 && int(b) < len(names) {
+		return names[b]
+	}
+	return "Button(" + strconv.Itoa(int(b)) + ")"
+}
+
+// MouseAction tells what happened to a mouse button.
+type MouseAction int
+
+const (
+	MousePress MouseAction = iota
+	MouseRelease
+	MouseMotion
+)
+
+func (a MouseAction) String() string {
+	switch a {
+	case MousePress:
+		return "Press"
+	case MouseRelease:
+		return "Release"
+	}
+	return "Motion"
+}
+
+// MouseEvent is a mouse report, X and Y are 1-based cell
+// coordinates.
+type MouseEvent struct {
+	X, Y   int
+	Button MouseButton
+	Action MouseAction
+	Mod    Mod
+}
+
+func (e MouseEvent) String() string {
+	return "Mouse(" + e.Mod.String() + e.Button.String() + " " + e.Action.String() +
+		" " + strconv.Itoa(e.X) + "," + strconv.Itoa(e.Y) + ")"
+}
+
+// PasteEvent holds the text pasted in bracketed paste mode.
+type PasteEvent struct {
+	Text string
+}
+
+func (e PasteEvent) String() string { return "Paste(" + strconv.Quote(e.Text) + ")" }
+
+// FocusEvent reports the terminal window gained or lost focus.
+type FocusEvent struct {
+	Focused bool
+}
+
+func (e FocusEvent) String() string {
+	if e.Focused {
+		return "FocusIn"
+	}
+	return "FocusOut"
+}
+
+// ResizeEvent reports the new size of the terminal.
+type ResizeEvent struct {
+	Cols, Rows int
+}
+
+func (e ResizeEvent) String() string {
+	return "Resize(" + strconv.Itoa(e.Cols) + "x" + strconv.Itoa(e.Rows) + ")"
+}
+
+// UnknownEvent holds an escape sequence which cannot be decoded.
+type UnknownEvent struct {
+	Raw string
+}
+
+func (e UnknownEvent) String() string { return "Unknown(" + strconv.Quote(e.Raw) + ")" }
+
+// The sequences to enable/disable the optional reports.
+const (
+	EnableMouse           = "\x1b[?1000h\x1b[?1002h\x1b[?1006h" // button, drag, SGR encoding
+	EnableMouseAll        = "\x1b[?1000h\x1b[?1003h\x1b[?1006h" // button, any motion, SGR encoding
+	DisableMouse          = "\x1b[?1006l\x1b[?1003l\x1b[?1002l\x1b[?1000l"
+	EnableBracketedPaste  = "\x1b[?2004h"
+	DisableBracketedPaste = "\x1b[?2004l"
+	EnableFocus           = "\x1b[?1004h"
+	DisableFocus          = "\x1b[?1004l"
+	EnableResizeReport    = "\x1b[?2048h" // in-band resize notifications
+	DisableResizeReport   = "\x1b[?2048l"
+)
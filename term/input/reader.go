@@ -0,0 +1,139 @@
+package input
+
+import (
+	"context"
+	"io"
+	"time"
+)
+
+// Opt is a functional option for [Events].
+type Opt func(s *reader)
+
+// WithEscTimeout sets the idle duration after which a pending
+// ESC is treated as the Escape key, default is 50ms.
+func WithEscTimeout(d time.Duration) Opt {
+	return func(s *reader) {
+		if d > 0 {
+			s.escTimeout = d
+		}
+	}
+}
+
+// WithResize emits a [ResizeEvent] with the size of fd after
+// the terminal resized (SIGWINCH). The current size is
+// emitted at first.
+//
+// On the platforms without SIGWINCH, the size is polled.
+func WithResize(fd interface{ Fd() uintptr }) Opt {
+	return func(s *reader) {
+		s.resizeFd = fd
+	}
+}
+
+// WithBufferSize sets the capacity of the events channel,
+// default is 32.
+func WithBufferSize(n int) Opt {
+	return func(s *reader) {
+		if n >= 0 {
+			s.bufSize = n
+		}
+	}
+}
+
+type reader struct {
+	escTimeout time.Duration
+	resizeFd   interface{ Fd() uintptr }
+	bufSize    int
+}
+
+// Events reads r (generally os.Stdin in raw mode) and delivers
+// the decoded events on the returned channel, which will be
+// closed after ctx done, or r reached EOF or failed.
+//
+// Note that a blocking Read cannot be interrupted, the reading
+// goroutine exits at the next returning of Read after ctx done.
+func Events(ctx context.Context, r io.Reader, opts ...Opt) <-chan Event {
+	s := &reader{escTimeout: 50 * time.Millisecond, bufSize: 32}
+	for _, opt := range opts {
+		opt(s)
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	out := make(chan Event, s.bufSize)
+	chunks := make(chan []byte)
+	go readChunks(ctx, r, chunks)
+
+	var resized <-chan ResizeEvent
+	if s.resizeFd != nil {
+		resized = watchResize(ctx, s.resizeFd)
+	}
+
+	go func() {
+		defer close(out)
+
+		d := NewDecoder()
+		idle := time.NewTimer(s.escTimeout)
+		idle.Stop()
+		defer idle.Stop()
+
+		send := func(events []Event) bool {
+			for _, ev := range events {
+				select {
+				case out <- ev:
+				case <-ctx.Done():
+					return false
+				}
+			}
+			return true
+		}
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case data, ok := <-chunks:
+				if !ok {
+					send(d.Flush())
+					return
+				}
+				if !send(d.Feed(data)) {
+					return
+				}
+				if d.Pending() {
+					idle.Reset(s.escTimeout)
+				}
+			case <-idle.C:
+				if !send(d.Flush()) {
+					return
+				}
+			case ev := <-resized:
+				if !send([]Event{ev}) {
+					return
+				}
+			}
+		}
+	}()
+	return out
+}
+
+func readChunks(ctx context.Context, r io.Reader, chunks chan<- []byte) {
+	defer close(chunks)
+	buf := make([]byte, 256)
+	for {
+		n, err := r.Read(buf)
+		if n > 0 {
+			data := make([]byte, n)
+			copy(data, buf[:n])
+			select {
+			case chunks <- data:
+			case <-ctx.Done():
+				return
+			}
+		}
+		if err != nil {
+			return
+		}
+	}
+}
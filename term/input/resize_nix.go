@@ -0,0 +1,39 @@
+//go:build !windows && !plan9 && !appengine && !wasm
+// +build !windows,!plan9,!appengine,!wasm
+
+package input
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/hedzr/is/term"
+)
+
+// watchResize sends the size of fd at first, and after each
+// SIGWINCH caught.
+func watchResize(ctx context.Context, fd interface{ Fd() uintptr }) <-chan ResizeEvent {
+	ch := make(chan ResizeEvent, 1)
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, syscall.SIGWINCH)
+	go func() {
+		defer signal.Stop(sig)
+		for {
+			if cols, rows, err := term.GetTtySizeByFd(fd.Fd()); err == nil {
+				select {
+				case ch <- ResizeEvent{Cols: cols, Rows: rows}:
+				case <-ctx.Done():
+					return
+				}
+			}
+			select {
+			case <-sig:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return ch
+}
@@ -0,0 +1,40 @@
+//go:build windows || plan9 || appengine || wasm
+// +build windows plan9 appengine wasm
+
+package input
+
+import (
+	"context"
+	"time"
+
+	"github.com/hedzr/is/term"
+)
+
+// watchResize polls the size of fd since there is no SIGWINCH,
+// the current size is sent at first, and after each change.
+func watchResize(ctx context.Context, fd interface{ Fd() uintptr }) <-chan ResizeEvent {
+	ch := make(chan ResizeEvent, 1)
+	go func() {
+		ticker := time.NewTicker(250 * time.Millisecond)
+		defer ticker.Stop()
+		var last ResizeEvent
+		for {
+			if cols, rows, err := term.GetTtySizeByFd(fd.Fd()); err == nil {
+				if ev := (ResizeEvent{Cols: cols, Rows: rows}); ev != last {
+					last = ev
+					select {
+					case ch <- ev:
+					case <-ctx.Done():
+						return
+					}
+				}
+			}
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return ch
+}
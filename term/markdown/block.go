@@ -0,0 +1,324 @@
+package markdown
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/hedzr/is/term/table"
+)
+
+type blockKind int
+
+const (
+	blockParagraph blockKind = iota
+	blockHeading
+	blockCode
+	blockQuote
+	blockList
+	blockTable
+	blockRule
+)
+
+// block is a block of a markdown document.
+type block struct {
+	kind     blockKind
+	level    int      // of a heading
+	text     string   // the inline source of a paragraph or heading
+	lang     string   // the info string of a fenced code block
+	lines    []string // of a code block
+	children []*block // of a block quote
+	items    [][]*block
+	ordered  bool
+	loose    bool // the list items are separated by the blank lines
+	start    int  // the first number of an ordered list
+	header   []string
+	aligns   []table.Align
+	rows     [][]string
+}
+
+var (
+	reATX      = regexp.MustCompile(`^ {0,3}(#{1,6})(?:[ \t]+(.*?))?(?:[ \t]+#+)?[ \t]*$`)
+	reRule     = regexp.MustCompile(`^ {0,3}(?:(?:\*[ \t]*){3,}|(?:-[ \t]*){3,}|(?:_[ \t]*){3,})$`)
+	reSetext   = regexp.MustCompile(`^ {0,3}(=+|-+)[ \t]*$`)
+	reFence    = regexp.MustCompile("^( {0,3})(`{3,}|~{3,})[ \t]*(.*?)[ \t]*$")
+	reQuote    = regexp.MustCompile(`^ {0,3}> ?(.*)$`)
+	reList     = regexp.MustCompile(`^( {0,3})([-+*]|\d{1,9}[.)])(?:([ \t]+)(.*))?$`)
+	reTableSep = regexp.MustCompile(`^ {0,3}\|?[ \t]*:?-+:?[ \t]*(?:\|[ \t]*:?-+:?[ \t]*)*\|?[ \t]*$`)
+	reRefDef   = regexp.MustCompile(`^ {0,3}\[([^\]]+)\]:[ \t]*<?([^\s>]+)>?(?:[ \t]+(?:"[^"]*"|'[^']*'|\([^)]*\)))?[ \t]*$`)
+)
+
+// parser splits the lines into the blocks, and collects the link
+// reference definitions.
+type parser struct {
+	refs map[string]string
+}
+
+func (p *parser) parse(lines []string) (blocks []*block) {
+	for i := 0; i < len(lines); {
+		line := lines[i]
+		var b *block
+		switch {
+		case isBlank(line):
+			i++
+			continue
+		case reFence.MatchString(line) && !strings.Contains(fenceInfo(line), "`"):
+			b, i = p.fenced(lines, i)
+		case reATX.MatchString(line):
+			m := reATX.FindStringSubmatch(line)
+			b, i = &block{kind: blockHeading, level: len(m[1]), text: m[2]}, i+1
+		case reRule.MatchString(line):
+			b, i = &block{kind: blockRule}, i+1
+		case reQuote.MatchString(line):
+			b, i = p.quote(lines, i)
+		case reList.MatchString(line):
+			b, i = p.list(lines, i)
+		case indentOf(line) >= 4:
+			b, i = indented(lines, i)
+		case i+1 < len(lines) && isTable(line, lines[i+1]):
+			b, i = tableOf(lines, i)
+		case reRefDef.MatchString(line):
+			m := reRefDef.FindStringSubmatch(line)
+			if label := normLabel(m[1]); p.refs[label] == "" {
+				p.refs[label] = m[2]
+			}
+			i++
+			continue
+		default:
+			b, i = paragraph(lines, i)
+		}
+		blocks = append(blocks, b)
+	}
+	return
+}
+
+func (p *parser) fenced(lines []string, i int) (*block, int) {
+	m := reFence.FindStringSubmatch(lines[i])
+	indent, fence := len(m[1]), m[2]
+	b := &block{kind: blockCode}
+	if info := strings.Fields(m[3]); len(info) > 0 {
+		b.lang = info[0]
+	}
+	for i++; i < len(lines); i++ {
+		line := lines[i]
+		if t := strings.TrimSpace(line); strings.HasPrefix(t, fence) && strings.Trim(t, fence[:1]) == "" && indentOf(line) < 4 {
+			return b, i + 1
+		}
+		b.lines = append(b.lines, line[min(indent, indentOf(line)):])
+	}
+	return b, i
+}
+
+func (p *parser) quote(lines []string, i int) (*block, int) {
+	var inner []string
+	for ; i < len(lines); i++ {
+		line := lines[i]
+		if m := reQuote.FindStringSubmatch(line); m != nil {
+			inner = append(inner, m[1])
+			continue
+		}
+		// a lazy continuation line of a paragraph
+		if isBlank(line) || len(inner) == 0 || isBlank(inner[len(inner)-1]) || interrupts(line) {
+			break
+		}
+		inner = append(inner, line)
+	}
+	return &block{kind: blockQuote, children: p.parse(inner)}, i
+}
+
+func (p *parser) list(lines []string, i int) (*block, int) {
+	m := reList.FindStringSubmatch(lines[i])
+	b := &block{kind: blockList, ordered: isOrdered(m[2])}
+	if b.ordered {
+		b.start, _ = strconv.Atoi(m[2][:len(m[2])-1])
+	}
+	delim := m[2][len(m[2])-1]
+	// sibling reports whether the line is an item of this list.
+	sibling := func(line string) bool {
+		m := reList.FindStringSubmatch(line)
+		return m != nil && !reRule.MatchString(line) &&
+			isOrdered(m[2]) == b.ordered && m[2][len(m[2])-1] == delim
+	}
+	for i < len(lines) && sibling(lines[i]) {
+		m = reList.FindStringSubmatch(lines[i])
+		indent, first := len(m[1])+len(m[2])+1, m[4]
+		if n := len(m[3]); m[4] != "" && n <= 4 {
+			indent += n - 1
+		} else if m[4] != "" {
+			first = m[3][1:] + m[4]
+		}
+
+		body := []string{first}
+		for i++; i < len(lines); i++ {
+			line := lines[i]
+			switch {
+			case isBlank(line):
+				body = append(body, "")
+				continue
+			case indentOf(line) >= indent:
+				body = append(body, line[indent:])
+				continue
+			case !isBlank(body[len(body)-1]) && !interrupts(line) && !reList.MatchString(line):
+				body = append(body, strings.TrimLeft(line, " "))
+				continue
+			}
+			break
+		}
+
+		n := len(body)
+		for n > 0 && isBlank(body[n-1]) {
+			n--
+		}
+		b.items = append(b.items, p.parse(body[:n]))
+		if n < len(body) {
+			if i >= len(lines) || !sibling(lines[i]) {
+				break
+			}
+			b.loose = true
+		}
+	}
+	return b, i
+}
+
+func indented(lines []string, i int) (*block, int) {
+	b := &block{kind: blockCode}
+	for ; i < len(lines) && (isBlank(lines[i]) || indentOf(lines[i]) >= 4); i++ {
+		b.lines = append(b.lines, strings.TrimPrefix(lines[i], "    "))
+	}
+	n := len(b.lines)
+	for n > 0 && isBlank(b.lines[n-1]) {
+		n--
+	}
+	b.lines = b.lines[:n]
+	return b, i
+}
+
+func paragraph(lines []string, i int) (*block, int) {
+	b := &block{kind: blockParagraph}
+	para := []string{strings.TrimLeft(lines[i], " ")}
+	for i++; i < len(lines); i++ {
+		line := lines[i]
+		if m := reSetext.FindStringSubmatch(line); m != nil {
+			b.kind, b.level = blockHeading, 2
+			if m[1][0] == '=' {
+				b.level = 1
+			}
+			i++
+			break
+		}
+		if isBlank(line) || interrupts(line) {
+			break
+		}
+		para = append(para, strings.TrimLeft(line, " "))
+	}
+	b.text = joinLines(para)
+	if b.kind == blockHeading {
+		b.text = strings.TrimSpace(b.text)
+	}
+	return b, i
+}
+
+// joinLines joins the lines of a paragraph, a line ending with
+// two spaces or a backslash is a hard line break ("\n").
+func joinLines(lines []string) string {
+	var sb strings.Builder
+	for k, line := range lines {
+		if k == len(lines)-1 {
+			_, _ = sb.WriteString(strings.TrimRight(line, " "))
+			break
+		}
+		switch {
+		case strings.HasSuffix(line, "  "):
+			_, _ = sb.WriteString(strings.TrimRight(line, " ") + "\n")
+		case strings.HasSuffix(line, `\`) && !strings.HasSuffix(line, `\\`):
+			_, _ = sb.WriteString(line[:len(line)-1] + "\n")
+		default:
+			_, _ = sb.WriteString(strings.TrimRight(line, " ") + " ")
+		}
+	}
+	return sb.String()
+}
+
+// isTable reports whether a GFM table starts with the header line
+// and the delimiter row.
+func isTable(header, sep string) bool {
+	return strings.Contains(header, "|") && strings.Contains(sep, "|") &&
+		reTableSep.MatchString(sep) && len(splitRow(header)) == len(splitRow(sep))
+}
+
+func tableOf(lines []string, i int) (*block, int) {
+	b := &block{kind: blockTable, header: splitRow(lines[i])}
+	for _, cell := range splitRow(lines[i+1]) {
+		left, right := strings.HasPrefix(cell, ":"), strings.HasSuffix(cell, ":")
+		switch {
+		case left && right:
+			b.aligns = append(b.aligns, table.AlignCenter)
+		case right:
+			b.aligns = append(b.aligns, table.AlignRight)
+		default:
+			b.aligns = append(b.aligns, table.AlignLeft)
+		}
+	}
+	for i += 2; i < len(lines); i++ {
+		line := lines[i]
+		if isBlank(line) || interrupts(line) {
+			break
+		}
+		row := splitRow(line)
+		for len(row) < len(b.header) {
+			row = append(row, "")
+		}
+		b.rows = append(b.rows, row[:len(b.header)])
+	}
+	return b, i
+}
+
+// splitRow splits a table row into the trimmed cells by the
+// unescaped pipes.
+func splitRow(line string) (cells []string) {
+	line = strings.TrimSpace(line)
+	line = strings.TrimPrefix(line, "|")
+	if strings.HasSuffix(line, "|") && !strings.HasSuffix(line, `\|`) {
+		line = line[:len(line)-1]
+	}
+	start := 0
+	for j := 0; j < len(line); j++ {
+		switch line[j] {
+		case '\\':
+			j++
+		case '|':
+			cells = append(cells, strings.TrimSpace(line[start:j]))
+			start = j + 1
+		}
+	}
+	return append(cells, strings.TrimSpace(line[start:]))
+}
+
+// interrupts reports whether line starts a block which interrupts
+// a paragraph.
+func interrupts(line string) bool {
+	if reATX.MatchString(line) || reRule.MatchString(line) || reQuote.MatchString(line) ||
+		(reFence.MatchString(line) && !strings.Contains(fenceInfo(line), "`")) {
+		return true
+	}
+	m := reList.FindStringSubmatch(line)
+	return m != nil && m[4] != "" && (!isOrdered(m[2]) || m[2][:len(m[2])-1] == "1")
+}
+
+func fenceInfo(line string) string {
+	if m := reFence.FindStringSubmatch(line); m != nil && m[2][0] == '`' {
+		return m[3]
+	}
+	return ""
+}
+
+func isOrdered(marker string) bool { return marker[0] >= '0' && marker[0] <= '9' }
+
+func isBlank(line string) bool { return strings.TrimSpace(line) == "" }
+
+func indentOf(line string) int { return len(line) - len(strings.TrimLeft(line, " ")) }
+
+// normLabel normalizes a link label for matching.
+func normLabel(label string) string {
+	return strings.ToLower(strings.Join(strings.Fields(label), " "))
+}
@@ -0,0 +1,320 @@
+package markdown
+
+import (
+	"html"
+	"regexp"
+	"strings"
+
+	"github.com/hedzr/is/term/color"
+)
+
+var (
+	reAutolink = regexp.MustCompile(`^<([a-zA-Z][a-zA-Z0-9+.-]{1,31}:[^\s<>]*)>`)
+	reEmail    = regexp.MustCompile(`^<([a-zA-Z0-9.!#$%&'*+/=?^_{|}~-]+@[a-zA-Z0-9](?:[a-zA-Z0-9.-]*[a-zA-Z0-9])?)>`)
+	reRawTag   = regexp.MustCompile(`^</?[a-zA-Z][a-zA-Z0-9-]*(?:\s+[a-zA-Z_:][^<>]*?)?\s*/?>`)
+	reEntity   = regexp.MustCompile(`^&(?:#[0-9]{1,7}|#[xX][0-9a-fA-F]{1,6}|[a-zA-Z][a-zA-Z0-9]{1,31});`)
+)
+
+// inline converts the inline markdown s into the CPT markups
+// (see [color.GetCPT]), the raw HTML tags are kept.
+func (r *renderer) inline(s string) string {
+	var sb strings.Builder
+	for i := 0; i < len(s); {
+		n := r.inlineAt(&sb, s, i)
+		if n == 0 {
+			_, _ = sb.WriteString(color.EscapeCPT(s[i : i+1]))
+			n = 1
+		}
+		i += n
+	}
+	return sb.String()
+}
+
+// inlineAt writes the inline element at s[i:], and returns its
+// length, or 0 if it's a plain character.
+func (r *renderer) inlineAt(sb *strings.Builder, s string, i int) int {
+	rest := s[i:]
+	switch c := s[i]; c {
+	case '\\':
+		if len(rest) > 1 && isPunct(rest[1]) {
+			_, _ = sb.WriteString(color.EscapeCPT(rest[1:2]))
+			return 2
+		}
+	case '`':
+		if n, code, ok := codeSpan(rest); ok {
+			_, _ = sb.WriteString(`<font color="code">` + color.EscapeCPT(code) + `</font>`)
+			return n
+		}
+		n := runLen(rest, '`')
+		_, _ = sb.WriteString(rest[:n])
+		return n
+	case '*', '_', '~':
+		return r.emphasis(sb, s, i)
+	case '!':
+		if n, text, url, ok := r.link(rest[1:]); ok {
+			alt := r.inline(text)
+			if alt == "" {
+				alt = color.EscapeCPT(url)
+			}
+			_, _ = sb.WriteString(anchor(url, alt))
+			return n + 1
+		}
+	case '[':
+		if n, text, url, ok := r.link(rest); ok {
+			_, _ = sb.WriteString(anchor(url, r.inline(text)))
+			return n
+		}
+	case '<':
+		if m := reAutolink.FindStringSubmatch(rest); m != nil {
+			_, _ = sb.WriteString(anchor(m[1], color.EscapeCPT(m[1])))
+			return len(m[0])
+		}
+		if m := reEmail.FindStringSubmatch(rest); m != nil {
+			_, _ = sb.WriteString(anchor("mailto:"+m[1], color.EscapeCPT(m[1])))
+			return len(m[0])
+		}
+		if m := reRawTag.FindString(rest); m != "" {
+			_, _ = sb.WriteString(m)
+			return len(m)
+		}
+	case '&':
+		if m := reEntity.FindString(rest); m != "" {
+			_, _ = sb.WriteString(m)
+			return len(m)
+		}
+	}
+	return 0
+}
+
+// emphasis writes the emphasis (*em*, _em_), the strong emphasis
+// (**strong**, __strong__) or the strikethrough (~~del~~) at
+// s[i:].
+func (r *renderer) emphasis(sb *strings.Builder, s string, i int) int {
+	c := s[i]
+	n := runLen(s[i:], c)
+	if c == '~' && n != 2 || i+n >= len(s) || isSpace(s[i+n]) ||
+		c == '_' && i > 0 && isAlnum(s[i-1]) {
+		_, _ = sb.WriteString(s[i : i+n])
+		return n
+	}
+	for k := min(n, 3); k >= 1; k-- {
+		j := closing(s, i+n, c, k)
+		if j < 0 {
+			continue
+		}
+		_, _ = sb.WriteString(s[i : i+n-k])
+		inner := r.inline(s[i+n : j])
+		switch {
+		case c == '~':
+			inner = "<del>" + inner + "</del>"
+		case k == 1:
+			inner = "<i>" + inner + "</i>"
+		case k == 2:
+			inner = "<b>" + inner + "</b>"
+		default:
+			inner = "<b><i>" + inner + "</i></b>"
+		}
+		_, _ = sb.WriteString(inner)
+		return j + k - i
+	}
+	_, _ = sb.WriteString(s[i : i+n])
+	return n
+}
+
+// closing returns the position of the closing delimiter run of k
+// characters c, or -1. The code spans and the escaped characters
+// are skipped.
+func closing(s string, from int, c byte, k int) int {
+	for j := from; j < len(s); {
+		switch s[j] {
+		case '\\':
+			j += 2
+			continue
+		case '`':
+			if n, _, ok := codeSpan(s[j:]); ok {
+				j += n
+				continue
+			}
+		case c:
+			n := runLen(s[j:], c)
+			if n == k && j > from && !isSpace(s[j-1]) &&
+				(c != '_' || j+n >= len(s) || !isAlnum(s[j+n])) {
+				return j
+			}
+			j += n
+			continue
+		}
+		j++
+	}
+	return -1
+}
+
+// codeSpan returns the length and the content of the code span at
+// the beginning of s.
+func codeSpan(s string) (n int, code string, ok bool) {
+	open := runLen(s, '`')
+	for j := open; j < len(s); {
+		if s[j] != '`' {
+			j++
+			continue
+		}
+		m := runLen(s[j:], '`')
+		if m == open {
+			code = strings.ReplaceAll(s[open:j], "\n", " ")
+			if len(code) > 1 && code[0] == ' ' && code[len(code)-1] == ' ' && strings.Trim(code, " ") != "" {
+				code = code[1 : len(code)-1]
+			}
+			return j + m, code, true
+		}
+		j += m
+	}
+	return 0, "", false
+}
+
+// link parses the link at the beginning of s: [text](url "title"),
+// [text][label], [text][] or [label]. It returns the markdown
+// source of the link text.
+func (r *renderer) link(s string) (n int, text, url string, ok bool) {
+	if len(s) == 0 || s[0] != '[' {
+		return
+	}
+	end := closeBracket(s)
+	if end < 0 {
+		return
+	}
+	text, rest := s[1:end], s[end+1:]
+	if strings.HasPrefix(rest, "(") {
+		if m, dest, found := destination(rest); found {
+			return end + 1 + m, text, dest, true
+		}
+	}
+	label, m := text, 0
+	if strings.HasPrefix(rest, "[") {
+		if e := closeBracket(rest); e >= 0 {
+			if e > 1 {
+				label = rest[1:e]
+			}
+			m = e + 1
+		}
+	}
+	if url, ok = r.refs[normLabel(label)]; ok {
+		n = end + 1 + m
+	}
+	return
+}
+
+// closeBracket returns the position of the ']' matching the '['
+// at the beginning of s, or -1.
+func closeBracket(s string) int {
+	depth := 0
+	for j := 0; j < len(s); j++ {
+		switch s[j] {
+		case '\\':
+			j++
+		case '`':
+			if n, _, ok := codeSpan(s[j:]); ok {
+				j += n - 1
+			}
+		case '[':
+			depth++
+		case ']':
+			if depth--; depth == 0 {
+				return j
+			}
+		}
+	}
+	return -1
+}
+
+// destination parses `(url "title")` at the beginning of s.
+func destination(s string) (n int, url string, ok bool) {
+	j := 1
+	for j < len(s) && isSpace(s[j]) {
+		j++
+	}
+	if j < len(s) && s[j] == '<' {
+		e := strings.IndexAny(s[j:], ">\n")
+		if e < 0 || s[j+e] != '>' {
+			return
+		}
+		url, j = s[j+1:j+e], j+e+1
+	} else {
+		start, depth := j, 0
+	loop:
+		for ; j < len(s); j++ {
+			switch s[j] {
+			case '\\':
+				j++
+			case '(':
+				depth++
+			case ')':
+				if depth == 0 {
+					break loop
+				}
+				depth--
+			case ' ', '\t', '\n':
+				break loop
+			}
+		}
+		url = s[start:min(j, len(s))]
+	}
+	for j < len(s) && isSpace(s[j]) {
+		j++
+	}
+	if j < len(s) && (s[j] == '"' || s[j] == '\'' || s[j] == '(') {
+		q := s[j]
+		if q == '(' {
+			q = ')'
+		}
+		e := strings.IndexByte(s[j+1:], q)
+		if e < 0 {
+			return
+		}
+		j += e + 2
+		for j < len(s) && isSpace(s[j]) {
+			j++
+		}
+	}
+	if j >= len(s) || s[j] != ')' {
+		return
+	}
+	return j + 1, unescape(url), true
+}
+
+// unescape removes the backslashes before the punctuations.
+func unescape(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var sb strings.Builder
+	for j := 0; j < len(s); j++ {
+		if s[j] == '\\' && j+1 < len(s) && isPunct(s[j+1]) {
+			j++
+		}
+		_ = sb.WriteByte(s[j])
+	}
+	return sb.String()
+}
+
+// anchor returns the <a> markup of a link whose text is the CPT
+// markups already.
+func anchor(url, text string) string {
+	return `<a href="` + html.EscapeString(url) + `"><font color="info">` + text + `</font></a>`
+}
+
+func runLen(s string, c byte) (n int) {
+	for n < len(s) && s[n] == c {
+		n++
+	}
+	return
+}
+
+func isSpace(c byte) bool { return c == ' ' || c == '\t' || c == '\n' }
+
+func isAlnum(c byte) bool {
+	return c >= '0' && c <= '9' || c|0x20 >= 'a' && c|0x20 <= 'z' || c >= 0x80
+}
+
+func isPunct(c byte) bool {
+	return c >= '!' && c <= '/' || c >= ':' && c <= '@' || c >= '[' && c <= '`' || c >= '{' && c <= '~'
+}
@@ -0,0 +1,120 @@
+// Package markdown renders the CommonMark text for the terminals.
+//
+// The headings, emphasis, lists, block quotes, code blocks, GFM
+// tables and links are supported. The text is styled by the
+// current theme (see [color.CurrentTheme]), and word-wrapped to
+// the terminal width:
+//
+//	_ = markdown.Render(os.Stdout, "# Usage\n\nRun `app serve` to **start** the server.\n")
+//
+// The output is plain text if the colors are disabled (see
+// [states.Env].IsNoColorMode) or the writer isn't a colorful
+// terminal, so it is suitable for the long descriptions of the
+// --help screen and the changelogs.
+package markdown
+
+import (
+	"io"
+	"os"
+	"strings"
+
+	"github.com/hedzr/is/states"
+	"github.com/hedzr/is/term"
+	"github.com/hedzr/is/term/chk"
+	"github.com/hedzr/is/term/color"
+)
+
+// defaultWidth is the width if the output isn't a terminal.
+const defaultWidth = 80
+
+// Opt is a functional option for [Render] and [String].
+type Opt func(r *renderer)
+
+// WithWidth sets the width to wrap the text in. The default is
+// the terminal width (see [term.GetTtySize]), or 80 columns if the
+// output isn't a terminal.
+func WithWidth(n int) Opt {
+	return func(r *renderer) {
+		r.width = n
+	}
+}
+
+// WithColor enables or disables the colors, regardless of the
+// output.
+func WithColor(colored bool) Opt {
+	return func(r *renderer) {
+		r.colored, r.coloredSet = colored, true
+	}
+}
+
+// Render writes the markdown src to w.
+func Render(w io.Writer, src string, opts ...Opt) error {
+	r := newRenderer(opts)
+	if !r.coloredSet {
+		r.colored = !states.Env().IsNoColorMode() && chk.IsTty(w) && chk.IsColorful(w)
+	}
+	if r.width <= 0 {
+		r.width = ttyWidth(w)
+	}
+	_, err := io.WriteString(w, r.render(src))
+	return err
+}
+
+// String returns the rendered markdown src, which is colored
+// unless the colors are disabled (see [states.Env].IsNoColorMode).
+func String(src string, opts ...Opt) string {
+	r := newRenderer(opts)
+	if !r.coloredSet {
+		r.colored = !states.Env().IsNoColorMode()
+	}
+	if r.width <= 0 {
+		r.width = ttyWidth(os.Stdout)
+	}
+	return r.render(src)
+}
+
+func newRenderer(opts []Opt) *renderer {
+	r := &renderer{}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// ttyWidth returns the columns of the terminal w, or defaultWidth.
+func ttyWidth(w io.Writer) (width int) {
+	if f, ok := w.(*os.File); ok && chk.IsTty(w) {
+		if f == os.Stdout {
+			width, _ = term.GetTtySize()
+		} else {
+			width, _, _ = term.GetTtySizeByFd(f.Fd())
+		}
+	} else if o, ok := w.(*color.Output); ok {
+		width = o.Width()
+	}
+	if width <= 0 {
+		width = defaultWidth
+	}
+	return
+}
+
+// expandTabs replaces the tabs in line with the spaces, the tab
+// stops are 4 columns apart as CommonMark says.
+func expandTabs(line string) string {
+	if !strings.Contains(line, "\t") {
+		return line
+	}
+	var sb strings.Builder
+	col := 0
+	for _, r := range line {
+		if r == '\t' {
+			n := 4 - col%4
+			_, _ = sb.WriteString(strings.Repeat(" ", n))
+			col += n
+			continue
+		}
+		_, _ = sb.WriteRune(r)
+		col++
+	}
+	return sb.String()
+}
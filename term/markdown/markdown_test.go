@@ -0,0 +1,69 @@
+package markdown
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestStringPlain(t *testing.T) {
+	for _, tc := range []struct {
+		name, src, want string
+	}{
+		{"heading", "# Title\n\nSetext\n------\n", "# Title\n\n## Setext\n"},
+		{"emphasis", "Some *em*, __strong__ and ~~del~~ `a < b`.", "Some em, strong and del a < b.\n"},
+		{"literal", `2 * 3 * 4, snake_case_name and \*stars\* & <b>bold</b>`,
+			"2 * 3 * 4, snake_case_name and *stars*\n& bold\n"},
+		{"wrap", "one two three four five six seven eight nine ten eleven twelve",
+			"one two three four five six seven\neight nine ten eleven twelve\n"},
+		{"hard break", "line one  \nline two\\\nline three\nsame line", "line one\nline two\nline three same line\n"},
+		{"links", "[site](https://example.com \"Title\") <https://go.dev> [ref][r] ![logo](a.png)\n\n[r]: https://r.io",
+			"site (https://example.com)\nhttps://go.dev ref (https://r.io) logo\n(a.png)\n"},
+		{"tight list", "- a\n- b\n  - c\n    lazy\n- d\n", "• a\n• b\n  ◦ c lazy\n• d\n"},
+		{"loose list", "1. one\n\n2. two\n   more\n", "1. one\n\n2. two more\n"},
+		{"ordered start", "9) nine\n10) ten\n", " 9. nine\n10. ten\n"},
+		{"quote", "> quoted\nlazy\n>\n> - item\n", "│ quoted lazy\n│\n│ • item\n"},
+		{"fenced", "```go\nfunc main() {\n\tprintln()\n}\n```\n", "    func main() {\n        println()\n    }\n"},
+		{"indented", "    code\n\n    more\n\ntext", "    code\n\n    more\n\ntext\n"},
+		{"rule", "a\n\n***\n", "a\n\n──────────────────────────────────────\n"},
+		{"table", "| a | b |\n|:-:|--:|\n| `x` | 22 |\n| y |\n",
+			"┌───┬────┐\n│ a │  b │\n├───┼────┤\n│ x │ 22 │\n│ y │    │\n└───┴────┘\n"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got := String(tc.src, WithWidth(38), WithColor(false))
+			if got != tc.want {
+				t.Errorf("got\n%q\nwant\n%q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestStringColored(t *testing.T) {
+	got := String("# Title\n\n- **bold** `code`\n", WithWidth(40), WithColor(true))
+	for _, want := range []string{"\x1b[4m", "Title", "\x1b[1mbold", "code", "•"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("%q is not in %q", want, got)
+		}
+	}
+	if strings.Contains(got, "#") || strings.Contains(got, "**") || strings.Contains(got, "`") {
+		t.Errorf("markdown markers left in %q", got)
+	}
+}
+
+func TestStringCodeLang(t *testing.T) {
+	got := String("```go\nif x {}\n```\n\n```\nif x {}\n```\n", WithWidth(40), WithColor(true))
+	want := "    \x1b[96mif\x1b[0m x {}\n\n    \x1b[95mif x {}\x1b[0m\n"
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestRenderNotTty(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Render(&buf, "**bold** [x](https://x.io)"); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := buf.String(), "bold x (https://x.io)\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,210 @@
+package markdown
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/hedzr/is/term/color"
+	"github.com/hedzr/is/term/table"
+)
+
+// minWidth is the least width of the text in a nested block.
+const minWidth = 10
+
+// bullets are the list markers of the nesting levels.
+var bullets = []string{"•", "◦", "▪"}
+
+type renderer struct {
+	width      int
+	colored    bool
+	coloredSet bool
+	depth      int // of the nested lists
+	refs       map[string]string
+}
+
+func (r *renderer) render(src string) string {
+	src = strings.ReplaceAll(src, "\r\n", "\n")
+	lines := strings.Split(src, "\n")
+	for i, line := range lines {
+		lines[i] = expandTabs(line)
+	}
+	p := &parser{refs: make(map[string]string)}
+	blocks := p.parse(lines)
+	r.refs = p.refs
+
+	out := r.blocks(blocks, r.width, false)
+	if len(out) == 0 {
+		return ""
+	}
+	return strings.Join(out, "\n") + "\n"
+}
+
+// blocks renders the blocks into the lines of width columns, they
+// are separated by the blank lines unless tight.
+func (r *renderer) blocks(blocks []*block, width int, tight bool) (lines []string) {
+	for i, b := range blocks {
+		if i > 0 && !tight {
+			lines = append(lines, "")
+		}
+		lines = append(lines, r.block(b, width)...)
+	}
+	return
+}
+
+func (r *renderer) block(b *block, width int) []string {
+	switch b.kind {
+	case blockHeading:
+		return r.heading(b, width)
+	case blockCode:
+		return r.code(b)
+	case blockQuote:
+		return r.prefixed(r.blocks(b.children, max(width-2, minWidth), false),
+			r.style(color.RoleMuted, "│")+" ", r.style(color.RoleMuted, "│")+" ")
+	case blockList:
+		return r.list(b, width)
+	case blockTable:
+		return r.table(b, width)
+	case blockRule:
+		return []string{r.style(color.RoleMuted, strings.Repeat("─", width))}
+	}
+	return r.wrap(r.inline(b.text), width)
+}
+
+func (r *renderer) heading(b *block, width int) []string {
+	text := r.inline(b.text)
+	if !r.colored {
+		return r.wrap(strings.Repeat("#", b.level)+" "+text, width)
+	}
+	switch b.level {
+	case 1:
+		text = `<u><font color="heading">` + text + `</font></u>`
+	case 2:
+		text = `<font color="heading">` + text + `</font>`
+	default:
+		text = `<b><font color="accent">` + text + `</font></b>`
+	}
+	return r.wrap(text, width)
+}
+
+// code renders a code block indented, which is highlighted if
+// its language is given (see [color.HighlightCode]).
+func (r *renderer) code(b *block) (lines []string) {
+	src := b.lines
+	if r.colored && b.lang != "" {
+		src = strings.Split(color.HighlightCode(strings.Join(b.lines, "\n"), b.lang), "\n")
+	}
+	for _, line := range src {
+		if line == "" {
+			lines = append(lines, "")
+			continue
+		}
+		if b.lang == "" {
+			line = r.style(color.RoleCode, line)
+		}
+		lines = append(lines, "    "+line)
+	}
+	return
+}
+
+func (r *renderer) list(b *block, width int) (lines []string) {
+	r.depth++
+	defer func() { r.depth-- }()
+
+	markers := make([]string, len(b.items))
+	mw := 0
+	for i := range b.items {
+		if b.ordered {
+			markers[i] = strconv.Itoa(b.start+i) + "."
+		} else {
+			markers[i] = bullets[(r.depth-1)%len(bullets)]
+		}
+		mw = max(mw, color.StringWidth(markers[i]))
+	}
+	for i, item := range b.items {
+		if i > 0 && b.loose {
+			lines = append(lines, "")
+		}
+		marker := color.PadLeft(markers[i], mw)
+		if !b.ordered {
+			marker = color.PadRight(markers[i], mw)
+		}
+		inner := r.blocks(item, max(width-mw-1, minWidth), !b.loose)
+		if len(inner) == 0 {
+			inner = []string{""}
+		}
+		lines = append(lines, r.prefixed(inner, r.style(color.RoleAccent, marker)+" ", strings.Repeat(" ", mw+1))...)
+	}
+	return
+}
+
+func (r *renderer) table(b *block, width int) []string {
+	format := table.FormatPlain
+	if r.colored {
+		format = table.FormatTerminal
+	}
+	t := table.New(table.WithFormat(format), table.WithMaxWidth(width), table.WithAligns(b.aligns...))
+	cells := func(row []string) (ret []string) {
+		for _, cell := range row {
+			ret = append(ret, r.cell(cell))
+		}
+		return
+	}
+	t.SetHeader(cells(b.header)...)
+	for _, row := range b.rows {
+		t.Append(cells(row)...)
+	}
+	return strings.Split(strings.TrimSuffix(t.String(), "\n"), "\n")
+}
+
+// cell returns the text of a table cell, the markups are
+// translated by the table if it's colored.
+func (r *renderer) cell(s string) string {
+	if r.colored {
+		return r.inline(s)
+	}
+	return r.translate(r.inline(s))
+}
+
+// prefixed puts first before the first line, and rest before the
+// others. The trailing spaces of the prefix are dropped on an
+// empty line.
+func (r *renderer) prefixed(lines []string, first, rest string) []string {
+	for i, line := range lines {
+		prefix := rest
+		if i == 0 {
+			prefix = first
+		}
+		if line == "" {
+			prefix = strings.TrimRight(prefix, " ")
+		}
+		lines[i] = prefix + line
+	}
+	return lines
+}
+
+// style paints the plain text s in the color of a theme role.
+func (r *renderer) style(role color.Role, s string) string {
+	if !r.colored || s == "" {
+		return s
+	}
+	return color.CurrentTheme().Wrap(role, s)
+}
+
+// wrap translates the CPT markups, and wraps the text in width
+// columns.
+func (r *renderer) wrap(markup string, width int) []string {
+	return color.WordWrap(r.translate(markup), width)
+}
+
+// translate translates the CPT markups into the escape sequences,
+// or the plain text if the colors are disabled.
+func (r *renderer) translate(markup string) string {
+	if r.colored {
+		return color.GetCPTC().Translate(markup, color.Reset)
+	}
+	var sb strings.Builder
+	tw := color.NewTranslatingWriter(&sb, color.GetCPTNC())
+	_, _ = tw.Write([]byte(markup))
+	_ = tw.Close()
+	return sb.String()
+}
@@ -0,0 +1,335 @@
+package progress
+
+import (
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/hedzr/is/term/color"
+)
+
+type barState int
+
+const (
+	stateRunning barState = iota
+	stateDone
+	stateAborted
+)
+
+// Bar is a progress bar, or a spinner if its total is not
+// positive. The counter methods are safe for concurrent use.
+type Bar struct {
+	g       *Group
+	own     bool // owns g, see New
+	title   string
+	width   int
+	bytes   bool
+	spinner []string
+
+	total   atomic.Int64
+	current atomic.Int64
+
+	// the following fields are guarded by g.mu
+	state       barState
+	start, end  time.Time
+	rate        float64 // the smoothed rate, per second
+	hasRate     bool
+	sampleAt    time.Time
+	sampleValue int64
+	logged      barState
+	loggedValue int64
+}
+
+// New returns a standalone bar which renders itself until
+// [Bar.Done] or [Bar.Abort], total <= 0 means an indeterminate
+// bar.
+func New(total int64, opts ...Opt) *Bar {
+	b := NewGroup(opts...).Add(total)
+	b.own = true
+	return b
+}
+
+// NewSpinner returns a standalone indeterminate bar.
+func NewSpinner(title string, opts ...Opt) *Bar {
+	return New(0, append([]Opt{WithTitle(title)}, opts...)...)
+}
+
+// Add increases the counter by n.
+func (b *Bar) Add(n int64) { b.current.Add(n) }
+
+// Increment increases the counter by 1.
+func (b *Bar) Increment() { b.current.Add(1) }
+
+// Set sets the counter.
+func (b *Bar) Set(n int64) { b.current.Store(n) }
+
+// SetTotal changes the total, <= 0 means indeterminate.
+func (b *Bar) SetTotal(total int64) { b.total.Store(total) }
+
+// SetTitle changes the title.
+func (b *Bar) SetTitle(title string) {
+	b.g.mu.Lock()
+	defer b.g.mu.Unlock()
+	b.title = title
+}
+
+// Current returns the counter.
+func (b *Bar) Current() int64 { return b.current.Load() }
+
+// Total returns the total.
+func (b *Bar) Total() int64 { return b.total.Load() }
+
+// Write increases the counter by len(p), so that a bar can be a
+// destination of [io.Copy] or [io.MultiWriter].
+func (b *Bar) Write(p []byte) (n int, err error) {
+	b.current.Add(int64(len(p)))
+	return len(p), nil
+}
+
+// Reader returns a reader which increases the counter by the
+// bytes read from r.
+func (b *Bar) Reader(r io.Reader) io.Reader {
+	return io.TeeReader(r, b)
+}
+
+// Done marks the bar completed, the counter of a determinate bar
+// is filled up to the total.
+func (b *Bar) Done() { b.finish(stateDone) }
+
+// Abort marks the bar failed.
+func (b *Bar) Abort() { b.finish(stateAborted) }
+
+func (b *Bar) finish(state barState) {
+	b.g.mu.Lock()
+	if b.state != stateRunning {
+		b.g.mu.Unlock()
+		return
+	}
+	if total := b.Total(); state == stateDone && total > 0 && b.Current() < total {
+		b.Set(total)
+	}
+	b.state, b.end = state, b.g.now()
+	b.g.mu.Unlock()
+	b.g.finished(b)
+}
+
+// Elapsed returns the duration since the bar was created till
+// now or it's finished.
+func (b *Bar) Elapsed() time.Duration {
+	b.g.mu.Lock()
+	defer b.g.mu.Unlock()
+	return b.elapsed(b.g.now())
+}
+
+// Rate returns the throughput per second, it's the smoothed
+// recent rate while running, or the average one after finished.
+func (b *Bar) Rate() float64 {
+	b.g.mu.Lock()
+	defer b.g.mu.Unlock()
+	return b.currentRate(b.g.now())
+}
+
+// ETA returns the estimated remaining time, or -1 if unknown.
+func (b *Bar) ETA() time.Duration {
+	b.g.mu.Lock()
+	defer b.g.mu.Unlock()
+	return b.eta(b.g.now())
+}
+
+func (b *Bar) elapsed(now time.Time) time.Duration {
+	if b.state != stateRunning {
+		return b.end.Sub(b.start)
+	}
+	return now.Sub(b.start)
+}
+
+func (b *Bar) currentRate(now time.Time) float64 {
+	if b.state == stateRunning && b.hasRate {
+		return b.rate
+	}
+	if secs := b.elapsed(now).Seconds(); secs > 0 {
+		return float64(b.Current()) / secs
+	}
+	return 0
+}
+
+func (b *Bar) eta(now time.Time) time.Duration {
+	total, cur := b.Total(), b.Current()
+	if b.state != stateRunning {
+		return 0
+	}
+	rate := b.currentRate(now)
+	if total <= 0 || rate <= 0 {
+		return -1
+	}
+	return time.Duration(float64(max(total-cur, 0)) / rate * float64(time.Second))
+}
+
+// rateSmoothing is the time constant of the exponential moving
+// average of the rate.
+const rateSmoothing = 3 * time.Second
+
+// sample updates the smoothed rate, g.mu is held.
+func (b *Bar) sample(now time.Time) {
+	dt := now.Sub(b.sampleAt)
+	if b.state != stateRunning || dt <= 0 {
+		return
+	}
+	cur := b.Current()
+	inst := float64(cur-b.sampleValue) / dt.Seconds()
+	if !b.hasRate {
+		b.rate, b.hasRate = inst, true
+	} else {
+		alpha := 1 - math.Exp(-float64(dt)/float64(rateSmoothing))
+		b.rate += alpha * (inst - b.rate)
+	}
+	b.sampleAt, b.sampleValue = now, cur
+}
+
+// segment is a piece of a rendered line.
+type segment struct {
+	text string
+	clr  color.Color
+}
+
+// line renders the bar in a terminal, the title is padded to
+// titleWidth, and the line is fit into cols if it's positive.
+func (b *Bar) line(now time.Time, titleWidth, cols, frame int) string {
+	total, cur := b.Total(), b.Current()
+
+	var head, stats []segment
+	if b.title != "" {
+		head = append(head, segment{b.title + strings.Repeat(" ", titleWidth-color.StringWidth(b.title)) + " ", nil})
+	}
+
+	var mark segment
+	switch b.state {
+	case stateDone:
+		mark = segment{"✓", color.FgGreen}
+	case stateAborted:
+		mark = segment{"✗", color.FgRed}
+	default:
+		mark = segment{b.spinner[frame%len(b.spinner)], color.FgCyan}
+	}
+
+	if total > 0 {
+		pct := min(float64(cur)/float64(total), 1)
+		stats = append(stats,
+			segment{" " + strconv.Itoa(int(pct*100)) + "%", nil},
+			segment{" " + b.formatCount(cur) + "/" + b.formatCount(total), nil},
+		)
+	} else {
+		head = append(head, mark, segment{" ", nil})
+		stats = append(stats, segment{b.formatCount(cur), nil})
+	}
+	switch b.state {
+	case stateRunning:
+		stats = append(stats, segment{" " + b.formatRate(b.currentRate(now)), color.FgDarkGray})
+		if total > 0 {
+			stats = append(stats, segment{" ETA " + formatDuration(b.eta(now)), color.FgDarkGray})
+		} else {
+			stats = append(stats, segment{" " + formatDuration(b.elapsed(now)), color.FgDarkGray})
+		}
+	case stateDone:
+		stats = append(stats, segment{" in " + formatDuration(b.elapsed(now)), color.FgDarkGray})
+	case stateAborted:
+		stats = append(stats, segment{" aborted", color.FgRed})
+	}
+
+	var segs []segment
+	segs = append(segs, head...)
+	if total > 0 {
+		width := b.width
+		if cols > 0 {
+			used := 0
+			for _, s := range append(head, stats...) {
+				used += color.StringWidth(s.text)
+			}
+			// keep the last column empty to avoid the auto-wrapping
+			width = min(width, cols-1-used)
+		}
+		if width >= 5 {
+			segs = append(segs, b.bar(min(float64(cur)/float64(total), 1), width)...)
+		}
+	}
+	segs = append(segs, stats...)
+
+	var sb strings.Builder
+	for _, s := range segs {
+		_, _ = sb.WriteString(b.g.paint(s.clr, s.text))
+	}
+	return sb.String()
+}
+
+// partialBlocks are the left eighths blocks, from 1/8 to 7/8.
+var partialBlocks = []string{"▏", "▎", "▍", "▌", "▋", "▊", "▉"}
+
+func (b *Bar) bar(fraction float64, width int) []segment {
+	cells := fraction * float64(width)
+	full := int(cells)
+	filled := strings.Repeat("█", full)
+	if eighths := int((cells - float64(full)) * 8); eighths > 0 && full < width {
+		filled += partialBlocks[eighths-1]
+		full++
+	}
+	clr := color.FgCyan
+	switch b.state {
+	case stateDone:
+		clr = color.FgGreen
+	case stateAborted:
+		clr = color.FgRed
+	}
+	return []segment{{filled, clr}, {strings.Repeat("░", width-full), color.FgDarkGray}}
+}
+
+// logLine renders the bar as a plain log line.
+func (b *Bar) logLine(now time.Time) string {
+	total, cur := b.Total(), b.Current()
+	var sb strings.Builder
+	if b.title != "" {
+		_, _ = sb.WriteString(b.title)
+		_, _ = sb.WriteString(": ")
+	}
+	counter := b.formatCount(cur)
+	if total > 0 {
+		counter += "/" + b.formatCount(total)
+	}
+	switch b.state {
+	case stateDone:
+		_, _ = sb.WriteString("done " + counter + " in " + formatDuration(b.elapsed(now)) +
+			" (" + b.formatRate(b.currentRate(now)) + ")")
+	case stateAborted:
+		_, _ = sb.WriteString("aborted at " + counter + " after " + formatDuration(b.elapsed(now)))
+	default:
+		if total > 0 {
+			_, _ = sb.WriteString(strconv.Itoa(int(min(float64(cur)/float64(total), 1)*100)) + "% ")
+		}
+		_, _ = sb.WriteString(counter + " " + b.formatRate(b.currentRate(now)))
+		if total > 0 {
+			_, _ = sb.WriteString(" ETA " + formatDuration(b.eta(now)))
+		} else {
+			_, _ = sb.WriteString(" " + formatDuration(b.elapsed(now)))
+		}
+	}
+	return sb.String()
+}
+
+func (b *Bar) formatCount(n int64) string {
+	if b.bytes {
+		return formatBytes(float64(n))
+	}
+	return strconv.FormatInt(n, 10)
+}
+
+func (b *Bar) formatRate(r float64) string {
+	if b.bytes {
+		return formatBytes(r) + "/s"
+	}
+	if r < 10 {
+		return strconv.FormatFloat(r, 'f', 1, 64) + "/s"
+	}
+	return strconv.FormatFloat(r, 'f', 0, 64) + "/s"
+}
@@ -0,0 +1,39 @@
+package progress
+
+import (
+	"fmt"
+	"time"
+)
+
+var byteUnits = []string{"B", "KiB", "MiB", "GiB", "TiB", "PiB"}
+
+// formatBytes formats a byte size in the binary units.
+func formatBytes(n float64) string {
+	i := 0
+	for n >= 1024 && i < len(byteUnits)-1 {
+		n /= 1024
+		i++
+	}
+	if i == 0 {
+		return fmt.Sprintf("%.0f %s", n, byteUnits[i])
+	}
+	return fmt.Sprintf("%.1f %s", n, byteUnits[i])
+}
+
+// formatDuration formats d shortly, such as 3.2s, 45s, 2m05s
+// and 1h20m, or "?" if d is negative (unknown).
+func formatDuration(d time.Duration) string {
+	switch {
+	case d < 0:
+		return "?"
+	case d < 10*time.Second:
+		return fmt.Sprintf("%.1fs", d.Seconds())
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d.Round(time.Second).Seconds()))
+	case d < time.Hour:
+		d = d.Round(time.Second)
+		return fmt.Sprintf("%dm%02ds", int(d.Minutes()), int(d.Seconds())%60)
+	}
+	d = d.Round(time.Minute)
+	return fmt.Sprintf("%dh%02dm", int(d.Hours()), int(d.Minutes())%60)
+}
@@ -0,0 +1,386 @@
+// Package progress renders the progress bars and spinners.
+//
+// A [Bar] is determinate if its total is positive, or
+// indeterminate (a spinner with the counter) if not. The bars
+// of a [Group] are redrawn in place together at a fixed interval,
+// with a single write each time so that they never tear:
+//
+//	g := progress.NewGroup()
+//	for _, f := range files {
+//	  bar := g.Add(f.Size, progress.WithTitle(f.Name), progress.WithBytes())
+//	  go func() {
+//	    defer bar.Done()
+//	    _, _ = io.Copy(dst, bar.Reader(src))
+//	  }()
+//	}
+//	g.Wait()
+//
+// A standalone bar owns a group implicitly:
+//
+//	bar := progress.New(100, progress.WithTitle("working"))
+//	for i := 0; i < 100; i++ {
+//	  bar.Increment()
+//	}
+//	bar.Done()
+//
+// If the output is not a terminal, such as stdout piped or
+// redirected (see is.StdoutPiped), the bars are reported by the
+// plain log lines periodically instead of redrawing.
+package progress
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hedzr/is/term"
+	"github.com/hedzr/is/term/chk"
+	"github.com/hedzr/is/term/color"
+)
+
+// Opt is a functional option for [New], [NewGroup] and
+// [Group.Add]. The group options are ignored by [Group.Add].
+type Opt func(c *config)
+
+type config struct {
+	// group options
+	out         io.Writer
+	refresh     time.Duration
+	logInterval time.Duration
+
+	// bar options
+	title   string
+	width   int
+	bytes   bool
+	spinner []string
+}
+
+// WithOutput sets the output of a group, default is os.Stdout.
+func WithOutput(w io.Writer) Opt {
+	return func(c *config) {
+		c.out = w
+	}
+}
+
+// WithRefreshInterval sets the redrawing interval in a terminal,
+// default is 100ms.
+func WithRefreshInterval(d time.Duration) Opt {
+	return func(c *config) {
+		if d > 0 {
+			c.refresh = d
+		}
+	}
+}
+
+// WithLogInterval sets the interval of the plain log lines if
+// the output is not a terminal, default is 5s.
+func WithLogInterval(d time.Duration) Opt {
+	return func(c *config) {
+		if d > 0 {
+			c.logInterval = d
+		}
+	}
+}
+
+// WithTitle sets the title of a bar.
+func WithTitle(title string) Opt {
+	return func(c *config) {
+		c.title = title
+	}
+}
+
+// WithWidth sets the columns of the bar part, default is 30.
+// It will be shrunk if the terminal is narrow.
+func WithWidth(n int) Opt {
+	return func(c *config) {
+		if n > 0 {
+			c.width = n
+		}
+	}
+}
+
+// WithBytes formats the counters as the byte sizes, such as
+// 1.5 MiB and 300 KiB/s.
+func WithBytes() Opt {
+	return func(c *config) {
+		c.bytes = true
+	}
+}
+
+// WithSpinner sets the frames of the spinner of an indeterminate
+// bar, default is [SpinnerDots].
+func WithSpinner(frames ...string) Opt {
+	return func(c *config) {
+		if len(frames) > 0 {
+			c.spinner = frames
+		}
+	}
+}
+
+// The predefined spinners.
+var (
+	SpinnerDots   = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+	SpinnerLine   = []string{"-", "\\", "|", "/"}
+	SpinnerCircle = []string{"◐", "◓", "◑", "◒"}
+)
+
+func newConfig(opts []Opt) config {
+	c := config{
+		out:         os.Stdout,
+		refresh:     100 * time.Millisecond,
+		logInterval: 5 * time.Second,
+		width:       30,
+		spinner:     SpinnerDots,
+	}
+	for _, opt := range opts {
+		opt(&c)
+	}
+	return c
+}
+
+// Group renders a set of bars concurrently.
+type Group struct {
+	config
+	live     bool // redraw in place, or log lines
+	colorful bool
+	now      func() time.Time
+
+	mu      sync.Mutex
+	bars    []*Bar
+	blk     color.RowsBlock
+	buf     fdBuffer
+	drawn   bool
+	running bool
+	stop    chan struct{}
+	stopped chan struct{}
+	frame   int
+	lastLog time.Time
+}
+
+// NewGroup returns a group of bars, the rendering starts at the
+// first [Group.Add].
+func NewGroup(opts ...Opt) *Group {
+	g := &Group{config: newConfig(opts), now: time.Now}
+	g.live = chk.IsTty(g.out)
+	g.colorful = g.live && chk.IsColorful(g.out)
+	if f, ok := g.out.(interface{ Fd() uintptr }); ok {
+		g.buf.fd = f.Fd()
+	}
+	g.blk = color.NewRowsBlock()
+	g.blk.WithWriter(&g.buf)
+	return g
+}
+
+// fdBuffer collects a redrawing for a single write, it's a
+// [color.Writer] on behalf of the output.
+type fdBuffer struct {
+	bytes.Buffer
+	fd uintptr
+}
+
+func (b *fdBuffer) Fd() uintptr { return b.fd }
+
+// Add adds a bar, total <= 0 means an indeterminate bar.
+func (g *Group) Add(total int64, opts ...Opt) *Bar {
+	c := g.config
+	for _, opt := range opts {
+		opt(&c)
+	}
+	b := &Bar{g: g, title: c.title, width: c.width, bytes: c.bytes, spinner: c.spinner}
+	b.total.Store(total)
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	b.start = g.now()
+	b.sampleAt = b.start
+	g.bars = append(g.bars, b)
+	if !g.running {
+		g.running = true
+		g.lastLog = b.start
+		g.stop, g.stopped = make(chan struct{}), make(chan struct{})
+		go g.loop(g.stop, g.stopped)
+	}
+	return b
+}
+
+// Wait blocks until all bars are done or aborted, and stops the
+// rendering.
+func (g *Group) Wait() {
+	for {
+		g.mu.Lock()
+		pending := false
+		for _, b := range g.bars {
+			pending = pending || b.state == stateRunning
+		}
+		g.mu.Unlock()
+		if !pending {
+			break
+		}
+		time.Sleep(g.refresh)
+	}
+	g.Stop()
+}
+
+// Stop stops the rendering after the final drawing. The bars
+// still running are left as is. A stopped group restarts at the
+// next [Group.Add].
+func (g *Group) Stop() {
+	g.mu.Lock()
+	if !g.running {
+		g.mu.Unlock()
+		return
+	}
+	g.running = false
+	stop, stopped := g.stop, g.stopped
+	g.mu.Unlock()
+
+	close(stop)
+	<-stopped
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.live {
+		g.render()
+		if g.drawn {
+			_, _ = io.WriteString(g.out, "\n")
+		}
+		g.drawn = false
+		g.blk = color.NewRowsBlock()
+		g.blk.WithWriter(&g.buf)
+	} else {
+		g.log(true)
+	}
+	g.bars = g.bars[:0]
+}
+
+// Println prints a line above the bars without tearing them.
+func (g *Group) Println(args ...any) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	line := strings.TrimRight(fmt.Sprintln(args...), "\n")
+	if !g.live || !g.drawn {
+		_, _ = fmt.Fprintln(g.out, line)
+		return
+	}
+	g.buf.Reset()
+	g.blk.Clear()
+	_, _ = g.buf.WriteString(line)
+	_, _ = g.buf.WriteString("\n")
+	_, _ = g.out.Write(g.buf.Bytes())
+	g.blk = color.NewRowsBlock()
+	g.blk.WithWriter(&g.buf)
+	g.drawn = false
+	g.render()
+}
+
+func (g *Group) loop(stop <-chan struct{}, stopped chan<- struct{}) {
+	defer close(stopped)
+	interval := g.refresh
+	if !g.live {
+		// sampling the rates more often than logging
+		interval = min(g.logInterval, time.Second)
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			g.mu.Lock()
+			g.frame++
+			if g.live {
+				g.render()
+			} else {
+				g.log(false)
+			}
+			g.mu.Unlock()
+		}
+	}
+}
+
+// render redraws all bars with a single write, g.mu is held.
+func (g *Group) render() {
+	if len(g.bars) == 0 {
+		return
+	}
+	now := g.now()
+	cols := g.columns()
+	titleWidth := 0
+	for _, b := range g.bars {
+		b.sample(now)
+		titleWidth = max(titleWidth, color.StringWidth(b.title))
+	}
+
+	g.buf.Reset()
+	lines := make([]string, 0, len(g.bars))
+	for _, b := range g.bars {
+		lines = append(lines, b.line(now, titleWidth, cols, g.frame))
+	}
+	if !g.drawn {
+		g.blk.HideCursor()
+	}
+	g.blk.Update(strings.Join(lines, "\n"))
+	if !g.running {
+		g.blk.ShowCursor()
+	}
+	_, _ = g.out.Write(g.buf.Bytes())
+	g.drawn = true
+}
+
+// log prints the plain lines of the bars changed since the
+// last logging, at each log interval or at the end. g.mu is held.
+func (g *Group) log(final bool) {
+	now := g.now()
+	for _, b := range g.bars {
+		b.sample(now)
+	}
+	if !final && now.Sub(g.lastLog) < g.logInterval {
+		return
+	}
+	g.lastLog = now
+	for _, b := range g.bars {
+		if cur := b.Current(); b.logged != b.state || b.loggedValue != cur {
+			_, _ = fmt.Fprintln(g.out, b.logLine(now))
+			b.logged, b.loggedValue = b.state, cur
+		}
+	}
+}
+
+// finished is called by a bar after it's done or aborted.
+func (g *Group) finished(b *Bar) {
+	g.mu.Lock()
+	if !g.live && b.logged != b.state {
+		b.sample(g.now())
+		_, _ = fmt.Fprintln(g.out, b.logLine(g.now()))
+		b.logged, b.loggedValue = b.state, b.Current()
+	}
+	g.mu.Unlock()
+	if b.own {
+		g.Stop()
+	}
+}
+
+// columns returns the terminal width, or 0 if unknown.
+func (g *Group) columns() int {
+	if o, ok := g.out.(*color.Output); ok {
+		return o.Width()
+	}
+	if f, ok := g.out.(*os.File); ok {
+		if cols, _, err := term.GetTtySizeByFd(f.Fd()); err == nil {
+			return cols
+		}
+	}
+	return 0
+}
+
+func (g *Group) paint(clr color.Color, text string) string {
+	if !g.colorful || clr == nil || text == "" {
+		return text
+	}
+	return clr.Wrap(text)
+}
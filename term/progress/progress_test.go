@@ -0,0 +1,195 @@
+package progress
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeClock is a manual clock for the group.
+type fakeClock struct{ t time.Time }
+
+func (c *fakeClock) now() time.Time          { return c.t }
+func (c *fakeClock) advance(d time.Duration) { c.t = c.t.Add(d) }
+
+// countingWriter records each Write.
+type countingWriter struct {
+	bytes.Buffer
+	writes int
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	w.writes++
+	return w.Buffer.Write(p)
+}
+
+func testGroup(out *countingWriter, live bool, opts ...Opt) (*Group, *fakeClock) {
+	clock := &fakeClock{t: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	g := NewGroup(append([]Opt{WithOutput(out), WithRefreshInterval(time.Hour)}, opts...)...)
+	g.now, g.live = clock.now, live
+	return g, clock
+}
+
+func TestBarLine(t *testing.T) {
+	var out countingWriter
+	g, clock := testGroup(&out, true)
+	defer g.Stop()
+
+	b := g.Add(100, WithTitle("copy"), WithWidth(10))
+	s := g.Add(0, WithTitle("scan"), WithSpinner(SpinnerLine...))
+	clock.advance(time.Second)
+	b.Add(50)
+	s.Add(7)
+
+	g.mu.Lock()
+	now := clock.now()
+	b.sample(now)
+	s.sample(now)
+	tests := []struct {
+		got, want string
+	}{
+		{b.line(now, 4, 0, 0), "copy █████░░░░░ 50% 50/100 50/s ETA 1.0s"},
+		{b.line(now, 6, 40, 0), "copy   ███▌░░░ 50% 50/100 50/s ETA 1.0s"}, // narrow terminal
+		{b.line(now, 4, 20, 0), "copy  50% 50/100 50/s ETA 1.0s"},          // no room for the bar
+		{s.line(now, 4, 0, 2), "scan | 7 7.0/s 1.0s"},
+	}
+	g.mu.Unlock()
+
+	b.Add(5)
+	g.mu.Lock()
+	tests = append(tests, struct{ got, want string }{b.line(now, 4, 0, 0), "copy █████▌░░░░ 55% 55/100 50/s ETA 0.9s"})
+	g.mu.Unlock()
+
+	clock.advance(2 * time.Second)
+	b.Done()
+	s.Abort()
+	g.mu.Lock()
+	tests = append(tests,
+		struct{ got, want string }{b.line(now, 4, 0, 0), "copy ██████████ 100% 100/100 in 3.0s"},
+		struct{ got, want string }{s.line(now, 4, 0, 0), "scan ✗ 7 aborted"},
+	)
+	g.mu.Unlock()
+
+	for i, tc := range tests {
+		if tc.got != tc.want {
+			t.Errorf("%d. got\n%q\nwant\n%q", i, tc.got, tc.want)
+		}
+	}
+}
+
+func TestRender(t *testing.T) {
+	var out countingWriter
+	g, clock := testGroup(&out, true)
+
+	a := g.Add(10, WithTitle("a"), WithWidth(5))
+	_ = g.Add(20, WithTitle("bbb"), WithWidth(5))
+	clock.advance(time.Second)
+	a.Add(10)
+
+	g.mu.Lock()
+	g.render()
+	g.render()
+	g.mu.Unlock()
+	if out.writes != 2 {
+		t.Errorf("each redrawing should be a single write, got %d writes", out.writes)
+	}
+	s := out.String()
+	if !strings.Contains(s, "a   █████ 100% 10/10") || !strings.Contains(s, "bbb ░░░░░ 0% 0/20") {
+		t.Errorf("unexpected output %q", s)
+	}
+
+	out.Reset()
+	g.Println("hello")
+	if s = out.String(); !strings.Contains(s, "hello\n") || !strings.Contains(s, "bbb ░░░░░") {
+		t.Errorf("Println should print above the bars, got %q", s)
+	}
+
+	g.Stop()
+	if s = out.String(); !strings.HasSuffix(s, "\n") {
+		t.Errorf("Stop should leave the bars with a newline, got %q", s)
+	}
+}
+
+func TestPlainLog(t *testing.T) {
+	var out countingWriter
+	g, clock := testGroup(&out, false, WithLogInterval(5*time.Second))
+
+	b := g.Add(200, WithTitle("download"), WithBytes())
+	s := g.Add(0, WithTitle("scan"))
+	step := func(d time.Duration, n int64) {
+		clock.advance(d)
+		b.Add(n)
+		g.mu.Lock()
+		g.log(false)
+		g.mu.Unlock()
+	}
+	step(time.Second, 20)
+	if out.Len() != 0 {
+		t.Errorf("nothing should be logged before the interval, got %q", out.String())
+	}
+	step(4*time.Second, 80)
+	step(5*time.Second, 0) // unchanged, not logged again
+	clock.advance(time.Second)
+	b.Done()
+	s.Add(3)
+	g.Stop()
+
+	want := "download: 50% 100 B/200 B 20 B/s ETA 5.0s\n" +
+		"download: done 200 B/200 B in 11s (18 B/s)\n" +
+		"scan: 3 0.9/s 11s\n"
+	if got := out.String(); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestConcurrent(t *testing.T) {
+	var out countingWriter
+	g := NewGroup(WithOutput(&out), WithLogInterval(time.Millisecond))
+	var wg sync.WaitGroup
+	bars := make([]*Bar, 4)
+	for i := range bars {
+		bars[i] = g.Add(1000)
+	}
+	for _, b := range bars {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer b.Done()
+			for range 1000 {
+				b.Increment()
+			}
+		}()
+	}
+	g.Wait()
+	wg.Wait()
+	for i, b := range bars {
+		if b.Current() != 1000 {
+			t.Errorf("bar %d: got %d", i, b.Current())
+		}
+	}
+	if n := strings.Count(out.String(), "done 1000/1000"); n != 4 {
+		t.Errorf("want 4 done lines, got %d:\n%s", n, out.String())
+	}
+}
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		got, want string
+	}{
+		{formatDuration(-1), "?"},
+		{formatDuration(3200 * time.Millisecond), "3.2s"},
+		{formatDuration(45 * time.Second), "45s"},
+		{formatDuration(125 * time.Second), "2m05s"},
+		{formatDuration(80 * time.Minute), "1h20m"},
+		{formatBytes(999), "999 B"},
+		{formatBytes(1536), "1.5 KiB"},
+		{formatBytes(3 << 30), "3.0 GiB"},
+	}
+	for i, tc := range tests {
+		if tc.got != tc.want {
+			t.Errorf("%d. got %q, want %q", i, tc.got, tc.want)
+		}
+	}
+}
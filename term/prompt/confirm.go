@@ -0,0 +1,108 @@
+package prompt
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/hedzr/is/term/input"
+)
+
+// Confirm asks a yes/no question, def is the answer if the user
+// pressed Enter (or entered nothing in the plain mode).
+func Confirm(ctx context.Context, title string, def bool, opts ...Opt) (yes bool, err error) {
+	c := newConfig(opts)
+	w := &confirmW{c: c, title: title, def: def, answer: def}
+	if err = run(ctx, c, w); err == nil {
+		yes = w.answer
+	}
+	return
+}
+
+type confirmW struct {
+	c        *config
+	title    string
+	def      bool
+	answer   bool
+	canceled bool
+}
+
+func (w *confirmW) editing() bool { return false }
+
+func (w *confirmW) choices() string {
+	if w.def {
+		return "[Y/n]"
+	}
+	return "[y/N]"
+}
+
+func (w *confirmW) handle(ev input.Event) (done bool, err error) {
+	if isInterrupt(ev) {
+		w.canceled = true
+		return true, ErrInterrupted
+	}
+	e, ok := ev.(input.KeyEvent)
+	if !ok {
+		return
+	}
+	switch {
+	case e.Key == input.KeyEnter:
+		w.answer = w.def
+		return true, nil
+	case e.Key == input.KeyRune && e.Mod&^input.ModShift == 0:
+		if yes, ok := parseYesNo(string(e.Rune)); ok {
+			w.answer = yes
+			return true, nil
+		}
+	}
+	return
+}
+
+func (w *confirmW) view() (content string, back int) {
+	return w.c.question(w.title) + " " + w.c.hint(w.choices()) + " ", 0
+}
+
+func (w *confirmW) summary() string {
+	if w.canceled {
+		return w.c.question(w.title)
+	}
+	if w.answer {
+		return w.c.question(w.title) + " " + w.c.answer("Yes")
+	}
+	return w.c.question(w.title) + " " + w.c.answer("No")
+}
+
+func (w *confirmW) fallback(r *bufio.Reader, out io.Writer) error {
+	for {
+		_, _ = fmt.Fprintf(out, "%s %s: ", w.c.question(w.title), w.choices())
+		line, err := readLine(r)
+		if err != nil && err != io.EOF {
+			return err
+		}
+		if err == io.EOF {
+			_, _ = fmt.Fprintln(out)
+		}
+		if strings.TrimSpace(line) == "" {
+			w.answer = w.def
+			return nil
+		}
+		if yes, ok := parseYesNo(line); ok {
+			w.answer = yes
+			return nil
+		}
+		_, _ = fmt.Fprintf(out, "  please answer yes or no\n")
+	}
+}
+
+// parseYesNo accepts y, yes, n, no, true, false, 1 and 0.
+func parseYesNo(s string) (yes, ok bool) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "y", "yes", "true", "1":
+		return true, true
+	case "n", "no", "false", "0":
+		return false, true
+	}
+	return
+}
@@ -0,0 +1,200 @@
+package prompt
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+
+	"github.com/hedzr/is/term/color"
+	"github.com/hedzr/is/term/input"
+)
+
+// Input asks the user to enter a line of text.
+//
+// [WithPlaceholder] shows a hint while the line is empty,
+// [WithDefaultText] gives the answer if nothing entered, and
+// [WithValidator] rejects the unacceptable answers, the error
+// message is shown after the line until the text changed.
+//
+// Keys: Left/Right, Home/End (Ctrl+A/Ctrl+E), Backspace/Delete,
+// Ctrl+U (clear) and Ctrl+W (delete a word) edit the line, Enter
+// submits it.
+func Input(ctx context.Context, title string, opts ...Opt) (text string, err error) {
+	c := newConfig(opts)
+	w := &inputW{c: c, title: title}
+	if err = run(ctx, c, w); err == nil {
+		text = w.result()
+	}
+	return
+}
+
+// Password is an [Input] which shows '*' for each character, use
+// [WithMask] to change it, or WithMask(0) to show nothing.
+func Password(ctx context.Context, title string, opts ...Opt) (text string, err error) {
+	return Input(ctx, title, append([]Opt{WithMask('*')}, opts...)...)
+}
+
+type inputW struct {
+	c        *config
+	title    string
+	text     []rune
+	pos      int // cursor position in text
+	errMsg   string
+	canceled bool
+}
+
+func (w *inputW) editing() bool { return true }
+
+func (w *inputW) result() string {
+	if len(w.text) == 0 {
+		return w.c.defText
+	}
+	return string(w.text)
+}
+
+// validate checks the result with the validator.
+func (w *inputW) validate() bool {
+	if w.c.validator != nil {
+		if err := w.c.validator(w.result()); err != nil {
+			w.errMsg = err.Error()
+			return false
+		}
+	}
+	w.errMsg = ""
+	return true
+}
+
+func (w *inputW) insert(rs []rune) {
+	w.text = append(w.text[:w.pos], append(rs, w.text[w.pos:]...)...)
+	w.pos += len(rs)
+}
+
+func (w *inputW) handle(ev input.Event) (done bool, err error) {
+	if isInterrupt(ev) {
+		w.canceled = true
+		return true, ErrInterrupted
+	}
+
+	old := string(w.text)
+	switch e := ev.(type) {
+	case input.PasteEvent:
+		w.insert([]rune(strings.ReplaceAll(e.Text, "\n", " ")))
+	case input.KeyEvent:
+		switch {
+		case e.Key == input.KeyEnter:
+			return w.validate(), nil
+		case e.Key == input.KeyLeft, e.Is('b', input.ModCtrl):
+			w.pos = max(w.pos-1, 0)
+		case e.Key == input.KeyRight, e.Is('f', input.ModCtrl):
+			w.pos = min(w.pos+1, len(w.text))
+		case e.Key == input.KeyHome, e.Is('a', input.ModCtrl):
+			w.pos = 0
+		case e.Key == input.KeyEnd, e.Is('e', input.ModCtrl):
+			w.pos = len(w.text)
+		case e.Key == input.KeyBackspace:
+			if w.pos > 0 {
+				w.text = append(w.text[:w.pos-1], w.text[w.pos:]...)
+				w.pos--
+			}
+		case e.Key == input.KeyDelete, e.Is('d', input.ModCtrl):
+			if w.pos < len(w.text) {
+				w.text = append(w.text[:w.pos], w.text[w.pos+1:]...)
+			}
+		case e.Is('u', input.ModCtrl):
+			w.text, w.pos = w.text[w.pos:], 0
+		case e.Is('k', input.ModCtrl):
+			w.text = w.text[:w.pos]
+		case e.Is('w', input.ModCtrl):
+			i := w.pos
+			for i > 0 && unicode.IsSpace(w.text[i-1]) {
+				i--
+			}
+			for i > 0 && !unicode.IsSpace(w.text[i-1]) {
+				i--
+			}
+			w.text = append(w.text[:i], w.text[w.pos:]...)
+			w.pos = i
+		case e.Key == input.KeyRune && e.Mod&^input.ModShift == 0:
+			w.insert([]rune{e.Rune})
+		}
+	}
+	if string(w.text) != old {
+		w.errMsg = ""
+	}
+	return
+}
+
+// display returns the text as shown, masked if necessary.
+func (w *inputW) display(rs []rune) string {
+	switch {
+	case w.c.mask == 0 && w.c.masking:
+		return ""
+	case w.c.masking:
+		return strings.Repeat(string(w.c.mask), len(rs))
+	}
+	return string(rs)
+}
+
+func (w *inputW) view() (content string, back int) {
+	var sb strings.Builder
+	_, _ = sb.WriteString(w.c.question(w.title))
+	_ = sb.WriteByte(' ')
+	if len(w.text) == 0 {
+		hint := w.c.placeholder
+		if hint == "" && w.c.defText != "" && !w.c.masking {
+			hint = "(" + w.c.defText + ")"
+		}
+		if hint != "" {
+			_, _ = sb.WriteString(w.c.hint(hint))
+			back += color.StringWidth(hint)
+		}
+	} else {
+		_, _ = sb.WriteString(w.display(w.text))
+		back += color.StringWidth(w.display(w.text[w.pos:]))
+	}
+	if w.errMsg != "" {
+		msg := "  ✗ " + w.errMsg
+		_, _ = sb.WriteString(w.c.paint(color.FgRed, msg))
+		back += color.StringWidth(msg)
+	}
+	return sb.String(), back
+}
+
+func (w *inputW) summary() string {
+	if w.canceled {
+		return w.c.question(w.title)
+	}
+	return w.c.question(w.title) + " " + w.c.answer(w.display([]rune(w.result())))
+}
+
+func (w *inputW) fallback(r *bufio.Reader, out io.Writer) error {
+	for {
+		_, _ = fmt.Fprint(out, w.c.question(w.title))
+		if w.c.placeholder != "" {
+			_, _ = fmt.Fprintf(out, " (%s)", w.c.placeholder)
+		}
+		if w.c.defText != "" && !w.c.masking {
+			_, _ = fmt.Fprintf(out, " [%s]", w.c.defText)
+		}
+		_, _ = fmt.Fprint(out, ": ")
+
+		line, err := readLine(r)
+		if err != nil && err != io.EOF {
+			return err
+		}
+		if err == io.EOF {
+			_, _ = fmt.Fprintln(out)
+		}
+		w.text = []rune(line)
+		if w.validate() {
+			return nil
+		}
+		if err == io.EOF {
+			return fmt.Errorf("prompt: %s: %w", w.errMsg, io.ErrUnexpectedEOF)
+		}
+		_, _ = fmt.Fprintf(out, "  ✗ %s\n", w.errMsg)
+	}
+}
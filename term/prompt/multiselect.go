@@ -0,0 +1,175 @@
+package prompt
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hedzr/is/term/input"
+)
+
+// MultiSelect asks the user to choose some of items, and returns
+// the 0-based indices of the chosen in ascending order. The items
+// given by [WithDefault] are checked initially.
+//
+// Keys: Space toggles the focused item, Right/Left checks/unchecks
+// it, Ctrl+A toggles all visible items, and the others are same as
+// [Select].
+//
+// In the plain mode, the user enters the numbers separated by
+// comma or space.
+func MultiSelect(ctx context.Context, title string, items []string, opts ...Opt) (indices []int, err error) {
+	if len(items) == 0 {
+		return nil, ErrNoItems
+	}
+	c := newConfig(opts)
+	w := &multiSelectW{list: newList(c, title, items), checked: make([]bool, len(items))}
+	for _, i := range c.def {
+		if i >= 0 && i < len(items) {
+			w.checked[i] = true
+		}
+	}
+	if err = run(ctx, c, w); err == nil {
+		indices = w.result()
+	}
+	return
+}
+
+type multiSelectW struct {
+	*list
+	checked []bool
+}
+
+func (w *multiSelectW) editing() bool { return false }
+
+func (w *multiSelectW) result() (indices []int) {
+	indices = []int{}
+	for i, ok := range w.checked {
+		if ok {
+			indices = append(indices, i)
+		}
+	}
+	return
+}
+
+func (w *multiSelectW) handle(ev input.Event) (done bool, err error) {
+	if handled, err := w.list.handle(ev); handled || err != nil {
+		return false, err
+	}
+	e, ok := ev.(input.KeyEvent)
+	if !ok {
+		return
+	}
+	cur := w.current()
+	switch {
+	case e.Key == input.KeyEnter:
+		return true, nil
+	case e.Is(' ', input.ModNone):
+		if cur >= 0 {
+			w.checked[cur] = !w.checked[cur]
+		}
+	case e.Key == input.KeyRight:
+		if cur >= 0 {
+			w.checked[cur] = true
+		}
+	case e.Key == input.KeyLeft:
+		if cur >= 0 {
+			w.checked[cur] = false
+		}
+	case e.Is('a', input.ModCtrl):
+		all := true
+		for _, i := range w.visible {
+			all = all && w.checked[i]
+		}
+		for _, i := range w.visible {
+			w.checked[i] = !all
+		}
+	case e.Key == input.KeyRune && e.Mod&^input.ModShift == 0:
+		w.typed(e.Rune)
+	}
+	return
+}
+
+func (w *multiSelectW) mark(index int) string {
+	if w.checked[index] {
+		return w.c.paint(colorChecked, "◉ ")
+	}
+	return "○ "
+}
+
+func (w *multiSelectW) view() (content string, back int) {
+	return w.list.view("(type to filter, space to toggle, enter to confirm)", w.mark), 0
+}
+
+func (w *multiSelectW) summary() string {
+	if w.canceled {
+		return w.c.question(w.title)
+	}
+	var names []string
+	for _, i := range w.result() {
+		names = append(names, w.items[i])
+	}
+	return w.c.question(w.title) + " " + w.c.answer(strings.Join(names, ", "))
+}
+
+func (w *multiSelectW) fallback(r *bufio.Reader, out io.Writer) error {
+	w.printItems(out)
+	var def []string
+	for _, i := range w.result() {
+		def = append(def, strconv.Itoa(i+1))
+	}
+	for {
+		_, _ = fmt.Fprintf(out, "Enter numbers separated by comma (1-%d) [%s]: ", len(w.items), strings.Join(def, ","))
+		line, err := readLine(r)
+		if err != nil && err != io.EOF {
+			return err
+		}
+		if err == io.EOF {
+			_, _ = fmt.Fprintln(out)
+		}
+		if strings.TrimSpace(line) == "" {
+			return nil
+		}
+		chosen, bad := w.parseChoices(line)
+		if bad == "" {
+			for i := range w.checked {
+				w.checked[i] = false
+			}
+			for _, i := range chosen {
+				w.checked[i] = true
+			}
+			return nil
+		}
+		_, _ = fmt.Fprintf(out, "  invalid choice %q\n", bad)
+	}
+}
+
+// parseChoices parses the numbers or ranges (such as "2-4"),
+// bad is the first invalid field.
+func (w *multiSelectW) parseChoices(line string) (chosen []int, bad string) {
+	fields := strings.FieldsFunc(line, func(r rune) bool { return r == ',' || r == ' ' || r == '\t' })
+	for _, f := range fields {
+		if i, ok := w.parseChoice(f); ok {
+			chosen = append(chosen, i)
+			continue
+		}
+		if from, to, ok := strings.Cut(f, "-"); ok {
+			a, ok1 := w.parseChoice(from)
+			b, ok2 := w.parseChoice(to)
+			if !ok1 || !ok2 || a > b {
+				return nil, f
+			}
+			for i := a; i <= b; i++ {
+				chosen = append(chosen, i)
+			}
+			continue
+		}
+		return nil, f
+	}
+	sort.Ints(chosen)
+	return
+}
@@ -0,0 +1,252 @@
+// Package prompt provides the interactive prompt widgets:
+// [Select], [MultiSelect], [Confirm], [Input] and [Password].
+//
+// In a terminal, a widget switches the input into raw mode and
+// redraws itself with [color.RowsBlock] on each key press. If the
+// input or the output is not a terminal (piped, redirected, in
+// CI, ...), a widget falls back to a plain numbered prompt which
+// reads lines, so the same code path works everywhere:
+//
+//	idx, err := prompt.Select(ctx, "Pick a fruit", []string{"apple", "banana", "cherry"})
+//	ok, err := prompt.Confirm(ctx, "Continue?", true)
+//	name, err := prompt.Input(ctx, "Your name", prompt.WithPlaceholder("anonymous"),
+//	  prompt.WithValidator(func(s string) error { ... }))
+//
+// Ctrl+C or Esc interrupts a widget with [ErrInterrupted].
+package prompt
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/hedzr/is/term"
+	"github.com/hedzr/is/term/chk"
+	"github.com/hedzr/is/term/color"
+	"github.com/hedzr/is/term/input"
+)
+
+// ErrInterrupted will be returned if the user pressed Ctrl+C
+// or Esc.
+var ErrInterrupted = errors.New("prompt: interrupted")
+
+// ErrNoItems will be returned if [Select] was called without items.
+var ErrNoItems = errors.New("prompt: no items to select")
+
+// Opt is a functional option for the widgets.
+type Opt func(c *config)
+
+type config struct {
+	in          io.Reader
+	out         io.Writer
+	pageSize    int
+	def         []int  // default choices
+	defText     string // default text of Input
+	placeholder string
+	validator   func(string) error
+	mask        rune
+	masking     bool
+	colorful    bool
+}
+
+// WithIO sets the input and output, default is os.Stdin and os.Stdout.
+func WithIO(in io.Reader, out io.Writer) Opt {
+	return func(c *config) {
+		c.in, c.out = in, out
+	}
+}
+
+// WithPageSize sets the count of visible items of [Select] and
+// [MultiSelect], default is 7.
+func WithPageSize(n int) Opt {
+	return func(c *config) {
+		if n > 0 {
+			c.pageSize = n
+		}
+	}
+}
+
+// WithDefault sets the default choice(s), the 0-based indices.
+// [Select] uses the first one.
+func WithDefault(indices ...int) Opt {
+	return func(c *config) {
+		c.def = indices
+	}
+}
+
+// WithDefaultText sets the default answer of [Input], which will be
+// returned if the user entered nothing.
+func WithDefaultText(text string) Opt {
+	return func(c *config) {
+		c.defText = text
+	}
+}
+
+// WithPlaceholder sets the hint shown in an empty [Input].
+func WithPlaceholder(text string) Opt {
+	return func(c *config) {
+		c.placeholder = text
+	}
+}
+
+// WithValidator sets the validator of [Input], the answer is
+// accepted only if validator returns nil.
+func WithValidator(validator func(text string) error) Opt {
+	return func(c *config) {
+		c.validator = validator
+	}
+}
+
+// WithMask hides the characters of [Input] by showing mask instead,
+// 0 means nothing shown. [Password] uses '*' by default.
+func WithMask(mask rune) Opt {
+	return func(c *config) {
+		c.mask, c.masking = mask, true
+	}
+}
+
+func newConfig(opts []Opt) *config {
+	c := &config{in: os.Stdin, out: os.Stdout, pageSize: 7}
+	for _, opt := range opts {
+		opt(c)
+	}
+	c.colorful = chk.IsTty(c.out) && chk.IsColorful(c.out)
+	return c
+}
+
+// interactive reports whether both sides are terminals.
+func (c *config) interactive() (in, out *os.File, ok bool) {
+	if in, ok = c.in.(*os.File); !ok {
+		return
+	}
+	if out, ok = c.out.(*os.File); !ok {
+		return
+	}
+	ok = chk.IsTty(in) && chk.IsTty(out)
+	return
+}
+
+// paint wraps text with clr if the output is colorful.
+func (c *config) paint(clr color.Color, text string) string {
+	if !c.colorful || text == "" {
+		return text
+	}
+	return clr.Wrap(text)
+}
+
+// widget is the model of a prompt widget.
+type widget interface {
+	// handle updates the model with an input event.
+	handle(ev input.Event) (done bool, err error)
+	// view returns the lines to draw, and the columns to move the
+	// cursor back from the end of the last line.
+	view() (content string, back int)
+	// summary is the final line drawn after done.
+	summary() string
+	// editing reports whether the terminal cursor should be
+	// visible, i.e. the widget has an editable line.
+	editing() bool
+	// fallback runs the widget in the plain line mode.
+	fallback(r *bufio.Reader, w io.Writer) error
+}
+
+// run drives a widget in a terminal, or in the plain line mode.
+//
+// Note that a blocking read cannot be interrupted, ctx is checked
+// after each key press.
+func run(ctx context.Context, c *config, w widget) (err error) {
+	in, out, ok := c.interactive()
+	if !ok {
+		return w.fallback(bufio.NewReader(c.in), c.out)
+	}
+
+	fd := int(in.Fd())
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		return w.fallback(bufio.NewReader(c.in), c.out)
+	}
+	defer func() { _ = term.Restore(fd, state) }()
+
+	blk := color.NewRowsBlock()
+	blk.WithWriter(out)
+	if !w.editing() {
+		blk.HideCursor()
+		defer blk.ShowCursor()
+	}
+	render := func(content string, back int) {
+		blk.Update(strings.ReplaceAll(content, "\n", "\r\n"))
+		if back > 0 {
+			blk.Cursor().LeftNow(back)
+		}
+	}
+
+	d := input.NewDecoder()
+	buf := make([]byte, 256)
+	for {
+		render(w.view())
+		if err = ctx.Err(); err != nil {
+			break
+		}
+
+		n, rerr := in.Read(buf)
+		// a key press, even an escape sequence, arrives in one
+		// read generally, so a lone ESC is the Escape key.
+		events := append(d.Feed(buf[:n]), d.Flush()...)
+		var done bool
+		for _, ev := range events {
+			if done, err = w.handle(ev); done || err != nil {
+				break
+			}
+		}
+		if done || err != nil {
+			break
+		}
+		if rerr != nil {
+			err = rerr
+			break
+		}
+	}
+
+	if err == nil || errors.Is(err, ErrInterrupted) {
+		render(w.summary(), 0)
+	}
+	_, _ = out.WriteString("\r\n")
+	return
+}
+
+const colorChecked = color.FgGreen
+
+// question returns the leading part of the first line of a widget.
+func (c *config) question(title string) string {
+	return c.paint(color.FgGreen, "?") + " " + c.paint(color.BgBoldOrBright, title)
+}
+
+// answer paints the final answer in summary.
+func (c *config) answer(text string) string {
+	return c.paint(color.FgCyan, text)
+}
+
+// hint paints the help text.
+func (c *config) hint(text string) string {
+	return c.paint(color.FgDarkGray, text)
+}
+
+// isInterrupt reports whether ev is Ctrl+C or Esc.
+func isInterrupt(ev input.Event) bool {
+	ke, ok := ev.(input.KeyEvent)
+	return ok && (ke.Is('c', input.ModCtrl) || ke.Key == input.KeyEscape && ke.Mod == input.ModNone)
+}
+
+// readLine reads a line in the plain mode, io.EOF will be
+// returned only if nothing read.
+func readLine(r *bufio.Reader) (line string, err error) {
+	line, err = r.ReadString('\n')
+	if err == io.EOF && line != "" {
+		err = nil
+	}
+	line = strings.TrimRight(line, "\r\n")
+	return
+}
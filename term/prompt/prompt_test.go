@@ -0,0 +1,258 @@
+package prompt
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hedzr/is/term/input"
+)
+
+var fruits = []string{"apple", "banana", "cherry", "date", "elderberry", "fig", "grape"}
+
+// feed decodes keys and sends the events to w, it returns what
+// the last handle returned. Each chunk is decoded as a separate
+// read, so that a lone ESC can be the Escape key.
+func feed(t *testing.T, w widget, chunks ...string) (done bool, err error) {
+	t.Helper()
+	d := input.NewDecoder()
+	var events []input.Event
+	for _, keys := range chunks {
+		events = append(append(events, d.Feed([]byte(keys))...), d.Flush()...)
+	}
+	for i, ev := range events {
+		if done, err = w.handle(ev); done || err != nil {
+			if i != len(events)-1 {
+				t.Fatalf("finished at event %d (%v) of %d", i, ev, len(events))
+			}
+			return
+		}
+	}
+	return
+}
+
+func plainConfig(opts ...Opt) *config {
+	return newConfig(append([]Opt{WithIO(strings.NewReader(""), io.Discard)}, opts...))
+}
+
+func TestSelectKeys(t *testing.T) {
+	tests := []struct {
+		keys []string
+		opts []Opt
+		want int
+	}{
+		{[]string{"\r"}, nil, 0},
+		{[]string{"\x1b[B\x1b[B\r"}, nil, 2},
+		{[]string{"\x1b[A\r"}, nil, 0},
+		{[]string{"\x1b[F\r"}, nil, 6},
+		{[]string{"\x1b[6~\r"}, []Opt{WithPageSize(3)}, 3},
+		{[]string{"er\r"}, nil, 2},                // cherry, elderberry
+		{[]string{"er\x1b[B\r"}, nil, 4},          // elderberry
+		{[]string{"erx\x7f\x7f\x7fgr\r"}, nil, 6}, // backspace edits the filter
+		{[]string{"zz", "\x1b", "\r"}, nil, 0},    // esc clears the filter
+		{[]string{"\r"}, []Opt{WithDefault(5)}, 5},
+		{[]string{"a\r"}, []Opt{WithDefault(3)}, 3}, // date still matches
+	}
+	for i, tc := range tests {
+		w := &selectW{list: newList(plainConfig(tc.opts...), "fruit", fruits)}
+		if c := w.c; len(c.def) > 0 {
+			w.focus(c.def[0])
+		}
+		done, err := feed(t, w, tc.keys...)
+		if !done || err != nil || w.chosen != tc.want {
+			t.Errorf("%d. keys %q: got %d (done=%v, err=%v), want %d", i, tc.keys, w.chosen, done, err, tc.want)
+		}
+	}
+}
+
+func TestSelectView(t *testing.T) {
+	w := &selectW{list: newList(plainConfig(WithPageSize(3)), "fruit", fruits)}
+	_, _ = feed(t, w, "\x1b[B\x1b[B\x1b[B")
+	content, _ := w.view()
+	want := "? fruit (type to filter, ↑↓ to move, enter to select)\n  banana ↑\n  cherry\n❯ date ↓"
+	if content != want {
+		t.Errorf("got\n%s\nwant\n%s", content, want)
+	}
+
+	_, _ = feed(t, w, "zz")
+	if content, _ = w.view(); content != "? fruit zz\n  (no matches)" {
+		t.Errorf("got %q", content)
+	}
+	if done, _ := feed(t, w, "\r"); done {
+		t.Error("enter should do nothing without matches")
+	}
+	if _, err := feed(t, w, "\x1b", "\x1b"); !errors.Is(err, ErrInterrupted) {
+		t.Errorf("the second esc should interrupt, got %v", err)
+	}
+}
+
+func TestMultiSelectKeys(t *testing.T) {
+	tests := []struct {
+		keys []string
+		opts []Opt
+		want []int
+	}{
+		{[]string{"\r"}, nil, []int{}},
+		{[]string{" \x1b[B\x1b[B \r"}, nil, []int{0, 2}},
+		{[]string{"\x1b[C\x1b[C\x1b[B\x1b[C\r"}, nil, []int{0, 1}},
+		{[]string{"\x1b[D\r"}, []Opt{WithDefault(0, 3)}, []int{3}},
+		{[]string{"an\x01\r"}, nil, []int{1}},                      // ctrl+a toggles visible ones
+		{[]string{"\x01\x01 \r"}, nil, []int{0}},                   // all, none, the first
+		{[]string{"e\x01", "\x1b", " \r"}, nil, []int{2, 3, 4, 6}}, // keeps the focus after esc
+	}
+	for i, tc := range tests {
+		c := plainConfig(tc.opts...)
+		w := &multiSelectW{list: newList(c, "fruits", fruits), checked: make([]bool, len(fruits))}
+		for _, j := range c.def {
+			w.checked[j] = true
+		}
+		done, err := feed(t, w, tc.keys...)
+		if got := w.result(); !done || err != nil || !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%d. keys %q: got %v (done=%v, err=%v), want %v", i, tc.keys, got, done, err, tc.want)
+		}
+	}
+}
+
+func TestConfirmKeys(t *testing.T) {
+	tests := []struct {
+		keys string
+		def  bool
+		want bool
+		err  error
+	}{
+		{"\r", true, true, nil},
+		{"\r", false, false, nil},
+		{"xn", true, false, nil},
+		{"Y", false, true, nil},
+		{"\x03", true, false, ErrInterrupted},
+	}
+	for i, tc := range tests {
+		w := &confirmW{c: plainConfig(), title: "ok?", def: tc.def, answer: tc.def}
+		_, err := feed(t, w, tc.keys)
+		if !errors.Is(err, tc.err) || err == nil && w.answer != tc.want {
+			t.Errorf("%d. keys %q: got %v, %v", i, tc.keys, w.answer, err)
+		}
+	}
+}
+
+func TestInputKeys(t *testing.T) {
+	notEmpty := WithValidator(func(s string) error {
+		if s == "" {
+			return errors.New("required")
+		}
+		return nil
+	})
+	tests := []struct {
+		keys string
+		opts []Opt
+		want string
+		done bool
+	}{
+		{"hello\r", nil, "hello", true},
+		{"helo\x1b[Dl\r", nil, "hello", true},
+		{"world\x1b[Hhello \r", nil, "hello world", true},
+		{"ab\x1b[D\x1b[3~\r", nil, "a", true},
+		{"foo bar\x17\r", nil, "foo ", true},
+		{"foo bar\x15\r", []Opt{WithDefaultText("x")}, "x", true},
+		{"\x1b[200~a\nb\x1b[201~\r", nil, "a b", true},
+		{"\r", []Opt{notEmpty}, "", false},
+		{"\rok\r", []Opt{notEmpty}, "ok", true},
+	}
+	for i, tc := range tests {
+		w := &inputW{c: plainConfig(tc.opts...), title: "name"}
+		done, err := feed(t, w, tc.keys)
+		if err != nil || done != tc.done || done && w.result() != tc.want {
+			t.Errorf("%d. keys %q: got %q (done=%v, err=%v), want %q", i, tc.keys, w.result(), done, err, tc.want)
+		}
+	}
+}
+
+func TestInputView(t *testing.T) {
+	w := &inputW{c: plainConfig(WithPlaceholder("anonymous")), title: "name"}
+	if content, back := w.view(); content != "? name anonymous" || back != 9 {
+		t.Errorf("got %q, %d", content, back)
+	}
+	_, _ = feed(t, w, "中文\x1b[D")
+	if content, back := w.view(); content != "? name 中文" || back != 2 {
+		t.Errorf("got %q, %d", content, back)
+	}
+
+	w = &inputW{c: plainConfig(WithMask('*'), WithValidator(func(string) error { return errors.New("bad") })), title: "pwd"}
+	_, _ = feed(t, w, "abc\r")
+	if content, back := w.view(); content != "? pwd ***  ✗ bad" || back != 7 {
+		t.Errorf("got %q, %d", content, back)
+	}
+}
+
+func TestFallback(t *testing.T) {
+	ctx := context.Background()
+	with := func(in string) (Opt, *bytes.Buffer) {
+		var out bytes.Buffer
+		return WithIO(strings.NewReader(in), &out), &out
+	}
+
+	opt, out := with("9\ncherry\n")
+	idx, err := Select(ctx, "fruit", fruits[:3], opt)
+	if err != nil || idx != 2 {
+		t.Errorf("Select got %d, %v", idx, err)
+	}
+	if want := "? fruit\n  1) apple\n  2) banana\n  3) cherry\nEnter a number (1-3) [1]:   invalid choice \"9\"\nEnter a number (1-3) [1]: "; out.String() != want {
+		t.Errorf("Select output:\n%q\nwant\n%q", out.String(), want)
+	}
+
+	opt, _ = with("")
+	if idx, err = Select(ctx, "fruit", fruits, opt, WithDefault(4)); err != nil || idx != 4 {
+		t.Errorf("Select with EOF got %d, %v", idx, err)
+	}
+	if _, err = Select(ctx, "fruit", nil); !errors.Is(err, ErrNoItems) {
+		t.Errorf("Select without items got %v", err)
+	}
+
+	opt, _ = with("1, 3-4 grape\n")
+	indices, err := MultiSelect(ctx, "fruits", fruits, opt)
+	if err != nil || !reflect.DeepEqual(indices, []int{0, 2, 3, 6}) {
+		t.Errorf("MultiSelect got %v, %v", indices, err)
+	}
+	opt, _ = with("\n")
+	if indices, err = MultiSelect(ctx, "fruits", fruits, opt, WithDefault(1)); err != nil || !reflect.DeepEqual(indices, []int{1}) {
+		t.Errorf("MultiSelect default got %v, %v", indices, err)
+	}
+
+	opt, out = with("maybe\nno\n")
+	if yes, err := Confirm(ctx, "sure?", true, opt); err != nil || yes {
+		t.Errorf("Confirm got %v, %v", yes, err)
+	}
+	if !strings.HasPrefix(out.String(), "? sure? [Y/n]: ") {
+		t.Errorf("Confirm output %q", out.String())
+	}
+	opt, _ = with("")
+	if yes, err := Confirm(ctx, "sure?", true, opt); err != nil || !yes {
+		t.Errorf("Confirm with EOF got %v, %v", yes, err)
+	}
+
+	opt, _ = with("\n")
+	if text, err := Input(ctx, "name", opt, WithDefaultText("bob")); err != nil || text != "bob" {
+		t.Errorf("Input got %q, %v", text, err)
+	}
+	opt, _ = with("secret")
+	if text, err := Password(ctx, "pwd", opt); err != nil || text != "secret" {
+		t.Errorf("Password got %q, %v", text, err)
+	}
+	opt, out = with("\n")
+	_, err = Input(ctx, "name", opt, WithValidator(func(s string) error {
+		if s == "" {
+			return errors.New("required")
+		}
+		return nil
+	}))
+	if !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("Input with EOF got %v", err)
+	}
+	if !strings.Contains(out.String(), "✗ required") {
+		t.Errorf("Input output %q", out.String())
+	}
+}
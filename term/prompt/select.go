@@ -0,0 +1,282 @@
+package prompt
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/hedzr/is/term/input"
+)
+
+// Select asks the user to choose one of items, and returns the
+// 0-based index of the chosen.
+//
+// Keys: Up/Down, PgUp/PgDn and Home/End move the selection, typing
+// filters the items, Enter chooses. Esc clears the filter first,
+// or interrupts.
+//
+// In the plain mode, the items are listed with numbers and the
+// user enters a number or an item text.
+func Select(ctx context.Context, title string, items []string, opts ...Opt) (index int, err error) {
+	if len(items) == 0 {
+		return -1, ErrNoItems
+	}
+	c := newConfig(opts)
+	w := &selectW{list: newList(c, title, items)}
+	if len(c.def) > 0 {
+		w.focus(c.def[0])
+	}
+	if err = run(ctx, c, w); err == nil {
+		index = w.chosen
+	} else {
+		index = -1
+	}
+	return
+}
+
+// list is the filterable and scrollable item list shared by
+// [Select] and [MultiSelect].
+type list struct {
+	c        *config
+	title    string
+	items    []string
+	filter   []rune
+	visible  []int // indices of matched items
+	cur      int   // position in visible
+	offset   int   // first row of the page
+	canceled bool
+}
+
+func newList(c *config, title string, items []string) *list {
+	l := &list{c: c, title: title, items: items}
+	l.refilter()
+	return l
+}
+
+// refilter rebuilds visible with the case-insensitive substring
+// matching, and keeps the current item focused if possible.
+func (l *list) refilter() {
+	current := l.current()
+	needle := strings.ToLower(string(l.filter))
+	l.visible = l.visible[:0]
+	for i, it := range l.items {
+		if needle == "" || strings.Contains(strings.ToLower(it), needle) {
+			l.visible = append(l.visible, i)
+		}
+	}
+	l.cur, l.offset = 0, 0
+	l.focus(current)
+}
+
+// current returns the focused item index, or -1.
+func (l *list) current() int {
+	if l.cur >= 0 && l.cur < len(l.visible) {
+		return l.visible[l.cur]
+	}
+	return -1
+}
+
+// focus moves the cursor to the item index if it is visible.
+func (l *list) focus(index int) {
+	for pos, i := range l.visible {
+		if i == index {
+			l.move(pos - l.cur)
+			return
+		}
+	}
+}
+
+// move moves the cursor by delta rows, and scrolls the page.
+func (l *list) move(delta int) {
+	if len(l.visible) == 0 {
+		return
+	}
+	l.cur = min(max(l.cur+delta, 0), len(l.visible)-1)
+	if l.cur < l.offset {
+		l.offset = l.cur
+	} else if l.cur >= l.offset+l.c.pageSize {
+		l.offset = l.cur - l.c.pageSize + 1
+	}
+}
+
+// handle processes the navigation and filtering keys, handled
+// reports whether ev was consumed.
+func (l *list) handle(ev input.Event) (handled bool, err error) {
+	if isInterrupt(ev) {
+		ke := ev.(input.KeyEvent)
+		if ke.Key == input.KeyEscape && len(l.filter) > 0 {
+			l.filter = l.filter[:0]
+			l.refilter()
+			return true, nil
+		}
+		l.canceled = true
+		return true, ErrInterrupted
+	}
+
+	switch e := ev.(type) {
+	case input.KeyEvent:
+		handled = true
+		switch {
+		case e.Key == input.KeyUp, e.Is('p', input.ModCtrl):
+			l.move(-1)
+		case e.Key == input.KeyDown, e.Is('n', input.ModCtrl):
+			l.move(1)
+		case e.Key == input.KeyPgUp:
+			l.move(-l.c.pageSize)
+		case e.Key == input.KeyPgDn:
+			l.move(l.c.pageSize)
+		case e.Key == input.KeyHome:
+			l.move(-len(l.visible))
+		case e.Key == input.KeyEnd:
+			l.move(len(l.visible))
+		case e.Key == input.KeyBackspace:
+			if len(l.filter) > 0 {
+				l.filter = l.filter[:len(l.filter)-1]
+				l.refilter()
+			}
+		case e.Is('u', input.ModCtrl):
+			l.filter = l.filter[:0]
+			l.refilter()
+		default:
+			handled = false
+		}
+	case input.PasteEvent:
+		l.filter = append(l.filter, []rune(strings.ReplaceAll(e.Text, "\n", " "))...)
+		l.refilter()
+		handled = true
+	}
+	return
+}
+
+// typed appends a rune to the filter.
+func (l *list) typed(r rune) {
+	l.filter = append(l.filter, r)
+	l.refilter()
+}
+
+// view renders the title line and the page of items, mark returns
+// the leading mark of an item.
+func (l *list) view(help string, mark func(index int) string) string {
+	var sb strings.Builder
+	_, _ = sb.WriteString(l.c.question(l.title))
+	_, _ = sb.WriteString(" ")
+	if len(l.filter) > 0 {
+		_, _ = sb.WriteString(string(l.filter))
+	} else {
+		_, _ = sb.WriteString(l.c.hint(help))
+	}
+
+	if len(l.visible) == 0 {
+		_, _ = sb.WriteString("\n  ")
+		_, _ = sb.WriteString(l.c.hint("(no matches)"))
+		return sb.String()
+	}
+
+	end := min(l.offset+l.c.pageSize, len(l.visible))
+	for pos := l.offset; pos < end; pos++ {
+		i := l.visible[pos]
+		_ = sb.WriteByte('\n')
+		text := l.items[i]
+		if pos == l.cur {
+			_, _ = sb.WriteString(l.c.answer("❯ "))
+			text = l.c.answer(text)
+		} else {
+			_, _ = sb.WriteString("  ")
+		}
+		if mark != nil {
+			_, _ = sb.WriteString(mark(i))
+		}
+		_, _ = sb.WriteString(text)
+		if pos == l.offset && l.offset > 0 {
+			_, _ = sb.WriteString(l.c.hint(" ↑"))
+		} else if pos == end-1 && end < len(l.visible) {
+			_, _ = sb.WriteString(l.c.hint(" ↓"))
+		}
+	}
+	return sb.String()
+}
+
+// printItems lists the items with numbers in the plain mode.
+func (l *list) printItems(w io.Writer) {
+	_, _ = fmt.Fprintf(w, "%s\n", l.c.question(l.title))
+	width := len(strconv.Itoa(len(l.items)))
+	for i, it := range l.items {
+		_, _ = fmt.Fprintf(w, "  %*d) %s\n", width, i+1, it)
+	}
+}
+
+// parseChoice parses a number (1-based) or an item text.
+func (l *list) parseChoice(s string) (index int, ok bool) {
+	s = strings.TrimSpace(s)
+	if n, err := strconv.Atoi(s); err == nil {
+		return n - 1, n >= 1 && n <= len(l.items)
+	}
+	for i, it := range l.items {
+		if strings.EqualFold(it, s) {
+			return i, true
+		}
+	}
+	return -1, false
+}
+
+type selectW struct {
+	*list
+	chosen int
+}
+
+func (w *selectW) editing() bool { return false }
+
+func (w *selectW) handle(ev input.Event) (done bool, err error) {
+	if handled, err := w.list.handle(ev); handled || err != nil {
+		return false, err
+	}
+	if e, ok := ev.(input.KeyEvent); ok {
+		switch {
+		case e.Key == input.KeyEnter:
+			if w.chosen = w.current(); w.chosen >= 0 {
+				return true, nil
+			}
+		case e.Key == input.KeyRune && e.Mod&^input.ModShift == 0:
+			w.typed(e.Rune)
+		}
+	}
+	return
+}
+
+func (w *selectW) view() (content string, back int) {
+	return w.list.view("(type to filter, ↑↓ to move, enter to select)", nil), 0
+}
+
+func (w *selectW) summary() string {
+	if w.canceled {
+		return w.c.question(w.title)
+	}
+	return w.c.question(w.title) + " " + w.c.answer(w.items[w.chosen])
+}
+
+func (w *selectW) fallback(r *bufio.Reader, out io.Writer) error {
+	w.printItems(out)
+	def := max(w.current(), 0)
+	for {
+		_, _ = fmt.Fprintf(out, "Enter a number (1-%d) [%d]: ", len(w.items), def+1)
+		line, err := readLine(r)
+		if err != nil && err != io.EOF {
+			return err
+		}
+		if err == io.EOF {
+			_, _ = fmt.Fprintln(out)
+		}
+		if strings.TrimSpace(line) == "" {
+			w.chosen = def
+			return nil
+		}
+		if i, ok := w.parseChoice(line); ok {
+			w.chosen = i
+			return nil
+		}
+		_, _ = fmt.Fprintf(out, "  invalid choice %q\n", line)
+	}
+}
@@ -0,0 +1,50 @@
+package table
+
+// Border is the set of the box-drawing characters of a table.
+// An empty line part (such as Top) omits that line.
+type Border struct {
+	Top, TopLeft, TopMid, TopRight             string
+	Left, Mid, Right                           string // the vertical lines
+	Sep, SepLeft, SepMid, SepRight             string // the line under header
+	Bottom, BottomLeft, BottomMid, BottomRight string
+}
+
+// The predefined border styles.
+var (
+	// BorderNone separates the columns by two spaces, and
+	// underlines the header with dashes.
+	BorderNone = Border{
+		Mid: "  ",
+		Sep: "-", SepMid: "  ",
+	}
+	BorderASCII = Border{
+		Top: "-", TopLeft: "+", TopMid: "+", TopRight: "+",
+		Left: "|", Mid: "|", Right: "|",
+		Sep: "-", SepLeft: "+", SepMid: "+", SepRight: "+",
+		Bottom: "-", BottomLeft: "+", BottomMid: "+", BottomRight: "+",
+	}
+	BorderLight = Border{
+		Top: "─", TopLeft: "┌", TopMid: "┬", TopRight: "┐",
+		Left: "│", Mid: "│", Right: "│",
+		Sep: "─", SepLeft: "├", SepMid: "┼", SepRight: "┤",
+		Bottom: "─", BottomLeft: "└", BottomMid: "┴", BottomRight: "┘",
+	}
+	BorderRounded = Border{
+		Top: "─", TopLeft: "╭", TopMid: "┬", TopRight: "╮",
+		Left: "│", Mid: "│", Right: "│",
+		Sep: "─", SepLeft: "├", SepMid: "┼", SepRight: "┤",
+		Bottom: "─", BottomLeft: "╰", BottomMid: "┴", BottomRight: "╯",
+	}
+	BorderHeavy = Border{
+		Top: "━", TopLeft: "┏", TopMid: "┳", TopRight: "┓",
+		Left: "┃", Mid: "┃", Right: "┃",
+		Sep: "━", SepLeft: "┣", SepMid: "╋", SepRight: "┫",
+		Bottom: "━", BottomLeft: "┗", BottomMid: "┻", BottomRight: "┛",
+	}
+	BorderDouble = Border{
+		Top: "═", TopLeft: "╔", TopMid: "╦", TopRight: "╗",
+		Left: "║", Mid: "║", Right: "║",
+		Sep: "═", SepLeft: "╠", SepMid: "╬", SepRight: "╣",
+		Bottom: "═", BottomLeft: "╚", BottomMid: "╩", BottomRight: "╝",
+	}
+)
@@ -0,0 +1,269 @@
+package table
+
+import (
+	"encoding/csv"
+	"io"
+	"strings"
+
+	"github.com/hedzr/is/term/color"
+)
+
+const ellipsis = "…"
+
+// renderText draws the table with the borders.
+func (t *Table) renderText(w io.Writer, colorful bool, maxWidth int) error {
+	ncol := t.numCols()
+	if ncol == 0 {
+		return nil
+	}
+	b := t.border
+	pad := 1
+	if strings.TrimSpace(b.Mid) == "" {
+		pad = 0
+	}
+
+	text := func(row []string) []string {
+		cells := make([]string, ncol)
+		for i := range min(len(row), ncol) {
+			cells[i] = cellText(row[i], colorful)
+		}
+		return cells
+	}
+	var header []string
+	if len(t.header) > 0 {
+		header = text(t.header)
+	}
+	rows := make([][]string, 0, len(t.rows))
+	for _, row := range t.rows {
+		rows = append(rows, text(row))
+	}
+
+	widths := t.layout(header, rows, ncol, pad, maxWidth)
+
+	var sb strings.Builder
+	hline := func(fill, left, mid, right string) {
+		if fill == "" {
+			return
+		}
+		_, _ = sb.WriteString(left)
+		for i, cw := range widths {
+			if i > 0 {
+				_, _ = sb.WriteString(mid)
+			}
+			_, _ = sb.WriteString(strings.Repeat(fill, cw+2*pad))
+		}
+		_, _ = sb.WriteString(right)
+		_ = sb.WriteByte('\n')
+	}
+	drawRow := func(cells []string, isHeader bool) {
+		lines := make([][]string, ncol)
+		height := 1
+		for i, cell := range cells {
+			lines[i] = t.fit(cell, widths[i])
+			height = max(height, len(lines[i]))
+		}
+		for h := range height {
+			var line strings.Builder
+			_, _ = line.WriteString(b.Left)
+			for i, cw := range widths {
+				if i > 0 {
+					_, _ = line.WriteString(b.Mid)
+				}
+				var s string
+				if h < len(lines[i]) {
+					s = lines[i][h]
+				}
+				if isHeader && colorful && s != "" {
+					s = color.BgBoldOrBright.Wrap(s)
+				}
+				_, _ = line.WriteString(strings.Repeat(" ", pad))
+				_, _ = line.WriteString(t.alignText(s, cw, i))
+				_, _ = line.WriteString(strings.Repeat(" ", pad))
+			}
+			_, _ = line.WriteString(b.Right)
+			_, _ = sb.WriteString(strings.TrimRight(line.String(), " "))
+			_ = sb.WriteByte('\n')
+		}
+	}
+
+	hline(b.Top, b.TopLeft, b.TopMid, b.TopRight)
+	if header != nil {
+		drawRow(header, true)
+		hline(b.Sep, b.SepLeft, b.SepMid, b.SepRight)
+	}
+	for _, row := range rows {
+		drawRow(row, false)
+	}
+	hline(b.Bottom, b.BottomLeft, b.BottomMid, b.BottomRight)
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// layout computes the widths of the columns, the widest columns
+// are shrunk until the table fits in maxWidth.
+func (t *Table) layout(header []string, rows [][]string, ncol, pad, maxWidth int) []int {
+	widths := make([]int, ncol)
+	measure := func(cells []string) {
+		for i, cell := range cells {
+			for _, line := range strings.Split(cell, "\n") {
+				widths[i] = max(widths[i], color.StringWidth(line))
+			}
+		}
+	}
+	measure(header)
+	for _, row := range rows {
+		measure(row)
+	}
+	for i := range widths {
+		if i < len(t.columns) && t.columns[i].maxWidth > 0 {
+			widths[i] = min(widths[i], t.columns[i].maxWidth)
+		}
+	}
+
+	if maxWidth <= 0 {
+		return widths
+	}
+	b := t.border
+	overhead := color.StringWidth(b.Left) + color.StringWidth(b.Right) +
+		(ncol-1)*color.StringWidth(b.Mid) + ncol*2*pad
+	total := overhead
+	for _, cw := range widths {
+		total += cw
+	}
+	const minWidth = 3
+	for total > maxWidth {
+		widest := 0
+		for i, cw := range widths {
+			if cw > widths[widest] {
+				widest = i
+			}
+		}
+		if widths[widest] <= minWidth {
+			break
+		}
+		widths[widest]--
+		total--
+	}
+	return widths
+}
+
+// fit wraps or truncates a cell into the lines of width.
+func (t *Table) fit(cell string, width int) (lines []string) {
+	if t.truncate {
+		for _, line := range strings.Split(cell, "\n") {
+			lines = append(lines, color.Truncate(line, width, ellipsis))
+		}
+		return
+	}
+	return color.WordWrap(cell, width)
+}
+
+// alignText pads s to width by the alignment of column col.
+func (t *Table) alignText(s string, width, col int) string {
+	var align Align
+	if col < len(t.columns) {
+		align = t.columns[col].align
+	}
+	switch align {
+	case AlignRight:
+		return color.PadLeft(s, width)
+	case AlignCenter:
+		return color.Center(s, width)
+	}
+	return color.PadRight(s, width)
+}
+
+// renderMarkdown writes a GitHub flavored markdown table.
+func (t *Table) renderMarkdown(w io.Writer) error {
+	ncol := t.numCols()
+	if ncol == 0 {
+		return nil
+	}
+	escape := func(row []string) []string {
+		cells := make([]string, ncol)
+		for i := range min(len(row), ncol) {
+			s := cellText(row[i], false)
+			s = strings.ReplaceAll(s, "|", `\|`)
+			cells[i] = strings.ReplaceAll(s, "\n", "<br>")
+		}
+		return cells
+	}
+	header := escape(t.header)
+	rows := make([][]string, 0, len(t.rows))
+	for _, row := range t.rows {
+		rows = append(rows, escape(row))
+	}
+
+	widths := make([]int, ncol)
+	for i := range widths {
+		widths[i] = 3 // the width of "---"
+	}
+	for _, row := range append([][]string{header}, rows...) {
+		for i, cell := range row {
+			widths[i] = max(widths[i], color.StringWidth(cell))
+		}
+	}
+
+	var sb strings.Builder
+	line := func(cells []string) {
+		_, _ = sb.WriteString("|")
+		for i, cell := range cells {
+			_, _ = sb.WriteString(" ")
+			_, _ = sb.WriteString(t.alignText(cell, widths[i], i))
+			_, _ = sb.WriteString(" |")
+		}
+		_ = sb.WriteByte('\n')
+	}
+	line(header)
+	seps := make([]string, ncol)
+	for i, cw := range widths {
+		var align Align
+		if i < len(t.columns) {
+			align = t.columns[i].align
+		}
+		switch align {
+		case AlignRight:
+			seps[i] = strings.Repeat("-", cw-1) + ":"
+		case AlignCenter:
+			seps[i] = ":" + strings.Repeat("-", cw-2) + ":"
+		default:
+			seps[i] = strings.Repeat("-", cw)
+		}
+	}
+	_, _ = sb.WriteString("|")
+	for _, s := range seps {
+		_, _ = sb.WriteString(" " + s + " |")
+	}
+	_ = sb.WriteByte('\n')
+	for _, row := range rows {
+		line(row)
+	}
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// renderCSV writes the header and rows as CSV.
+func (t *Table) renderCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	plain := func(row []string) []string {
+		cells := make([]string, len(row))
+		for i, cell := range row {
+			cells[i] = cellText(cell, false)
+		}
+		return cells
+	}
+	if len(t.header) > 0 {
+		if err := cw.Write(plain(t.header)); err != nil {
+			return err
+		}
+	}
+	for _, row := range t.rows {
+		if err := cw.Write(plain(row)); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,226 @@
+// Package table lays out the rows of text in columns.
+//
+// The cells may contain the CPT markups (such as <b> and
+// <font color=red>, see [color.GetCPT]) or the raw SGR
+// sequences, the widths are measured in terminal columns, so
+// the East Asian wide characters and the colored text are
+// aligned well:
+//
+//	t := table.New(table.WithBorder(table.BorderRounded))
+//	t.SetHeader("Name", "Size", "Status")
+//	t.SetAlign(1, table.AlignRight)
+//	t.Append("is", "12 KiB", "<font color=green>ok</font>")
+//	t.Append("中文", "1.5 MiB", "<b>failed</b>")
+//	_ = t.Render(os.Stdout)
+//
+// A table is fit into the terminal width by wrapping (or
+// truncating, see [WithTruncate]) the widest columns. If the
+// output is not a colorful terminal, the markups and escape
+// sequences are removed. [FormatMarkdown] and [FormatCSV] are
+// for the machine-readable outputs.
+package table
+
+import (
+	"io"
+	"os"
+	"strings"
+
+	"github.com/hedzr/is/term"
+	"github.com/hedzr/is/term/chk"
+	"github.com/hedzr/is/term/color"
+)
+
+// Align is the horizontal alignment of a column.
+type Align int
+
+const (
+	AlignLeft Align = iota
+	AlignRight
+	AlignCenter
+)
+
+// Format is the output format of a table.
+type Format int
+
+const (
+	// FormatAuto is FormatTerminal for a colorful terminal, or
+	// FormatPlain.
+	FormatAuto Format = iota
+	// FormatTerminal draws the borders and the colors.
+	FormatTerminal
+	// FormatPlain draws the borders without any escape sequences.
+	FormatPlain
+	// FormatMarkdown writes a GitHub flavored markdown table.
+	FormatMarkdown
+	// FormatCSV writes the comma-separated values.
+	FormatCSV
+)
+
+// Opt is a functional option for [New].
+type Opt func(t *Table)
+
+// WithBorder sets the border style, default is [BorderLight].
+func WithBorder(b Border) Opt {
+	return func(t *Table) {
+		t.border = b
+	}
+}
+
+// WithFormat sets the output format, default is [FormatAuto].
+func WithFormat(f Format) Opt {
+	return func(t *Table) {
+		t.format = f
+	}
+}
+
+// WithMaxWidth sets the maximal width of the table. The default
+// is the terminal width (see [term.GetTtySize]) if the output is
+// a terminal, or unlimited. Zero or negative means unlimited.
+func WithMaxWidth(n int) Opt {
+	return func(t *Table) {
+		t.maxWidth, t.maxWidthSet = n, true
+	}
+}
+
+// WithColumnMaxWidth limits the width of a column.
+func WithColumnMaxWidth(col, n int) Opt {
+	return func(t *Table) {
+		t.column(col).maxWidth = n
+	}
+}
+
+// WithTruncate truncates the overflowing cells with an ellipsis
+// instead of wrapping them.
+func WithTruncate(truncate bool) Opt {
+	return func(t *Table) {
+		t.truncate = truncate
+	}
+}
+
+// WithAligns sets the alignments of the columns from the first.
+func WithAligns(aligns ...Align) Opt {
+	return func(t *Table) {
+		for i, a := range aligns {
+			t.column(i).align = a
+		}
+	}
+}
+
+// Table is a table of text.
+type Table struct {
+	header      []string
+	rows        [][]string
+	columns     []columnOpts
+	border      Border
+	format      Format
+	maxWidth    int
+	maxWidthSet bool
+	truncate    bool
+}
+
+type columnOpts struct {
+	align    Align
+	maxWidth int
+}
+
+// New returns an empty table.
+func New(opts ...Opt) *Table {
+	t := &Table{border: BorderLight}
+	for _, opt := range opts {
+		opt(t)
+	}
+	return t
+}
+
+func (t *Table) column(i int) *columnOpts {
+	for len(t.columns) <= i {
+		t.columns = append(t.columns, columnOpts{})
+	}
+	return &t.columns[i]
+}
+
+// SetHeader sets the header row.
+func (t *Table) SetHeader(cells ...string) *Table {
+	t.header = cells
+	return t
+}
+
+// SetAlign sets the alignment of a column.
+func (t *Table) SetAlign(col int, align Align) *Table {
+	t.column(col).align = align
+	return t
+}
+
+// Append appends a row, the cells may contain line breaks.
+func (t *Table) Append(cells ...string) *Table {
+	t.rows = append(t.rows, cells)
+	return t
+}
+
+// AppendRows appends some rows.
+func (t *Table) AppendRows(rows [][]string) *Table {
+	t.rows = append(t.rows, rows...)
+	return t
+}
+
+// String renders the table in [FormatPlain] if the format is
+// [FormatAuto].
+func (t *Table) String() string {
+	var sb strings.Builder
+	_ = t.Render(&sb)
+	return sb.String()
+}
+
+// Render writes the table to w.
+func (t *Table) Render(w io.Writer) error {
+	format := t.format
+	if format == FormatAuto {
+		format = FormatPlain
+		if chk.IsTty(w) && chk.IsColorful(w) {
+			format = FormatTerminal
+		}
+	}
+
+	maxWidth := t.maxWidth
+	if !t.maxWidthSet && chk.IsTty(w) {
+		if f, ok := w.(*os.File); ok && f == os.Stdout {
+			maxWidth, _ = term.GetTtySize()
+		} else if ok {
+			maxWidth, _, _ = term.GetTtySizeByFd(f.Fd())
+		} else if o, ok := w.(*color.Output); ok {
+			maxWidth = o.Width()
+		}
+	}
+
+	switch format {
+	case FormatMarkdown:
+		return t.renderMarkdown(w)
+	case FormatCSV:
+		return t.renderCSV(w)
+	}
+	return t.renderText(w, format == FormatTerminal, maxWidth)
+}
+
+// numCols returns the count of columns.
+func (t *Table) numCols() (n int) {
+	n = len(t.header)
+	for _, row := range t.rows {
+		n = max(n, len(row))
+	}
+	return
+}
+
+// cellText returns the cell text for output, the markups translated
+// or stripped.
+func cellText(s string, colorful bool) string {
+	if colorful {
+		if strings.Contains(s, "<") {
+			s = color.GetCPTC().Translate(s, color.Reset)
+		}
+		return s
+	}
+	if strings.Contains(s, "<") {
+		s = color.GetCPTNC().Translate(s, color.Reset)
+	}
+	return term.StripEscapes(s)
+}
@@ -0,0 +1,89 @@
+package table
+
+import (
+	"strings"
+	"testing"
+)
+
+func sample(opts ...Opt) *Table {
+	t := New(opts...)
+	t.SetHeader("Name", "Size", "Status")
+	t.SetAlign(1, AlignRight).SetAlign(2, AlignCenter)
+	t.Append("is", "12 KiB", "<font color=green>ok</font>")
+	t.Append("中文名字", "1.5 MiB", "<b>failed</b> because of a very long reason here")
+	t.Append("a|b", "0", "\x1b[31mred\x1b[0m")
+	return t
+}
+
+func TestRenderPlain(t *testing.T) {
+	got := sample(WithMaxWidth(40)).String()
+	want := `┌──────────┬─────────┬─────────────────┐
+│ Name     │    Size │     Status      │
+├──────────┼─────────┼─────────────────┤
+│ is       │  12 KiB │       ok        │
+│ 中文名字 │ 1.5 MiB │ failed because  │
+│          │         │ of a very long  │
+│          │         │   reason here   │
+│ a|b      │       0 │       red       │
+└──────────┴─────────┴─────────────────┘
+`
+	if got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestRenderTerminal(t *testing.T) {
+	got := sample(WithFormat(FormatTerminal), WithBorder(BorderASCII), WithTruncate(true), WithColumnMaxWidth(2, 10)).String()
+	want := "+----------+---------+------------+\n" +
+		"| \x1b[1mName\x1b[0m     |    \x1b[1mSize\x1b[0m |   \x1b[1mStatus\x1b[0m   |\n" +
+		"+----------+---------+------------+\n" +
+		"| is       |  12 KiB |     \x1b[32mok\x1b[0m     |\n" +
+		"| 中文名字 | 1.5 MiB | \x1b[1mfailed\x1b[0m be… |\n" +
+		"| a|b      |       0 |    \x1b[31mred\x1b[0m     |\n" +
+		"+----------+---------+------------+\n"
+	if got != want {
+		t.Errorf("got\n%q\nwant\n%q", got, want)
+	}
+}
+
+func TestRenderBorderNone(t *testing.T) {
+	tbl := New(WithBorder(BorderNone), WithTruncate(true), WithMaxWidth(30))
+	tbl.SetHeader("Name", "Description")
+	tbl.Append("x", "a long long description of something")
+	tbl.Append("yy")
+	want := "Name  Description\n" +
+		"----  ------------------------\n" +
+		"x     a long long description…\n" +
+		"yy\n"
+	if got := tbl.String(); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestRenderMarkdownCSV(t *testing.T) {
+	got := sample(WithFormat(FormatMarkdown)).String()
+	want := `| Name     |    Size |                  Status                   |
+| -------- | ------: | :---------------------------------------: |
+| is       |  12 KiB |                    ok                     |
+| 中文名字 | 1.5 MiB | failed because of a very long reason here |
+| a\|b     |       0 |                    red                    |
+`
+	if got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+
+	got = sample(WithFormat(FormatCSV)).String()
+	want = "Name,Size,Status\nis,12 KiB,ok\n中文名字,1.5 MiB,failed because of a very long reason here\na|b,0,red\n"
+	if got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestRenderEmpty(t *testing.T) {
+	if got := New().String(); got != "" {
+		t.Errorf("got %q", got)
+	}
+	if got := New().Append("a", "b").String(); !strings.Contains(got, "│ a │ b │") {
+		t.Errorf("got %q", got)
+	}
+}
@@ -97,6 +97,17 @@ func MakeRawWrapped() (deferFunc func(), err error) {
 	return
 }
 
+// MakeRawWrappedFd puts the terminal of fd into raw mode, and
+// returns the function which restores it. Unlike [MakeRawWrapped],
+// which works on stdin, it fits the terminals other than stdin.
+func MakeRawWrappedFd(fd int) (deferFunc func(), err error) {
+	var oldState *term.State
+	if oldState, err = term.MakeRaw(fd); err != nil {
+		return func() {}, err
+	}
+	return func() { _ = term.Restore(fd, oldState) }, nil
+}
+
 type SmallTerm interface {
 	io.Writer
 	ReadLine() (string, error)
@@ -0,0 +1,351 @@
+package terminfo
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Builtin returns an entry from the embedded table, or nil if
+// the terminal name is unknown.
+//
+// The embedded table is a fallback for the systems without a
+// terminfo database (such as Windows, containers, ...), it
+// holds the commonly used capabilities only.
+func Builtin(name string) *Terminfo {
+	builtinOnce.Do(func() {
+		builtins = parseSources(builtinSource)
+	})
+	if ti, ok := builtins[name]; ok {
+		return ti.clone()
+	}
+	return nil
+}
+
+// BuiltinNames returns the names of the embedded entries.
+func BuiltinNames() (names []string) {
+	Builtin("")
+	for _, ti := range builtins {
+		if ti.Name != "" && !inSlice(ti.Name, names) {
+			names = append(names, ti.Name)
+		}
+	}
+	return
+}
+
+var (
+	builtinOnce sync.Once
+	builtins    map[string]*Terminfo
+)
+
+func (ti *Terminfo) clone() *Terminfo {
+	n := &Terminfo{
+		Name:    ti.Name,
+		Names:   append([]string(nil), ti.Names...),
+		Bools:   make(map[string]bool, len(ti.Bools)),
+		Numbers: make(map[string]int, len(ti.Numbers)),
+		Strings: make(map[string]string, len(ti.Strings)),
+		Builtin: ti.Builtin,
+	}
+	for k, v := range ti.Bools {
+		n.Bools[k] = v
+	}
+	for k, v := range ti.Numbers {
+		n.Numbers[k] = v
+	}
+	for k, v := range ti.Strings {
+		n.Strings[k] = v
+	}
+	return n
+}
+
+// parseSources parses the entries in terminfo source format,
+// see terminfo(5). Only the features used by builtinSource are
+// supported: `name`, `name#num`, `name=str`, `name@` and `use=`.
+func parseSources(src string) map[string]*Terminfo {
+	m := make(map[string]*Terminfo)
+	for _, block := range strings.Split(src, "\n\n") {
+		block = strings.TrimSpace(block)
+		if block == "" {
+			continue
+		}
+		fields := splitFields(block)
+		ti := &Terminfo{
+			Names:   strings.Split(fields[0], "|"),
+			Bools:   make(map[string]bool),
+			Numbers: make(map[string]int),
+			Strings: make(map[string]string),
+			Builtin: true,
+		}
+		ti.Name = ti.Names[0]
+		cancelled := make(map[string]bool)
+		for _, f := range fields[1:] {
+			switch {
+			case strings.HasPrefix(f, "use="):
+				// the capabilities declared before use= take precedence
+				if base, ok := m[f[4:]]; ok {
+					for k, v := range base.Bools {
+						if !cancelled[k] && !ti.Has(k) {
+							ti.Bools[k] = v
+						}
+					}
+					for k, v := range base.Numbers {
+						if !cancelled[k] && !ti.Has(k) {
+							ti.Numbers[k] = v
+						}
+					}
+					for k, v := range base.Strings {
+						if !cancelled[k] && !ti.Has(k) {
+							ti.Strings[k] = v
+						}
+					}
+				}
+			case strings.HasSuffix(f, "@"):
+				cancelled[f[:len(f)-1]] = true
+			case strings.Contains(f, "="):
+				i := strings.IndexByte(f, '=')
+				ti.Strings[f[:i]] = unescape(f[i+1:])
+			case strings.Contains(f, "#"):
+				i := strings.IndexByte(f, '#')
+				n, err := strconv.ParseInt(f[i+1:], 0, 64)
+				if err == nil {
+					ti.Numbers[f[:i]] = int(n)
+				}
+			default:
+				ti.Bools[f] = true
+			}
+		}
+		for _, name := range ti.Names[:max(len(ti.Names)-1, 1)] {
+			m[name] = ti
+		}
+	}
+	return m
+}
+
+// splitFields splits an entry by the unescaped commas.
+func splitFields(block string) (fields []string) {
+	var sb strings.Builder
+	for i := 0; i < len(block); i++ {
+		switch c := block[i]; c {
+		case '\\':
+			sb.WriteByte(c)
+			if i+1 < len(block) {
+				i++
+				sb.WriteByte(block[i])
+			}
+		case ',':
+			if f := strings.TrimSpace(sb.String()); f != "" {
+				fields = append(fields, f)
+			}
+			sb.Reset()
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	if f := strings.TrimSpace(sb.String()); f != "" {
+		fields = append(fields, f)
+	}
+	return
+}
+
+// unescape decodes the escapes in terminfo source format.
+func unescape(s string) string {
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '^' && i+1 < len(s):
+			i++
+			if s[i] == '?' {
+				sb.WriteByte(0x7f)
+			} else {
+				sb.WriteByte(s[i] & 0x1f)
+			}
+		case c == '\\' && i+1 < len(s):
+			i++
+			switch e := s[i]; e {
+			case 'E', 'e':
+				sb.WriteByte(0x1b)
+			case 'n', 'l':
+				sb.WriteByte('\n')
+			case 'r':
+				sb.WriteByte('\r')
+			case 't':
+				sb.WriteByte('\t')
+			case 'b':
+				sb.WriteByte('\b')
+			case 'f':
+				sb.WriteByte('\f')
+			case 's':
+				sb.WriteByte(' ')
+			case '0', '1', '2', '3':
+				n := 0
+				j := i
+				for ; j < len(s) && j < i+3 && s[j] >= '0' && s[j] <= '7'; j++ {
+					n = n*8 + int(s[j]-'0')
+				}
+				if n == 0 {
+					n = 0o200 // \0 means NUL which is encoded as \200
+				}
+				sb.WriteByte(byte(n))
+				i = j - 1
+			default:
+				sb.WriteByte(e)
+			}
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	return sb.String()
+}
+
+func inSlice(s string, list []string) bool {
+	for _, it := range list {
+		if it == s {
+			return true
+		}
+	}
+	return false
+}
+
+// builtinSource is in terminfo source format. The base entries
+// must be declared before the ones which `use=` them.
+const builtinSource = `
+dumb|80-column dumb tty,
+	am, cols#80, bel=^G, cr=\r, cud1=\n, ind=\n,
+
+ansi|ansi/pc-term compatible with color,
+	am, colors#8, cols#80, lines#24, pairs#64,
+	bel=^G, blink=\E[5m, bold=\E[1m, clear=\E[H\E[J, cr=\r,
+	cub=\E[%p1%dD, cub1=\E[D, cud=\E[%p1%dB, cud1=\E[B,
+	cuf=\E[%p1%dC, cuf1=\E[C, cup=\E[%i%p1%d;%p2%dH,
+	cuu=\E[%p1%dA, cuu1=\E[A, ed=\E[J, el=\E[K, el1=\E[1K,
+	home=\E[H, hpa=\E[%i%p1%dG, ind=\n, indn=\E[%p1%dS,
+	invis=\E[8m, kbs=^H, kcub1=\E[D, kcud1=\E[B, kcuf1=\E[C,
+	kcuu1=\E[A, khome=\E[H, kich1=\E[L, op=\E[39;49m, rev=\E[7m,
+	rin=\E[%p1%dT, rmso=\E[m, rmul=\E[m, setab=\E[4%p1%dm,
+	setaf=\E[3%p1%dm, sgr0=\E[0;10m, smso=\E[7m, smul=\E[4m,
+	vpa=\E[%i%p1%dd,
+
+vt100|vt100-am|DEC VT100 (w/advanced video),
+	am, xenl, cols#80, lines#24,
+	bel=^G, blink=\E[5m, bold=\E[1m, clear=\E[H\E[J, cr=\r,
+	csr=\E[%i%p1%d;%p2%dr, cub=\E[%p1%dD, cub1=^H,
+	cud=\E[%p1%dB, cud1=\n, cuf=\E[%p1%dC, cuf1=\E[C,
+	cup=\E[%i%p1%d;%p2%dH, cuu=\E[%p1%dA, cuu1=\E[A,
+	ed=\E[J, el=\E[K, el1=\E[1K, home=\E[H, ind=\n, kbs=^H,
+	kcub1=\EOD, kcud1=\EOB, kcuf1=\EOC, kcuu1=\EOA,
+	kf1=\EOP, kf2=\EOQ, kf3=\EOR, kf4=\EOS, rc=\E8, rev=\E[7m,
+	ri=\EM, rmkx=\E[?1l\E>, rmso=\E[m, rmul=\E[m, sc=\E7,
+	sgr0=\E[m\017, smkx=\E[?1h\E=, smso=\E[7m, smul=\E[4m,
+
+vt220|vt200|DEC VT220,
+	civis=\E[?25l, cnorm=\E[?25h, ech=\E[%p1%dX,
+	kdch1=\E[3~, kend=\E[4~, kf5=\E[17~, kf6=\E[18~,
+	kf7=\E[19~, kf8=\E[20~, kf9=\E[21~, kf10=\E[29~,
+	khome=\E[1~, kich1=\E[2~, knp=\E[6~, kpp=\E[5~,
+	use=vt100,
+
+linux|linux console,
+	am, bce, xenl, colors#8, pairs#64,
+	bel=^G, blink=\E[5m, bold=\E[1m, civis=\E[?25l\E[?1c,
+	clear=\E[H\E[J, cnorm=\E[?25h\E[?0c, cr=\r,
+	csr=\E[%i%p1%d;%p2%dr, cub=\E[%p1%dD, cub1=^H,
+	cud=\E[%p1%dB, cud1=\n, cuf=\E[%p1%dC, cuf1=\E[C,
+	cup=\E[%i%p1%d;%p2%dH, cuu=\E[%p1%dA, cuu1=\E[A,
+	dim=\E[2m, ed=\E[J, el=\E[K, el1=\E[1K, home=\E[H,
+	hpa=\E[%i%p1%dG, ind=\n, kbs=^?, kcub1=\E[D, kcud1=\E[B,
+	kcuf1=\E[C, kcuu1=\E[A, kdch1=\E[3~, kend=\E[4~,
+	kf1=\E[[A, kf2=\E[[B, kf3=\E[[C, kf4=\E[[D, kf5=\E[[E,
+	kf6=\E[17~, kf7=\E[18~, kf8=\E[19~, kf9=\E[20~,
+	kf10=\E[21~, kf11=\E[23~, kf12=\E[24~, khome=\E[1~,
+	kich1=\E[2~, knp=\E[6~, kpp=\E[5~, op=\E[39;49m, rc=\E8,
+	rev=\E[7m, ri=\EM, rmso=\E[27m, rmul=\E[24m, sc=\E7,
+	setab=\E[4%p1%dm, setaf=\E[3%p1%dm, sgr0=\E[m\017,
+	smso=\E[7m, smul=\E[4m, vpa=\E[%i%p1%dd,
+
+xterm|xterm-color|xterm-debian|xterm terminal emulator,
+	am, bce, km, xenl, colors#8, cols#80, lines#24, pairs#64,
+	bel=^G, blink=\E[5m, bold=\E[1m, civis=\E[?25l,
+	clear=\E[H\E[2J, cnorm=\E[?12l\E[?25h, cr=\r,
+	csr=\E[%i%p1%d;%p2%dr, cub=\E[%p1%dD, cub1=^H,
+	cud=\E[%p1%dB, cud1=\n, cuf=\E[%p1%dC, cuf1=\E[C,
+	cup=\E[%i%p1%d;%p2%dH, cuu=\E[%p1%dA, cuu1=\E[A,
+	cvvis=\E[?12;25h, dim=\E[2m, ech=\E[%p1%dX, ed=\E[J,
+	el=\E[K, el1=\E[1K, home=\E[H, hpa=\E[%i%p1%dG, ind=\n,
+	indn=\E[%p1%dS, invis=\E[8m, kbs=^?, kcub1=\EOD,
+	kcud1=\EOB, kcuf1=\EOC, kcuu1=\EOA, kdch1=\E[3~,
+	kend=\EOF, kf1=\EOP, kf2=\EOQ, kf3=\EOR, kf4=\EOS,
+	kf5=\E[15~, kf6=\E[17~, kf7=\E[18~, kf8=\E[19~,
+	kf9=\E[20~, kf10=\E[21~, kf11=\E[23~, kf12=\E[24~,
+	khome=\EOH, kich1=\E[2~, kmous=\E[<, knp=\E[6~,
+	kpp=\E[5~, op=\E[39;49m, rc=\E8, rev=\E[7m, ri=\EM,
+	rin=\E[%p1%dT, ritm=\E[23m, rmcup=\E[?1049l\E[23;0;0t,
+	rmkx=\E[?1l\E>, rmso=\E[27m, rmul=\E[24m, sc=\E7,
+	setab=\E[4%p1%dm, setaf=\E[3%p1%dm, sgr0=\E(B\E[m,
+	sitm=\E[3m, smcup=\E[?1049h\E[22;0;0t, smkx=\E[?1h\E=,
+	smso=\E[7m, smul=\E[4m, vpa=\E[%i%p1%dd,
+	Ms=\E]52;%p1%s;%p2%s\007, Se=\E[2 q, Ss=\E[%p1%d q,
+	XM=\E[?1006;1000%?%p1%{1}%=%th%el%;, rmxx=\E[29m, smxx=\E[9m,
+
+xterm-256color|xterm with 256 colors,
+	colors#256, pairs#65536,
+	setab=\E[%?%p1%{8}%<%t4%p1%d%e%p1%{16}%<%t10%p1%{8}%-%d%e48;5;%p1%d%;m,
+	setaf=\E[%?%p1%{8}%<%t3%p1%d%e%p1%{16}%<%t9%p1%{8}%-%d%e38;5;%p1%d%;m,
+	use=xterm,
+
+xterm-direct|xterm with direct-color indexing,
+	RGB, colors#16777216, pairs#65536,
+	setab=\E[%?%p1%{8}%<%t4%p1%d%e48;2;%p1%{65536}%/%d;%p1%{256}%/%{255}%&%d;%p1%{255}%&%d%;m,
+	setaf=\E[%?%p1%{8}%<%t3%p1%d%e38;2;%p1%{65536}%/%d;%p1%{256}%/%{255}%&%d;%p1%{255}%&%d%;m,
+	use=xterm,
+
+xterm-kitty|KovIdTTY,
+	Tc, Su, Smulx=\E[4:%p1%dm, Setulc=\E[58:2:%p1%{65536}%/%d:%p1%{256}%/%{255}%&%d:%p1%{255}%&%dm,
+	use=xterm-256color,
+
+xterm-ghostty|ghostty|Ghostty,
+	Tc, Su, Smulx=\E[4:%p1%dm,
+	use=xterm-256color,
+
+wezterm|Wez's terminal emulator,
+	Tc, Su, Smulx=\E[4:%p1%dm,
+	use=xterm-256color,
+
+alacritty|alacritty terminal emulator,
+	Tc, use=xterm-256color,
+
+screen|VT 100/ANSI X3.64 virtual terminal,
+	am, km, xenl, colors#8, cols#80, lines#24, pairs#64,
+	bel=^G, blink=\E[5m, bold=\E[1m, civis=\E[?25l,
+	clear=\E[H\E[J, cnorm=\E[34h\E[?25h, cr=\r,
+	csr=\E[%i%p1%d;%p2%dr, cub=\E[%p1%dD, cub1=^H,
+	cud=\E[%p1%dB, cud1=\n, cuf=\E[%p1%dC, cuf1=\E[C,
+	cup=\E[%i%p1%d;%p2%dH, cuu=\E[%p1%dA, cuu1=\EM,
+	cvvis=\E[34l, dim=\E[2m, ed=\E[J, el=\E[K, el1=\E[1K,
+	home=\E[H, hpa=\E[%i%p1%dG, ind=\n, indn=\E[%p1%dS,
+	kbs=^?, kcub1=\EOD, kcud1=\EOB, kcuf1=\EOC, kcuu1=\EOA,
+	kdch1=\E[3~, kend=\E[4~, kf1=\EOP, kf2=\EOQ, kf3=\EOR,
+	kf4=\EOS, kf5=\E[15~, kf6=\E[17~, kf7=\E[18~, kf8=\E[19~,
+	kf9=\E[20~, kf10=\E[21~, kf11=\E[23~, kf12=\E[24~,
+	khome=\E[1~, kich1=\E[2~, kmous=\E[M, knp=\E[6~,
+	kpp=\E[5~, op=\E[39;49m, rc=\E8, rev=\E[7m, ri=\EM,
+	rin=\E[%p1%dT, rmcup=\E[?1049l, rmkx=\E[?1l\E>,
+	rmso=\E[23m, rmul=\E[24m, sc=\E7, setab=\E[4%p1%dm,
+	setaf=\E[3%p1%dm, sgr0=\E[m\017, smcup=\E[?1049h,
+	smkx=\E[?1h\E=, smso=\E[3m, smul=\E[4m, vpa=\E[%i%p1%dd,
+
+screen-256color|GNU Screen with 256 colors,
+	colors#256, pairs#65536,
+	setab=\E[%?%p1%{8}%<%t4%p1%d%e%p1%{16}%<%t10%p1%{8}%-%d%e48;5;%p1%d%;m,
+	setaf=\E[%?%p1%{8}%<%t3%p1%d%e%p1%{16}%<%t9%p1%{8}%-%d%e38;5;%p1%d%;m,
+	use=screen,
+
+tmux|tmux terminal multiplexer,
+	ritm=\E[23m, rmso=\E[27m, sitm=\E[3m, smso=\E[7m,
+	use=screen,
+
+tmux-256color|tmux with 256 colors,
+	ritm=\E[23m, rmso=\E[27m, sitm=\E[3m, smso=\E[7m,
+	use=screen-256color,
+`
@@ -0,0 +1,207 @@
+package terminfo
+
+import (
+	"encoding/binary"
+	"errors"
+	"strings"
+)
+
+const (
+	magicLegacy   = 0o432  // numbers are 16-bit
+	magicExtended = 0o1036 // numbers are 32-bit, ncurses 6.1+
+)
+
+var errBadFormat = errors.New("terminfo: bad compiled entry")
+
+// decoder walks a compiled terminfo entry.
+type decoder struct {
+	data []byte
+	pos  int
+	err  error
+}
+
+func (d *decoder) short() int {
+	if d.err != nil {
+		return 0
+	}
+	if d.pos+2 > len(d.data) {
+		d.err = errBadFormat
+		return 0
+	}
+	v := int16(binary.LittleEndian.Uint16(d.data[d.pos:]))
+	d.pos += 2
+	return int(v)
+}
+
+func (d *decoder) int32() int {
+	if d.err != nil {
+		return 0
+	}
+	if d.pos+4 > len(d.data) {
+		d.err = errBadFormat
+		return 0
+	}
+	v := int32(binary.LittleEndian.Uint32(d.data[d.pos:]))
+	d.pos += 4
+	return int(v)
+}
+
+func (d *decoder) bytes(n int) []byte {
+	if d.err != nil {
+		return nil
+	}
+	if n < 0 || d.pos+n > len(d.data) {
+		d.err = errBadFormat
+		return nil
+	}
+	b := d.data[d.pos : d.pos+n]
+	d.pos += n
+	return b
+}
+
+// align skips the padding byte to an even offset.
+func (d *decoder) align() {
+	if d.pos%2 != 0 {
+		d.pos++
+	}
+}
+
+// decode parses a compiled terminfo entry, see term(5).
+func decode(data []byte) (ti *Terminfo, err error) {
+	d := &decoder{data: data}
+
+	magic := d.short()
+	var num func() int
+	switch magic {
+	case magicLegacy:
+		num = d.short
+	case magicExtended:
+		num = d.int32
+	default:
+		return nil, errBadFormat
+	}
+
+	namesSize, boolCount, numCount, strCount, tableSize := d.short(), d.short(), d.short(), d.short(), d.short()
+	if d.err != nil || namesSize < 0 || boolCount < 0 || numCount < 0 || strCount < 0 || tableSize < 0 {
+		return nil, errBadFormat
+	}
+
+	ti = &Terminfo{
+		Bools:   make(map[string]bool),
+		Numbers: make(map[string]int),
+		Strings: make(map[string]string),
+	}
+
+	names := strings.TrimRight(string(d.bytes(namesSize)), "\x00")
+	ti.Names = strings.Split(names, "|")
+	ti.Name = ti.Names[0]
+
+	for i, b := range d.bytes(boolCount) {
+		if b == 1 && i < len(boolNames) {
+			ti.Bools[boolNames[i]] = true
+		}
+	}
+	d.align()
+
+	for i := range numCount {
+		if v := num(); v >= 0 && i < len(numNames) {
+			ti.Numbers[numNames[i]] = v
+		}
+	}
+
+	offsets := make([]int, strCount)
+	for i := range offsets {
+		offsets[i] = d.short()
+	}
+	table := d.bytes(tableSize)
+	if d.err != nil {
+		return nil, d.err
+	}
+	for i, off := range offsets {
+		if off >= 0 && i < len(strNames) {
+			ti.Strings[strNames[i]] = cstring(table, off)
+		}
+	}
+
+	// the extended section is optional
+	d.align()
+	if d.pos < len(d.data) {
+		decodeExtended(d, num, ti)
+	}
+	return ti, nil
+}
+
+// decodeExtended parses the ncurses user-defined capabilities
+// section, which holds "Tc", "RGB", "Smulx", "Setulc", ...
+//
+// Any error in this section will be ignored silently, the
+// standard capabilities are kept.
+func decodeExtended(d *decoder, num func() int, ti *Terminfo) {
+	boolCount, numCount, strCount, _, tableSize := d.short(), d.short(), d.short(), d.short(), d.short()
+	if d.err != nil || boolCount < 0 || numCount < 0 || strCount < 0 || tableSize < 0 {
+		return
+	}
+
+	bools := d.bytes(boolCount)
+	d.align()
+	nums := make([]int, numCount)
+	for i := range nums {
+		nums[i] = num()
+	}
+	strOffsets := make([]int, strCount)
+	for i := range strOffsets {
+		strOffsets[i] = d.short()
+	}
+	nameOffsets := make([]int, boolCount+numCount+strCount)
+	for i := range nameOffsets {
+		nameOffsets[i] = d.short()
+	}
+	table := d.bytes(tableSize)
+	if d.err != nil {
+		return
+	}
+
+	// the names area follows the last string value
+	namesBase := 0
+	for _, off := range strOffsets {
+		if off >= 0 {
+			if end := off + len(cstring(table, off)) + 1; end > namesBase {
+				namesBase = end
+			}
+		}
+	}
+	name := func(i int) string {
+		if i >= len(nameOffsets) || nameOffsets[i] < 0 {
+			return ""
+		}
+		return cstring(table, namesBase+nameOffsets[i])
+	}
+
+	for i, b := range bools {
+		if n := name(i); n != "" && b == 1 {
+			ti.Bools[n] = true
+		}
+	}
+	for i, v := range nums {
+		if n := name(boolCount + i); n != "" && v >= 0 {
+			ti.Numbers[n] = v
+		}
+	}
+	for i, off := range strOffsets {
+		if n := name(boolCount + numCount + i); n != "" && off >= 0 {
+			ti.Strings[n] = cstring(table, off)
+		}
+	}
+}
+
+// cstring returns the NUL-terminated string at off.
+func cstring(table []byte, off int) string {
+	if off < 0 || off >= len(table) {
+		return ""
+	}
+	end := off
+	for end < len(table) && table[end] != 0 {
+		end++
+	}
+	return string(table[off:end])
+}
@@ -0,0 +1,245 @@
+// Package terminfo provides a small reader for the terminal
+// capability database.
+//
+// A [Terminfo] entry is loaded from the compiled terminfo files
+// under `$TERMINFO`, `~/.terminfo`, `$TERMINFO_DIRS`, `/etc/terminfo`,
+// `/lib/terminfo`, `/usr/share/terminfo` and so on. If nothing can
+// be found, a small embedded table (xterm, xterm-256color, screen,
+// tmux, linux, vt100, ansi, dumb) will be used as the fallback.
+//
+// For example:
+//
+//	ti, err := terminfo.Load("xterm-256color")
+//	if err == nil {
+//		fmt.Print(ti.Exec("cup", 3, 7)) // move cursor to row 3, col 7 (0-based)
+//	}
+//
+// [Current] returns the cached entry of `$TERM`.
+package terminfo
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ErrNotFound will be returned by [Load] if no compiled entry
+// and no builtin entry could be found for a terminal name.
+var ErrNotFound = errors.New("terminfo: entry not found")
+
+// Terminfo holds the capabilities of a terminal type.
+//
+// The keys of Bools, Numbers and Strings are the short
+// capability names, such as "am", "colors", "cup", ...
+// The extended (user-defined) capabilities like "Tc", "RGB",
+// "Smulx" are merged into the same maps.
+type Terminfo struct {
+	Name    string   // the primary name, eg "xterm-256color"
+	Names   []string // all names and the description at last
+	Bools   map[string]bool
+	Numbers map[string]int
+	Strings map[string]string
+	Builtin bool // true if the entry comes from the embedded table
+}
+
+// Load finds a terminal entry by its name.
+//
+// The compiled terminfo database will be searched firstly,
+// and the embedded table is the fallback.
+func Load(name string) (ti *Terminfo, err error) {
+	if name == "" {
+		return nil, ErrNotFound
+	}
+	for _, dir := range searchDirs() {
+		if ti, err = loadFrom(dir, name); err == nil {
+			return
+		}
+	}
+	if ti = Builtin(name); ti != nil {
+		return ti, nil
+	}
+	return nil, ErrNotFound
+}
+
+// LoadFromEnv loads the terminal entry named by `$TERM`.
+func LoadFromEnv() (*Terminfo, error) {
+	return Load(os.Getenv("TERM"))
+}
+
+// Current returns the cached entry of `$TERM`, or nil if
+// `$TERM` is empty or unknown.
+//
+// Call [Reset] to discard the cached entry, for example,
+// after `$TERM` has been changed. Both are safe for concurrent
+// use.
+func Current() *Terminfo {
+	currentMu.Lock()
+	defer currentMu.Unlock()
+	if !currentLoaded {
+		current, _ = LoadFromEnv()
+		currentLoaded = true
+	}
+	return current
+}
+
+// Reset discards the cached entry of [Current].
+func Reset() {
+	currentMu.Lock()
+	defer currentMu.Unlock()
+	current, currentLoaded = nil, false
+}
+
+var (
+	currentMu     sync.Mutex
+	currentLoaded bool
+	current       *Terminfo
+)
+
+// Has reports whether a capability is present, in any kind.
+func (ti *Terminfo) Has(capName string) bool {
+	if ti == nil {
+		return false
+	}
+	if _, ok := ti.Strings[capName]; ok {
+		return true
+	}
+	if _, ok := ti.Numbers[capName]; ok {
+		return true
+	}
+	return ti.Bools[capName]
+}
+
+// Bool returns the value of a boolean capability.
+func (ti *Terminfo) Bool(capName string) bool {
+	if ti == nil {
+		return false
+	}
+	return ti.Bools[capName]
+}
+
+// Num returns the value of a numeric capability, or -1 if absent.
+func (ti *Terminfo) Num(capName string) int {
+	if ti == nil {
+		return -1
+	}
+	if n, ok := ti.Numbers[capName]; ok {
+		return n
+	}
+	return -1
+}
+
+// Str returns the raw value of a string capability, the
+// parameters and padding will not be expanded.
+func (ti *Terminfo) Str(capName string) (str string, ok bool) {
+	if ti == nil {
+		return
+	}
+	str, ok = ti.Strings[capName]
+	return
+}
+
+// Exec expands a string capability with the given parameters,
+// and strips the padding specs (`$<5>`) since we never delay.
+//
+// An empty string will be returned if the capability is absent.
+func (ti *Terminfo) Exec(capName string, params ...any) string {
+	str, _ := ti.Parm(capName, params...)
+	return str
+}
+
+// Parm expands a string capability with the given parameters.
+// The ok result is false if the capability is absent.
+func (ti *Terminfo) Parm(capName string, params ...any) (str string, ok bool) {
+	var raw string
+	if raw, ok = ti.Str(capName); !ok {
+		return
+	}
+	str = stripPadding(Tparm(raw, params...))
+	return
+}
+
+// Colors returns the count of colors the terminal supported.
+//
+// 1<<24 will be returned for the true-color terminals which
+// declare `Tc` or `RGB` extended capability. 0 means that it
+// is monochrome.
+func (ti *Terminfo) Colors() int {
+	if ti == nil {
+		return 0
+	}
+	if ti.Bools["Tc"] || ti.Bools["RGB"] {
+		return 1 << 24
+	}
+	if _, ok := ti.Strings["RGB"]; ok {
+		return 1 << 24
+	}
+	if n := ti.Num("colors"); n > 0 {
+		return n
+	}
+	return 0
+}
+
+func searchDirs() (dirs []string) {
+	if d := os.Getenv("TERMINFO"); d != "" {
+		dirs = append(dirs, d)
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		dirs = append(dirs, filepath.Join(home, ".terminfo"))
+	}
+	if list := os.Getenv("TERMINFO_DIRS"); list != "" {
+		for _, d := range filepath.SplitList(list) {
+			if d == "" {
+				d = "/usr/share/terminfo" // an empty item means the system default
+			}
+			dirs = append(dirs, d)
+		}
+	}
+	dirs = append(dirs,
+		"/etc/terminfo",
+		"/lib/terminfo",
+		"/usr/share/terminfo",
+		"/usr/lib/terminfo",
+		"/usr/share/lib/terminfo",
+		"/usr/local/share/terminfo",
+		"/opt/homebrew/share/terminfo",
+		"/boot/system/data/terminfo", // haiku
+	)
+	return
+}
+
+func loadFrom(dir, name string) (ti *Terminfo, err error) {
+	// Linux: dir/x/xterm; macOS: dir/78/xterm
+	for _, sub := range []string{name[0:1], strconv.FormatInt(int64(name[0]), 16)} {
+		var data []byte
+		if data, err = os.ReadFile(filepath.Join(dir, sub, name)); err == nil {
+			return decode(data)
+		}
+	}
+	return nil, ErrNotFound
+}
+
+// stripPadding removes the `$<...>` padding specs from a
+// expanded capability string.
+func stripPadding(s string) string {
+	if !strings.Contains(s, "$<") {
+		return s
+	}
+	var sb strings.Builder
+	for {
+		i := strings.Index(s, "$<")
+		if i < 0 {
+			break
+		}
+		j := strings.IndexByte(s[i:], '>')
+		if j < 0 {
+			break
+		}
+		sb.WriteString(s[:i])
+		s = s[i+j+1:]
+	}
+	sb.WriteString(s)
+	return sb.String()
+}
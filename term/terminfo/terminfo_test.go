@@ -0,0 +1,163 @@
+package terminfo
+
+import (
+	"os"
+	"sync"
+	"testing"
+)
+
+func TestTparm(t *testing.T) {
+	tests := []struct {
+		str    string
+		params []any
+		want   string
+	}{
+		{"\x1b[%i%p1%d;%p2%dH", []any{2, 3}, "\x1b[3;4H"},
+		{"\x1b[%p1%dA", []any{5}, "\x1b[5A"},
+		{"\x1b[%?%p1%{8}%<%t3%p1%d%e%p1%{16}%<%t9%p1%{8}%-%d%e38;5;%p1%d%;m", []any{1}, "\x1b[31m"},
+		{"\x1b[%?%p1%{8}%<%t3%p1%d%e%p1%{16}%<%t9%p1%{8}%-%d%e38;5;%p1%d%;m", []any{12}, "\x1b[94m"},
+		{"\x1b[%?%p1%{8}%<%t3%p1%d%e%p1%{16}%<%t9%p1%{8}%-%d%e38;5;%p1%d%;m", []any{200}, "\x1b[38;5;200m"},
+		{"\x1b[38;2;%p1%d;%p2%d;%p3%dm", []any{1, 2, 3}, "\x1b[38;2;1;2;3m"},
+		{"%p1%02d|%p1%x|%p1%c", []any{65}, "65|41|A"},
+		{"%p1%s-%p1%l%d", []any{"abc"}, "abc-3"},
+		{"%p1%Pa%ga%ga%+%d", []any{21}, "42"},
+		{"%'a'%c%%", nil, "a%"},
+		{"no params", nil, "no params"},
+	}
+	for i, tc := range tests {
+		if got := Tparm(tc.str, tc.params...); got != tc.want {
+			t.Errorf("%d. Tparm(%q, %v) = %q, want %q", i, tc.str, tc.params, got, tc.want)
+		}
+	}
+}
+
+func TestBuiltin(t *testing.T) {
+	for _, name := range BuiltinNames() {
+		ti := Builtin(name)
+		if ti == nil || !ti.Builtin {
+			t.Fatalf("builtin entry %q not found", name)
+		}
+	}
+
+	ti := Builtin("xterm-256color")
+	if got := ti.Colors(); got != 256 {
+		t.Errorf("xterm-256color colors = %d, want 256", got)
+	}
+	if got := ti.Exec("cup", 2, 3); got != "\x1b[3;4H" {
+		t.Errorf("xterm-256color cup = %q", got)
+	}
+	if got := Builtin("xterm-direct").Colors(); got != 1<<24 {
+		t.Errorf("xterm-direct colors = %d, want 1<<24", got)
+	}
+
+	// vt220 inherits from vt100 via use=
+	vt220 := Builtin("vt220")
+	if !vt220.Has("cup") || vt220.Colors() != 0 {
+		t.Errorf("vt220: unexpected entry %+v", vt220)
+	}
+	if got := vt220.Exec("cuu1"); got != "\x1b[A" {
+		t.Errorf("vt220 cuu1 = %q", got)
+	}
+
+	dumb := Builtin("dumb")
+	if dumb.Has("cup") || dumb.Has("cuu1") || dumb.Exec("cup", 1, 1) != "" {
+		t.Errorf("dumb terminal shouldn't move the cursor")
+	}
+	if Builtin("no-such-terminal") != nil {
+		t.Errorf("want nil for unknown terminal")
+	}
+}
+
+func TestLoadCompiled(t *testing.T) {
+	t.Setenv("TERMINFO", "")
+	t.Setenv("TERMINFO_DIRS", "")
+
+	ti, err := Load("xterm-256color")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ti.Builtin {
+		t.Skip("no compiled terminfo database found")
+	}
+	if ti.Name != "xterm-256color" {
+		t.Errorf("name = %q", ti.Name)
+	}
+	if got := ti.Colors(); got < 256 {
+		t.Errorf("colors = %d, want >= 256", got)
+	}
+	if !ti.Bool("am") || ti.Num("cols") != 80 {
+		t.Errorf("unexpected am/cols: %v, %d", ti.Bool("am"), ti.Num("cols"))
+	}
+	if got := ti.Exec("cup", 0, 0); got != "\x1b[1;1H" {
+		t.Errorf("cup = %q", got)
+	}
+	if got := ti.Exec("setaf", 9); got != "\x1b[91m" {
+		t.Errorf("setaf = %q", got)
+	}
+}
+
+func TestDecodeBad(t *testing.T) {
+	for _, data := range [][]byte{
+		nil,
+		{0x1a, 0x01},
+		{0x00, 0x00, 0x01, 0x00},
+		{0x1a, 0x01, 0xff, 0x7f, 0, 0, 0, 0, 0, 0, 0, 0},
+	} {
+		if _, err := decode(data); err == nil {
+			t.Errorf("decode(%v): want error", data)
+		}
+	}
+}
+
+func TestLoadFromDir(t *testing.T) {
+	data, err := os.ReadFile("/lib/terminfo/v/vt100")
+	if err != nil {
+		t.Skip("no compiled vt100 entry found")
+	}
+
+	dir := t.TempDir()
+	if err = os.MkdirAll(dir+"/76", 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err = os.WriteFile(dir+"/76/vt100", data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("TERMINFO", dir)
+
+	ti, err := Load("vt100")
+	if err != nil || ti.Builtin {
+		t.Fatalf("load from macOS-style dir failed: %v", err)
+	}
+	if got := ti.Exec("cup", 4, 9); got != "\x1b[5;10H" {
+		t.Errorf("cup = %q", got)
+	}
+}
+
+func TestStripPadding(t *testing.T) {
+	if got := stripPadding("\x1b[A$<2>x$<5*/>"); got != "\x1b[Ax" {
+		t.Errorf("stripPadding = %q", got)
+	}
+}
+
+func TestCurrentReset(t *testing.T) {
+	t.Setenv("TERM", "xterm-256color")
+	Reset()
+	defer Reset()
+
+	var wg sync.WaitGroup
+	for i := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 100 {
+				if i%2 == 0 {
+					Reset()
+				} else if ti := Current(); ti == nil || ti.Colors() != 256 {
+					t.Errorf("Current() = %v", ti)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,278 @@
+package terminfo
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Tparm evaluates a parameterized capability string, see
+// the "Parameterized Strings" section of terminfo(5).
+//
+// The params can be int (or any integer type), bool and string.
+// Up to 9 params are used, the extra ones are ignored.
+//
+// For example:
+//
+//	Tparm(`\E[%i%p1%d;%p2%dH`, 2, 3) // => "\x1b[3;4H"
+//
+// The padding specs (`$<5>`) are kept as is.
+func Tparm(s string, params ...any) string {
+	if !strings.ContainsRune(s, '%') {
+		return s
+	}
+
+	var p [9]value
+	for i, v := range params {
+		if i >= len(p) {
+			break
+		}
+		p[i] = toValue(v)
+	}
+
+	var (
+		sb      strings.Builder
+		stack   []value
+		dynVars [26]value
+		// %PA .. %PZ should be shared between calls in theory,
+		// but nobody uses them in practice, so we keep them
+		// local to make Tparm safe for concurrent use.
+		staticVars [26]value
+	)
+	push := func(v value) { stack = append(stack, v) }
+	pop := func() (v value) {
+		if l := len(stack); l > 0 {
+			v, stack = stack[l-1], stack[:l-1]
+		}
+		return
+	}
+	popInt := func() int { return pop().int() }
+	bin := func(fn func(a, b int) int) {
+		b, a := popInt(), popInt()
+		push(intValue(fn(a, b)))
+	}
+
+	for i := 0; i < len(s); i++ {
+		ch := s[i]
+		if ch != '%' || i+1 >= len(s) {
+			sb.WriteByte(ch)
+			continue
+		}
+		i++
+		ch = s[i]
+		switch ch {
+		case '%':
+			sb.WriteByte('%')
+		case 'c':
+			sb.WriteByte(byte(popInt()))
+		case 's':
+			sb.WriteString(pop().str())
+		case 'd', 'o', 'x', 'X', ':', '.', '#', ' ', '0', '1', '2', '3', '4', '5', '6', '7', '8', '9':
+			// %[[:]flags][width[.precision]][doxXs]
+			j := i
+			if s[j] == ':' {
+				j++
+			}
+			for j < len(s) && strings.IndexByte("-+# 0123456789.", s[j]) >= 0 {
+				j++
+			}
+			if j >= len(s) {
+				i = j
+				break
+			}
+			spec := strings.TrimPrefix(s[i:j], ":")
+			verb := s[j]
+			i = j
+			switch verb {
+			case 'd', 'o', 'x', 'X':
+				fmt.Fprintf(&sb, "%"+spec+string(verb), popInt())
+			case 's':
+				fmt.Fprintf(&sb, "%"+spec+"s", pop().str())
+			}
+		case 'p':
+			if i+1 < len(s) {
+				i++
+				if n := int(s[i] - '1'); n >= 0 && n < len(p) {
+					push(p[n])
+				}
+			}
+		case 'P':
+			if i+1 < len(s) {
+				i++
+				if v := s[i]; v >= 'a' && v <= 'z' {
+					dynVars[v-'a'] = pop()
+				} else if v >= 'A' && v <= 'Z' {
+					staticVars[v-'A'] = pop()
+				}
+			}
+		case 'g':
+			if i+1 < len(s) {
+				i++
+				if v := s[i]; v >= 'a' && v <= 'z' {
+					push(dynVars[v-'a'])
+				} else if v >= 'A' && v <= 'Z' {
+					push(staticVars[v-'A'])
+				}
+			}
+		case '\'':
+			// %'c' char constant
+			if i+2 < len(s) {
+				push(intValue(int(s[i+1])))
+				i += 2
+			}
+		case '{':
+			// %{nn} integer constant
+			j := strings.IndexByte(s[i:], '}')
+			if j < 0 {
+				i = len(s)
+				break
+			}
+			n, _ := strconv.Atoi(s[i+1 : i+j])
+			push(intValue(n))
+			i += j
+		case 'l':
+			push(intValue(len(pop().str())))
+		case '+':
+			bin(func(a, b int) int { return a + b })
+		case '-':
+			bin(func(a, b int) int { return a - b })
+		case '*':
+			bin(func(a, b int) int { return a * b })
+		case '/':
+			bin(func(a, b int) int {
+				if b == 0 {
+					return 0
+				}
+				return a / b
+			})
+		case 'm':
+			bin(func(a, b int) int {
+				if b == 0 {
+					return 0
+				}
+				return a % b
+			})
+		case '&':
+			bin(func(a, b int) int { return a & b })
+		case '|':
+			bin(func(a, b int) int { return a | b })
+		case '^':
+			bin(func(a, b int) int { return a ^ b })
+		case '=':
+			bin(func(a, b int) int { return b2i(a == b) })
+		case '>':
+			bin(func(a, b int) int { return b2i(a > b) })
+		case '<':
+			bin(func(a, b int) int { return b2i(a < b) })
+		case 'A':
+			bin(func(a, b int) int { return b2i(a != 0 && b != 0) })
+		case 'O':
+			bin(func(a, b int) int { return b2i(a != 0 || b != 0) })
+		case '!':
+			push(intValue(b2i(popInt() == 0)))
+		case '~':
+			push(intValue(^popInt()))
+		case 'i':
+			p[0], p[1] = intValue(p[0].int()+1), intValue(p[1].int()+1)
+		case '?', ';':
+			// if-then-else markers, nothing to do
+		case 't':
+			if popInt() == 0 {
+				i = skipBranch(s, i+1, true) - 1
+			}
+		case 'e':
+			// the then-part has been done, skip the else-part
+			i = skipBranch(s, i+1, false) - 1
+		}
+	}
+	return sb.String()
+}
+
+// skipBranch finds the position after the matching `%e` (if
+// elseAllowed) or `%;` from pos, nested `%?` are counted.
+func skipBranch(s string, pos int, elseAllowed bool) int {
+	level := 0
+	for i := pos; i < len(s)-1; i++ {
+		if s[i] != '%' {
+			continue
+		}
+		i++
+		switch s[i] {
+		case '?':
+			level++
+		case ';':
+			if level == 0 {
+				return i + 1
+			}
+			level--
+		case 'e':
+			if level == 0 && elseAllowed {
+				return i + 1
+			}
+		}
+	}
+	return len(s)
+}
+
+type value struct {
+	s     string
+	n     int
+	isStr bool
+}
+
+func intValue(n int) value { return value{n: n} }
+
+func (v value) int() int {
+	if v.isStr {
+		n, _ := strconv.Atoi(v.s)
+		return n
+	}
+	return v.n
+}
+
+func (v value) str() string {
+	if v.isStr {
+		return v.s
+	}
+	return strconv.Itoa(v.n)
+}
+
+func toValue(v any) value {
+	switch z := v.(type) {
+	case string:
+		return value{s: z, isStr: true}
+	case []byte:
+		return value{s: string(z), isStr: true}
+	case bool:
+		return intValue(b2i(z))
+	case int:
+		return intValue(z)
+	case int8:
+		return intValue(int(z))
+	case int16:
+		return intValue(int(z))
+	case int32:
+		return intValue(int(z))
+	case int64:
+		return intValue(int(z))
+	case uint:
+		return intValue(int(z))
+	case uint8:
+		return intValue(int(z))
+	case uint16:
+		return intValue(int(z))
+	case uint32:
+		return intValue(int(z))
+	case uint64:
+		return intValue(int(z))
+	default:
+		return value{s: fmt.Sprint(z), isStr: true}
+	}
+}
+
+func b2i(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
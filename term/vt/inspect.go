@@ -0,0 +1,311 @@
+package vt
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/hedzr/is/term/color"
+)
+
+// Size returns the columns and the rows of the screen.
+func (t *Terminal) Size() (cols, rows int) { return t.cols, t.rows }
+
+// Cursor returns the cursor position, 0-based.
+func (t *Terminal) Cursor() (x, y int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.x, t.y
+}
+
+// CursorVisible reports whether the cursor is shown.
+func (t *Terminal) CursorVisible() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return !t.hidden
+}
+
+// AltScreen reports whether the alternate screen is active.
+func (t *Terminal) AltScreen() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.alt
+}
+
+// Title returns the title set by OSC 0 or OSC 2.
+func (t *Terminal) Title() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.title
+}
+
+// Cell returns the cell at column x of row y. The cell following
+// a wide character has Ch -1.
+func (t *Terminal) Cell(x, y int) color.Cell {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if x < 0 || x >= t.cols || y < 0 || y >= t.rows {
+		return color.Cell{}
+	}
+	return t.screen[y][x]
+}
+
+// Row returns the text of row y, without the trailing spaces.
+func (t *Terminal) Row(y int) string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if y < 0 || y >= t.rows {
+		return ""
+	}
+	return lineText(t.screen[y])
+}
+
+// Rows returns the texts of all rows.
+func (t *Terminal) Rows() []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return linesText(t.screen)
+}
+
+// History returns the texts of the lines scrolled off the top of
+// the main screen, the oldest first.
+func (t *Terminal) History() []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return linesText(t.history)
+}
+
+// String returns the text of the screen, without the trailing
+// blank rows.
+func (t *Terminal) String() string {
+	rows := t.Rows()
+	for len(rows) > 0 && rows[len(rows)-1] == "" {
+		rows = rows[:len(rows)-1]
+	}
+	return strings.Join(rows, "\n")
+}
+
+// Find returns the position of the first occurrence of text on
+// the screen.
+func (t *Terminal) Find(text string) (x, y int, ok bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for y, line := range t.screen {
+		if x = findCells(line, text); x >= 0 {
+			return x, y, true
+		}
+	}
+	return -1, -1, false
+}
+
+// Snapshot returns a dump of the screen for the golden tests
+// and the failure messages. Each row is followed by its styled
+// runs, the trailing blank rows are omitted:
+//
+//	0|    0. Hello, World! bold
+//	 |       ^^^^^^^^^^^^^ bold
+//	 |                     ^^^^ fg=red bg=#102030 italic
+//	cursor 0,1
+func (t *Terminal) Snapshot() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	last := t.y
+	for y := range t.screen {
+		if !blankLine(t.screen[y]) {
+			last = max(last, y)
+		}
+	}
+	var sb strings.Builder
+	pad := len(itoa(last))
+	for y, line := range t.screen[:last+1] {
+		num := itoa(y)
+		_, _ = sb.WriteString(strings.Repeat(" ", pad-len(num)) + num + "|" + lineText(line) + "\n")
+		for _, r := range styledRuns(line) {
+			_, _ = sb.WriteString(strings.Repeat(" ", pad) + "|" + strings.Repeat(" ", r.from) +
+				strings.Repeat("^", r.to-r.from) + " " + describe(line[r.from]) + "\n")
+		}
+	}
+	_, _ = sb.WriteString("cursor " + itoa(t.x) + "," + itoa(t.y))
+	if t.hidden {
+		_, _ = sb.WriteString(" hidden")
+	}
+	return sb.String()
+}
+
+// ExpectRow fails tb if row y doesn't read want (without the
+// trailing spaces).
+func (t *Terminal) ExpectRow(tb testing.TB, y int, want string) {
+	tb.Helper()
+	if got := t.Row(y); got != want {
+		tb.Errorf("row %d reads %q, want %q\n%s", y, got, want, t.Snapshot())
+	}
+}
+
+// ExpectStyle fails tb if text isn't found in row y, or any cell
+// of it isn't in the colors and the attributes of want. The Ch of
+// want is ignored:
+//
+//	term.ExpectStyle(t, 3, "failed", color.Cell{Fg: color.FgRed})
+func (t *Terminal) ExpectStyle(tb testing.TB, y int, text string, want color.Cell) {
+	tb.Helper()
+	var got []color.Cell
+	t.mu.Lock()
+	if y >= 0 && y < t.rows {
+		if x := findCells(t.screen[y], text); x >= 0 {
+			got = t.screen[y][x : x+color.StringWidth(text)]
+		}
+	}
+	t.mu.Unlock()
+	if got == nil {
+		tb.Errorf("row %d: %q not found\n%s", y, text, t.Snapshot())
+		return
+	}
+	for _, c := range got {
+		if !sameStyle(c, want) {
+			tb.Errorf("row %d: %q is %s, want %s\n%s", y, text, describe(c), describe(want), t.Snapshot())
+			return
+		}
+	}
+}
+
+// ExpectCursor fails tb if the cursor isn't at column x of row y.
+func (t *Terminal) ExpectCursor(tb testing.TB, x, y int) {
+	tb.Helper()
+	if gx, gy := t.Cursor(); gx != x || gy != y {
+		tb.Errorf("cursor is at %d,%d, want %d,%d\n%s", gx, gy, x, y, t.Snapshot())
+	}
+}
+
+func lineText(line []color.Cell) string {
+	var sb strings.Builder
+	for _, c := range line {
+		switch c.Ch {
+		case wideTail:
+		case 0:
+			_ = sb.WriteByte(' ')
+		default:
+			_, _ = sb.WriteRune(c.Ch)
+		}
+	}
+	return strings.TrimRight(sb.String(), " ")
+}
+
+func linesText(lines [][]color.Cell) (ret []string) {
+	for _, line := range lines {
+		ret = append(ret, lineText(line))
+	}
+	return
+}
+
+func blankLine(line []color.Cell) bool {
+	for _, c := range line {
+		if (c.Ch != 0 && c.Ch != ' ') || !sameStyle(c, color.Cell{}) {
+			return false
+		}
+	}
+	return true
+}
+
+// findCells returns the column of text in line, or -1.
+func findCells(line []color.Cell, text string) int {
+	var sb strings.Builder
+	var cols []int // the column of each byte of sb
+	for x, c := range line {
+		ch := c.Ch
+		switch ch {
+		case wideTail:
+			continue
+		case 0:
+			ch = ' '
+		}
+		n, _ := sb.WriteRune(ch)
+		for range n {
+			cols = append(cols, x)
+		}
+	}
+	if i := strings.Index(sb.String(), text); i >= 0 && text != "" {
+		return cols[i]
+	}
+	return -1
+}
+
+type run struct{ from, to int }
+
+// styledRuns returns the runs of the cells in the same style
+// other than the default one.
+func styledRuns(line []color.Cell) (runs []run) {
+	for x := 0; x < len(line); {
+		end := x + 1
+		for end < len(line) && sameStyle(line[end], line[x]) {
+			end++
+		}
+		if !sameStyle(line[x], color.Cell{}) {
+			runs = append(runs, run{x, end})
+		}
+		x = end
+	}
+	return
+}
+
+func sameStyle(a, b color.Cell) bool {
+	return a.Attrs == b.Attrs && colorName(a.Fg) == colorName(b.Fg) && colorName(a.Bg) == colorName(b.Bg)
+}
+
+// describe returns the style of a cell, such as "fg=red bold".
+func describe(c color.Cell) string {
+	var parts []string
+	if s := colorName(c.Fg); s != "" {
+		parts = append(parts, "fg="+s)
+	}
+	if s := colorName(c.Bg); s != "" {
+		parts = append(parts, "bg="+s)
+	}
+	for i, name := range attrNames {
+		if c.Attrs&(1<<i) != 0 {
+			parts = append(parts, name)
+		}
+	}
+	if len(parts) == 0 {
+		return "default"
+	}
+	return strings.Join(parts, " ")
+}
+
+// attrNames are the names of the [color.Attr] bits, in bit order.
+var attrNames = [...]string{"bold", "dim", "italic", "underline", "blink", "reverse", "hidden", "strike", "overline", "framed"}
+
+// ansiNames are the names of the 16 ANSI colors.
+var ansiNames = [...]string{
+	"black", "red", "green", "yellow", "blue", "magenta", "cyan", "lightgray",
+	"darkgray", "lightred", "lightgreen", "lightyellow", "lightblue", "lightmagenta", "lightcyan", "white",
+}
+
+// colorName returns the name of a foreground or background
+// color, such as "red", "256:208" and "#ff8700". It's empty for
+// the default color.
+func colorName(clr color.Color) string {
+	switch c := clr.(type) {
+	case nil:
+		return ""
+	case color.Color16:
+		switch n := int(c); {
+		case n >= 30 && n <= 37, n >= 40 && n <= 47:
+			return ansiNames[n%10]
+		case n >= 90 && n <= 97, n >= 100 && n <= 107:
+			return ansiNames[n%10+8]
+		case c == color.NoColor:
+			return ""
+		}
+	case color.Color256, *color.Color256:
+		// such as "\x1b[38;5;208m"
+		s := c.Color()
+		return "256:" + strings.TrimSuffix(s[strings.LastIndexByte(s, ';')+1:], "m")
+	case color.Color16m:
+		return c.Hex()
+	case *color.Color16m:
+		return c.Hex()
+	}
+	return strings.TrimPrefix(clr.Color(), "\x1b[")
+}
+
+func itoa(i int) string { return strconv.Itoa(i) }
@@ -0,0 +1,661 @@
+// Package vt is a headless virtual terminal for testing the
+// colored and cursor-moving outputs.
+//
+// A [Terminal] consumes the bytes written to it, as a real
+// terminal does, and maintains a screen of the character cells
+// with the colors and attributes, the cursor position, and so
+// on. So a test can check what a user actually sees rather than
+// the raw escape sequences:
+//
+//	term := vt.New(80, 24)
+//	rb := color.NewRowsBlock()
+//	rb.WithWriter(term)
+//	rb.Update("hello\n")
+//	rb.Update("world\n")
+//	term.ExpectRow(t, 0, "world")
+//	term.ExpectStyle(t, 1, "failed", color.Cell{Fg: color.FgRed, Attrs: color.AttrBold})
+//
+// A Terminal is a [color.Writer], it understands the control
+// characters, the CSI sequences of cursor movements, erasing,
+// scrolling and SGR (the 16, 256 and true colors), the cursor
+// saving and the alternate screen. The OSC sequences set the
+// title, the others (DCS, APC, ...) are ignored.
+//
+// As the tty driver does (see onlcr in stty(1)), a line feed
+// moves the cursor to the start of the next line.
+package vt
+
+import (
+	"sync"
+	"unicode/utf8"
+
+	"github.com/hedzr/is/term/color"
+)
+
+// wideTail is the placeholder in the cell following a wide
+// character (such as CJK ideographs).
+const wideTail rune = -1
+
+// maxStringLen limits the length of an OSC sequence.
+const maxStringLen = 4096
+
+// the states of the parser.
+const (
+	stGround = iota
+	stEsc
+	stCSI
+	stOSC
+	stOSCEsc
+	stString // DCS, SOS, PM and APC, which are ignored
+	stStringEsc
+	stCharset // ESC ( x, ...
+)
+
+type cursorState struct {
+	x, y     int
+	pen      color.Cell
+	wrapNext bool
+}
+
+// Terminal is a headless virtual terminal, see the package
+// doc. It's safe for concurrent use.
+type Terminal struct {
+	mu         sync.Mutex
+	cols, rows int
+
+	screen   [][]color.Cell // the main or the alternate screen
+	main     [][]color.Cell
+	alt      bool
+	history  [][]color.Cell // the lines scrolled off the main screen
+	top, bot int            // the scrolling region
+
+	x, y     int
+	pen      color.Cell // the current colors and attributes
+	wrapNext bool       // the last column was written
+	saved    cursorState
+	altSaved cursorState
+	hidden   bool // the cursor is invisible
+	title    string
+
+	state  int
+	seq    []byte // the parameters of CSI, or the text of OSC
+	inter  byte   // the intermediate or private marker of CSI
+	pend   [utf8.UTFMax]byte
+	npend  int
+	lastCh rune
+}
+
+// New returns a Terminal of cols columns and rows rows.
+func New(cols, rows int) *Terminal {
+	t := &Terminal{cols: max(cols, 1), rows: max(rows, 1)}
+	t.reset()
+	return t
+}
+
+// Fd returns an invalid file descriptor, a Terminal isn't a tty.
+// So [color.ColorLevel] is 0 unless it's forced by
+// [color.SetColorLevel].
+func (t *Terminal) Fd() uintptr { return ^uintptr(0) }
+
+// WriteString writes s to the terminal.
+func (t *Terminal) WriteString(s string) (n int, err error) {
+	return t.Write([]byte(s))
+}
+
+// Write consumes the bytes, an escape sequence or a UTF-8
+// character can be split into several writes.
+func (t *Terminal) Write(p []byte) (n int, err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, b := range p {
+		t.feed(b)
+	}
+	return len(p), nil
+}
+
+// Reset clears the screen and the history, and resets the
+// cursor and the colors.
+func (t *Terminal) Reset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.reset()
+}
+
+func (t *Terminal) reset() {
+	t.main = t.blankScreen()
+	t.screen, t.alt, t.history = t.main, false, nil
+	t.top, t.bot = 0, t.rows-1
+	t.x, t.y, t.pen, t.wrapNext = 0, 0, color.Cell{}, false
+	t.saved, t.altSaved = cursorState{}, cursorState{}
+	t.hidden, t.title = false, ""
+	t.state, t.npend, t.lastCh = stGround, 0, 0
+}
+
+func (t *Terminal) blankScreen() [][]color.Cell {
+	s := make([][]color.Cell, t.rows)
+	for i := range s {
+		s[i] = make([]color.Cell, t.cols)
+	}
+	return s
+}
+
+func (t *Terminal) feed(b byte) {
+	switch t.state {
+	case stGround:
+		t.ground(b)
+	case stEsc:
+		t.esc(b)
+	case stCSI:
+		switch {
+		case b == 0x1b:
+			t.state = stEsc
+		case b < 0x20:
+			t.control(b)
+		case b >= 0x40 && b <= 0x7e:
+			t.state = stGround
+			t.csi(b)
+		case b >= 0x3c && b <= 0x3f && len(t.seq) == 0:
+			t.inter = b // the private markers: < = > ?
+		case b >= 0x20 && b <= 0x2f:
+			t.inter = b
+		default:
+			t.seq = append(t.seq, b)
+		}
+	case stOSC:
+		switch b {
+		case 0x07:
+			t.state = stGround
+			t.osc()
+		case 0x1b:
+			t.state = stOSCEsc
+		default:
+			if len(t.seq) < maxStringLen {
+				t.seq = append(t.seq, b)
+			}
+		}
+	case stOSCEsc:
+		t.osc()
+		t.state = stEsc
+		if b == '\\' {
+			t.state = stGround
+			return
+		}
+		t.esc(b)
+	case stString, stStringEsc:
+		switch {
+		case b == 0x1b:
+			t.state = stStringEsc
+		case b == '\\' && t.state == stStringEsc, b == 0x07:
+			t.state = stGround
+		default:
+			t.state = stString
+		}
+	case stCharset:
+		t.state = stGround
+	}
+}
+
+func (t *Terminal) ground(b byte) {
+	if t.npend > 0 && (b < 0x80 || b >= 0xc0) {
+		// an incomplete character
+		t.npend = 0
+		t.print(utf8.RuneError)
+	}
+	switch {
+	case b == 0x1b:
+		t.state = stEsc
+	case b < 0x20 || b == 0x7f:
+		t.control(b)
+	case b < 0x80:
+		t.print(rune(b))
+	default:
+		t.pend[t.npend] = b
+		t.npend++
+		if utf8.FullRune(t.pend[:t.npend]) {
+			r, _ := utf8.DecodeRune(t.pend[:t.npend])
+			t.npend = 0
+			t.print(r)
+		}
+	}
+}
+
+func (t *Terminal) control(b byte) {
+	switch b {
+	case '\b':
+		t.moveTo(t.x-1, t.y)
+	case '\t':
+		t.moveTo(min((t.x/8+1)*8, t.cols-1), t.y)
+	case '\n', '\v', '\f':
+		t.x, t.wrapNext = 0, false
+		t.index()
+	case '\r':
+		t.x, t.wrapNext = 0, false
+	}
+}
+
+func (t *Terminal) esc(b byte) {
+	t.state = stGround
+	switch b {
+	case '[':
+		t.state, t.seq, t.inter = stCSI, t.seq[:0], 0
+	case ']':
+		t.state, t.seq = stOSC, t.seq[:0]
+	case 'P', 'X', '^', '_':
+		t.state = stString
+	case '(', ')', '*', '+':
+		t.state = stCharset
+	case '7':
+		t.saved = t.cursorState()
+	case '8':
+		t.restore(t.saved)
+	case 'c':
+		t.reset()
+	case 'D':
+		t.index()
+	case 'E':
+		t.x, t.wrapNext = 0, false
+		t.index()
+	case 'M':
+		t.reverseIndex()
+	}
+}
+
+// osc handles an OSC sequence, only the title is kept.
+func (t *Terminal) osc() {
+	s := string(t.seq)
+	for i := range len(s) {
+		if s[i] == ';' {
+			if s[:i] == "0" || s[:i] == "2" {
+				t.title = s[i+1:]
+			}
+			return
+		}
+	}
+}
+
+func (t *Terminal) cursorState() cursorState {
+	return cursorState{t.x, t.y, t.pen, t.wrapNext}
+}
+
+func (t *Terminal) restore(s cursorState) {
+	t.x, t.y = min(s.x, t.cols-1), min(s.y, t.rows-1)
+	t.pen, t.wrapNext = s.pen, s.wrapNext
+}
+
+// print puts a character at the cursor and advances it.
+func (t *Terminal) print(r rune) {
+	w := color.StringWidth(string(r))
+	if w == 0 {
+		return // the combining characters are dropped
+	}
+	w = min(w, 2, t.cols)
+	if t.wrapNext || t.x+w > t.cols {
+		t.x, t.wrapNext = 0, false
+		t.index()
+	}
+	line := t.screen[t.y]
+	t.unsplit(line, t.x)
+	t.unsplit(line, t.x+w-1)
+	c := t.pen
+	c.Ch = r
+	line[t.x] = c
+	if w == 2 {
+		c.Ch = wideTail
+		line[t.x+1] = c
+	}
+	t.lastCh = r
+	if t.x+w == t.cols {
+		t.x, t.wrapNext = t.cols-1, true
+	} else {
+		t.x += w
+	}
+}
+
+// unsplit blanks the other half of the wide character at x if
+// it's going to be overwritten.
+func (t *Terminal) unsplit(line []color.Cell, x int) {
+	switch {
+	case line[x].Ch == wideTail && x > 0:
+		line[x-1] = color.Cell{Bg: line[x-1].Bg}
+	case x+1 < len(line) && line[x+1].Ch == wideTail:
+		line[x+1] = color.Cell{Bg: line[x+1].Bg}
+	}
+}
+
+func (t *Terminal) moveTo(x, y int) {
+	t.x = max(0, min(x, t.cols-1))
+	t.y = max(0, min(y, t.rows-1))
+	t.wrapNext = false
+}
+
+// index moves the cursor down, the region is scrolled up at its
+// bottom.
+func (t *Terminal) index() {
+	if t.y == t.bot {
+		t.scrollUp(1)
+	} else if t.y < t.rows-1 {
+		t.y++
+	}
+}
+
+func (t *Terminal) reverseIndex() {
+	if t.y == t.top {
+		t.scrollDown(1)
+	} else if t.y > 0 {
+		t.y--
+	}
+}
+
+// scrollUp scrolls the region up by n lines, the lines scrolled
+// off the top of the main screen are kept in the history.
+func (t *Terminal) scrollUp(n int) {
+	n = min(n, t.bot-t.top+1)
+	region := t.screen[t.top : t.bot+1]
+	if t.top == 0 && !t.alt {
+		for _, line := range region[:n] {
+			t.history = append(t.history, append([]color.Cell(nil), line...))
+		}
+	}
+	copy(region, append(region[n:len(region):len(region)], region[:n]...))
+	for _, line := range region[len(region)-n:] {
+		t.blank(line)
+	}
+}
+
+func (t *Terminal) scrollDown(n int) {
+	n = min(n, t.bot-t.top+1)
+	region := t.screen[t.top : t.bot+1]
+	copy(region, append(region[len(region)-n:len(region):len(region)], region[:len(region)-n]...))
+	for _, line := range region[:n] {
+		t.blank(line)
+	}
+}
+
+// blank erases the cells in the current background color.
+func (t *Terminal) blank(cells []color.Cell) {
+	for i := range cells {
+		cells[i] = color.Cell{Bg: t.pen.Bg}
+	}
+}
+
+// params returns the parameters of the CSI sequence, the
+// sub-parameters (separated by ':') follow their parameter and
+// are flagged in sub. A missing parameter is -1.
+func (t *Terminal) params() (ps []int, sub []bool) {
+	n, ok, colon := 0, false, false
+	for _, b := range append(t.seq, ';') {
+		switch {
+		case b >= '0' && b <= '9':
+			n, ok = min(n*10+int(b-'0'), 1<<16), true
+		case b == ';' || b == ':':
+			if !ok {
+				n = -1
+			}
+			ps, sub = append(ps, n), append(sub, colon)
+			n, ok, colon = 0, false, b == ':'
+		}
+	}
+	return
+}
+
+func (t *Terminal) csi(final byte) {
+	ps, sub := t.params()
+	arg := func(i, def int) int {
+		if i < len(ps) && ps[i] > 0 {
+			return ps[i]
+		}
+		return def
+	}
+	n := arg(0, 1)
+
+	if t.inter == '?' {
+		if final == 'h' || final == 'l' {
+			for _, p := range ps {
+				t.mode(p, final == 'h')
+			}
+		}
+		return
+	}
+	if t.inter != 0 {
+		return
+	}
+
+	switch final {
+	case 'A':
+		t.moveTo(t.x, t.y-n)
+	case 'B', 'e':
+		t.moveTo(t.x, t.y+n)
+	case 'C', 'a':
+		t.moveTo(t.x+n, t.y)
+	case 'D':
+		t.moveTo(t.x-n, t.y)
+	case 'E':
+		t.moveTo(0, t.y+n)
+	case 'F':
+		t.moveTo(0, t.y-n)
+	case 'G', '`':
+		t.moveTo(n-1, t.y)
+	case 'd':
+		t.moveTo(t.x, n-1)
+	case 'H', 'f':
+		t.moveTo(arg(1, 1)-1, n-1)
+	case 'J':
+		t.eraseDisplay(arg(0, 0))
+	case 'K':
+		t.eraseLine(arg(0, 0))
+	case 'X':
+		line := t.screen[t.y]
+		t.blank(line[t.x:min(t.x+n, t.cols)])
+		t.wrapNext = false
+	case '@':
+		line := t.screen[t.y]
+		n = min(n, t.cols-t.x)
+		copy(line[t.x+n:], line[t.x:])
+		t.blank(line[t.x : t.x+n])
+		t.wrapNext = false
+	case 'P':
+		line := t.screen[t.y]
+		n = min(n, t.cols-t.x)
+		copy(line[t.x:], line[t.x+n:])
+		t.blank(line[t.cols-n:])
+		t.wrapNext = false
+	case 'L', 'M':
+		if t.y < t.top || t.y > t.bot {
+			return
+		}
+		top := t.top
+		t.top = t.y
+		if final == 'L' {
+			t.scrollDown(n)
+		} else {
+			t.scrollUpNoHistory(n)
+		}
+		t.top = top
+		t.x, t.wrapNext = 0, false
+	case 'S':
+		t.scrollUp(n)
+	case 'T':
+		t.scrollDown(n)
+	case 'b':
+		for range min(n, t.cols*t.rows) {
+			t.print(t.lastCh)
+		}
+	case 'm':
+		t.sgr(ps, sub)
+	case 'r':
+		top, bot := arg(0, 1)-1, arg(1, t.rows)-1
+		if top < bot && bot < t.rows {
+			t.top, t.bot = top, bot
+			t.moveTo(0, 0)
+		}
+	case 's':
+		t.saved = t.cursorState()
+	case 'u':
+		t.restore(t.saved)
+	}
+}
+
+// scrollUpNoHistory deletes the lines, they aren't kept in the
+// history.
+func (t *Terminal) scrollUpNoHistory(n int) {
+	alt := t.alt
+	t.alt = true
+	t.scrollUp(n)
+	t.alt = alt
+}
+
+// mode sets or resets a DEC private mode.
+func (t *Terminal) mode(p int, set bool) {
+	switch p {
+	case 25:
+		t.hidden = !set
+	case 47, 1047, 1049:
+		if set == t.alt {
+			return
+		}
+		if set {
+			if p == 1049 {
+				t.altSaved = t.cursorState()
+			}
+			t.screen, t.alt = t.blankScreen(), true
+			return
+		}
+		t.screen, t.alt = t.main, false
+		if p == 1049 {
+			t.restore(t.altSaved)
+		}
+	}
+}
+
+func (t *Terminal) eraseDisplay(how int) {
+	switch how {
+	case 0:
+		t.eraseLine(0)
+		for _, line := range t.screen[t.y+1:] {
+			t.blank(line)
+		}
+	case 1:
+		t.eraseLine(1)
+		for _, line := range t.screen[:t.y] {
+			t.blank(line)
+		}
+	case 2, 3:
+		for _, line := range t.screen {
+			t.blank(line)
+		}
+		if how == 3 {
+			t.history = nil
+		}
+	}
+}
+
+func (t *Terminal) eraseLine(how int) {
+	line := t.screen[t.y]
+	switch how {
+	case 0:
+		t.unsplit(line, t.x)
+		t.blank(line[t.x:])
+	case 1:
+		t.unsplit(line, t.x)
+		t.blank(line[:t.x+1])
+	case 2:
+		t.blank(line)
+	}
+	t.wrapNext = false
+}
+
+// the SGR codes of the attributes, and of resetting them.
+var (
+	sgrAttrs = map[int]color.Attr{
+		1: color.AttrBold, 2: color.AttrDim, 3: color.AttrItalic, 4: color.AttrUnderline,
+		5: color.AttrBlink, 6: color.AttrBlink, 7: color.AttrReverse, 8: color.AttrHidden,
+		9: color.AttrStrike, 21: color.AttrUnderline, 51: color.AttrFramed, 53: color.AttrOverline,
+	}
+	sgrResets = map[int]color.Attr{
+		22: color.AttrBold | color.AttrDim, 23: color.AttrItalic, 24: color.AttrUnderline,
+		25: color.AttrBlink, 27: color.AttrReverse, 28: color.AttrHidden, 29: color.AttrStrike,
+		54: color.AttrFramed, 55: color.AttrOverline,
+	}
+)
+
+func (t *Terminal) sgr(ps []int, sub []bool) {
+	if len(ps) == 0 {
+		ps, sub = []int{0}, []bool{false}
+	}
+	for i := 0; i < len(ps); i++ {
+		p := max(ps[i], 0)
+		// the count of the sub-parameters following p
+		subs := 0
+		for i+subs+1 < len(ps) && sub[i+subs+1] {
+			subs++
+		}
+		switch {
+		case p == 0:
+			t.pen = color.Cell{}
+		case p == 4 && subs > 0:
+			// 4:0 is no underline, 4:3 is curly, ...
+			if ps[i+1] == 0 {
+				t.pen.Attrs &^= color.AttrUnderline
+			} else {
+				t.pen.Attrs |= color.AttrUnderline
+			}
+		case sgrAttrs[p] != 0:
+			t.pen.Attrs |= sgrAttrs[p]
+		case sgrResets[p] != 0:
+			t.pen.Attrs &^= sgrResets[p]
+		case p >= 30 && p <= 37, p >= 90 && p <= 97:
+			t.pen.Fg = color.Color16(p)
+		case p >= 40 && p <= 47, p >= 100 && p <= 107:
+			t.pen.Bg = color.Color16(p)
+		case p == 39:
+			t.pen.Fg = nil
+		case p == 49:
+			t.pen.Bg = nil
+		case p == 38, p == 48, p == 58:
+			var clr color.Color
+			var used int
+			if subs > 0 {
+				clr, _ = extColor(ps[i+1:i+1+subs], p == 48, true)
+				used = subs
+			} else {
+				clr, used = extColor(ps[i+1:], p == 48, false)
+			}
+			switch p {
+			case 38:
+				t.pen.Fg = clr
+			case 48:
+				t.pen.Bg = clr
+			}
+			i += used
+			continue
+		}
+		i += subs
+	}
+}
+
+// extColor parses the arguments of SGR 38 and 48, such as 5;n
+// and 2;r;g;b, and returns the count of the arguments used. In
+// the colon form, 2 may be followed by a color space id.
+func extColor(args []int, bg, colon bool) (clr color.Color, used int) {
+	if len(args) == 0 {
+		return nil, 0
+	}
+	switch args[0] {
+	case 5:
+		if len(args) < 2 {
+			return nil, len(args)
+		}
+		return *color.NewColor256(byte(max(args[1], 0)), bg), 2
+	case 2:
+		rgb := args[1:]
+		if colon && len(rgb) >= 4 {
+			rgb = rgb[1:]
+		}
+		if len(rgb) < 3 {
+			return nil, len(args)
+		}
+		c := func(i int) byte { return byte(max(min(rgb[i], 255), 0)) }
+		return *color.NewColor16m(c(0), c(1), c(2), bg), 4
+	}
+	return nil, 1
+}
@@ -0,0 +1,166 @@
+package vt
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hedzr/is/term/color"
+	"github.com/hedzr/is/term/terminfo"
+)
+
+// withoutTerminfo clears $TERM in the test, so the cursor
+// sequences are the ECMA-48 ones whatever the environment is.
+func withoutTerminfo(t *testing.T) {
+	t.Setenv("TERM", "")
+	terminfo.Reset()
+	t.Cleanup(terminfo.Reset)
+}
+
+func TestTerminalText(t *testing.T) {
+	for _, c := range []struct {
+		name, in, want string
+		x, y           int
+	}{
+		{"lf", "ab\ncd", "ab\ncd", 2, 1},
+		{"cr", "abcd\rx", "xbcd", 1, 0},
+		{"bs tab", "ab\bx\ty", "ax     y", 7, 0},
+		{"wrap", "abcdefghij", "abcdefgh\nij", 2, 1},
+		{"deferred wrap", "abcdefgh\r\n", "abcdefgh", 0, 1},
+		{"wide", "a中文b", "a中文b", 6, 0},
+		{"wide wrap", "abcdefg中", "abcdefg\n中", 2, 1},
+		{"wide overwrite", "中文\x1b[1Gx", "x 文", 1, 0},
+		{"cup", "\x1b[2;3Hx\x1b[Hy", "y\n  x", 1, 0},
+		{"moves", "\x1b[3Bx\x1b[2Ay\x1b[3Dz\x1b[2Cw\x1b[Ev\x1b[Fu", "\nuy w\nv\nx", 1, 1},
+		{"cha vpa", "\x1b[4Gx\x1b[3dy\x1b[0Gz", "   x\n\nz   y", 1, 2},
+		{"clamp", "\x1b[99;99Hx\x1b[99A\x1b[99Dy", "y\n\n\n\n       x", 1, 0},
+		{"erase line", "abcdef\x1b[3G\x1b[K", "ab", 2, 0},
+		{"erase line left", "abcdef\x1b[3G\x1b[1K", "   def", 2, 0},
+		{"erase all line", "abcdef\x1b[2K", "", 6, 0},
+		{"erase display", "ab\ncd\nef\x1b[2;2H\x1b[J", "ab\nc", 1, 1},
+		{"erase display up", "ab\ncd\nef\x1b[2;2H\x1b[1J", "\n\nef", 1, 1},
+		{"insert delete chars", "abcdef\x1b[2G\x1b[2@\x1b[4G\x1b[P", "a  cdef", 3, 0},
+		{"erase chars", "abcdef\x1b[2G\x1b[3X", "a   ef", 1, 0},
+		{"insert lines", "a\nb\nc\x1b[2H\x1b[L", "a\n\nb\nc", 0, 1},
+		{"delete lines", "a\nb\nc\x1b[1H\x1b[2M", "c", 0, 0},
+		{"scroll", "1\n2\n3\n4\n5\n6\n7", "3\n4\n5\n6\n7", 1, 4},
+		{"scroll up down", "1\n2\n3\x1b[S\x1b[2T", "\n\n2\n3", 1, 2},
+		{"region", "1\n2\n3\n4\n5\x1b[2;3r\x1b[3Hx\ny", "1\nx\ny\n4\n5", 1, 2},
+		{"reverse index", "a\x1bM\x1bMb", " b\n\na", 2, 0},
+		{"save restore", "ab\x1b7\ncd\x1b8x\x1b[s\x1b[3Hy\x1b[uz", "abxz\ncd\ny", 4, 0},
+		{"rep", "a\x1b[3b", "aaaa", 4, 0},
+		{"osc dcs apc", "a\x1b]0;title\x07b\x1b]8;;http://x\x1b\\c\x1b]8;;\x1b\\\x1bPq#0\x1b\\d\x1b_x\x1b\\e", "abcde", 5, 0},
+		{"charset", "\x1b(Ba\x1b)0b", "ab", 2, 0},
+		{"private modes", "a\x1b[?25l\x1b[?2004hb\x1b[>4;2mc", "abc", 3, 0},
+		{"alternate screen", "ab\x1b[?1049h\x1b[Hxyz\x1b[?1049lc", "abc", 3, 0},
+		{"invalid utf8", "a\xe4\xb8b", "a�b", 3, 0},
+		{"reset", "ab\x1b[31m\x1bcx", "x", 1, 0},
+	} {
+		term := New(8, 5)
+		_, _ = term.Write([]byte(c.in))
+		if got := term.String(); got != c.want {
+			t.Errorf("%s: got %q, want %q", c.name, got, c.want)
+		}
+		if x, y := term.Cursor(); x != c.x || y != c.y {
+			t.Errorf("%s: the cursor is at %d,%d, want %d,%d", c.name, x, y, c.x, c.y)
+		}
+	}
+}
+
+func TestTerminalSplitWrites(t *testing.T) {
+	in := "\x1b[1;31m中文\x1b]2;t\x1b\\\x1b[38;2;1;2;3mx"
+	term := New(10, 2)
+	for i := range len(in) {
+		_, _ = term.Write([]byte{in[i]})
+	}
+	term.ExpectRow(t, 0, "中文x")
+	term.ExpectStyle(t, 0, "中文", color.Cell{Fg: color.FgRed, Attrs: color.AttrBold})
+	term.ExpectStyle(t, 0, "x", color.Cell{Fg: color.NewColor16m(1, 2, 3, false), Attrs: color.AttrBold})
+	if got := term.Title(); got != "t" {
+		t.Errorf("title is %q", got)
+	}
+}
+
+func TestTerminalSGR(t *testing.T) {
+	for _, c := range []struct {
+		in   string
+		want color.Cell
+	}{
+		{"\x1b[1;2;3;4;5;7;8;9mx", color.Cell{Attrs: color.AttrBold | color.AttrDim | color.AttrItalic |
+			color.AttrUnderline | color.AttrBlink | color.AttrReverse | color.AttrHidden | color.AttrStrike}},
+		{"\x1b[1;3;4;9m\x1b[22;23;24;29mx", color.Cell{}},
+		{"\x1b[4:3mx", color.Cell{Attrs: color.AttrUnderline}},
+		{"\x1b[4m\x1b[4:0mx", color.Cell{}},
+		{"\x1b[32;44mx", color.Cell{Fg: color.FgGreen, Bg: color.BgBlue}},
+		{"\x1b[92;104mx", color.Cell{Fg: color.FgLightGreen, Bg: color.BgLightBlue}},
+		{"\x1b[32;44m\x1b[39;49mx", color.Cell{}},
+		{"\x1b[31m\x1b[mx", color.Cell{}},
+		{"\x1b[38;5;208;48;5;17;1mx", color.Cell{Fg: color.NewColor256(208, false), Bg: color.NewColor256(17, true), Attrs: color.AttrBold}},
+		{"\x1b[38:5:208mx", color.Cell{Fg: color.NewColor256(208, false)}},
+		{"\x1b[38;2;255;135;0;4mx", color.Cell{Fg: color.NewColor16m(255, 135, 0, false), Attrs: color.AttrUnderline}},
+		{"\x1b[48:2::1:2:3mx", color.Cell{Bg: color.NewColor16m(1, 2, 3, true)}},
+		{"\x1b[58:5:1;3mx", color.Cell{Attrs: color.AttrItalic}},
+	} {
+		term := New(4, 1)
+		_, _ = term.Write([]byte(c.in))
+		term.ExpectStyle(t, 0, "x", c.want)
+	}
+}
+
+func TestTerminalErase(t *testing.T) {
+	// the erased cells are in the current background color
+	term := New(4, 2)
+	_, _ = term.WriteString("ab\x1b[44m\x1b[1G\x1b[K")
+	term.ExpectStyle(t, 0, "    ", color.Cell{Bg: color.BgBlue})
+}
+
+func TestTerminalHistory(t *testing.T) {
+	term := New(4, 2)
+	_, _ = term.WriteString("1\n2\n3\n4")
+	if got := strings.Join(term.History(), ","); got != "1,2" {
+		t.Errorf("history: %q", got)
+	}
+	_, _ = term.WriteString("\x1b[?1049h\n\n\n\x1b[?1049l")
+	if got := strings.Join(term.History(), ","); got != "1,2" {
+		t.Errorf("the alternate screen has no history: %q", got)
+	}
+	_, _ = term.WriteString("\x1b[3J")
+	if got := term.History(); got != nil {
+		t.Errorf("history: %q", got)
+	}
+}
+
+func TestTerminalSnapshot(t *testing.T) {
+	term := New(20, 4)
+	_, _ = term.WriteString("a \x1b[1mbold\x1b[0m \x1b[31;48;5;17;3mred\x1b[m\n\x1b[?25l")
+	want := "0|a bold red\n" +
+		" |  ^^^^ bold\n" +
+		" |       ^^^ fg=red bg=256:17 italic\n" +
+		"1|\n" +
+		"cursor 0,1 hidden"
+	if got := term.Snapshot(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+	if x, y, ok := term.Find("red"); !ok || x != 7 || y != 0 {
+		t.Errorf("find: %d,%d,%v", x, y, ok)
+	}
+	if term.CursorVisible() {
+		t.Error("the cursor is hidden")
+	}
+}
+
+func TestTerminalCursor(t *testing.T) {
+	// a Terminal can be the output of a color.Cursor
+	withoutTerminfo(t)
+	term := New(20, 3)
+	color.SetColorLevel(color.Level256)
+	defer color.SetColorLevel(0)
+
+	c := color.New().WithWriter(term)
+	c.Color16(color.FgRed).Println("red").Flush()
+	_, _ = term.WriteString("xyz")
+	c.LeftNow(2).EraseLineNow()
+	term.ExpectRow(t, 0, "red")
+	term.ExpectStyle(t, 0, "red", color.Cell{Fg: color.FgRed})
+	term.ExpectRow(t, 1, "")
+	term.ExpectCursor(t, 1, 1)
+}